@@ -17,7 +17,9 @@ limitations under the License.
 package v1alpha1
 
 import (
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -31,6 +33,110 @@ type ScopeInstanceSpec struct {
 	// Foo is an example field of ScopeInstance. Edit scopeinstance_types.go to remove/update
 	ScopeTemplateName string   `json:"scopeTemplateName,omitempty"`
 	Namespaces        []string `json:"namespaces,omitempty"`
+
+	// ScopeTemplateRef references the ScopeTemplate by name and namespace.
+	// When set, it takes precedence over ScopeTemplateName.
+	// +optional
+	ScopeTemplateRef *ScopeTemplateReference `json:"scopeTemplateRef,omitempty"`
+
+	// ScopeTemplateUID pins the ScopeTemplate with this UID, which is used
+	// even once ScopeTemplateRef or ScopeTemplateName reference another
+	// one. A ScopeTemplate cannot be renamed, a copy under a new name has a
+	// new UID, so once no ScopeTemplate has this UID the ScopeTemplate
+	// referenced by ScopeTemplateRef or ScopeTemplateName is used instead.
+	// +optional
+	ScopeTemplateUID types.UID `json:"scopeTemplateUID,omitempty"`
+
+	// NamespaceAnnotationSelector selects the namespaces carrying every
+	// listed annotation key/value to receive bindings. When Namespaces is
+	// also set, NamespaceMatchMode decides how both are combined.
+	// +optional
+	NamespaceAnnotationSelector map[string]string `json:"namespaceAnnotationSelector,omitempty"`
+
+	// NamespaceMatchMode decides which namespaces are selected when both
+	// Namespaces and NamespaceAnnotationSelector are set. Union, the default,
+	// selects the listed namespaces along with every annotated namespace.
+	// Intersection only selects the listed namespaces that are annotated.
+	// +kubebuilder:validation:Enum=Union;Intersection
+	// +optional
+	NamespaceMatchMode string `json:"namespaceMatchMode,omitempty"`
+
+	// BindInSubjectNamespaces also creates the RoleBinding of each ClusterRole
+	// in the namespace of every ServiceAccount it binds, in addition to the
+	// selected namespaces. The ScopeInstance is then never bound cluster wide.
+	// +optional
+	BindInSubjectNamespaces bool `json:"bindInSubjectNamespaces,omitempty"`
+
+	// GroupPrefix is prepended to the name of every Group subject bound by
+	// this ScopeInstance, e.g. a prefix of "oidc:" binds the "team-a" group
+	// as "oidc:team-a".
+	// +optional
+	GroupPrefix string `json:"groupPrefix,omitempty"`
+
+	// DefaultSubjectNamespace is given to every ServiceAccount subject bound
+	// by this ScopeInstance without a namespace. BindingNamespace gives each
+	// RoleBinding its own namespace, leaving those of ClusterRoleBindings
+	// unset, while the name of a namespace gives it to every binding.
+	// +kubebuilder:validation:Pattern=`^(BindingNamespace|[a-z0-9]([-a-z0-9]*[a-z0-9])?)$`
+	// +optional
+	DefaultSubjectNamespace string `json:"defaultSubjectNamespace,omitempty"`
+
+	// ClusterRoleNameOverrides binds another ClusterRole in place of the one
+	// the ScopeTemplate creates, keyed by the generateName of its entry in the
+	// ScopeTemplate, e.g. to bind an environment specific ClusterRole.
+	// +optional
+	ClusterRoleNameOverrides map[string]string `json:"clusterRoleNameOverrides,omitempty"`
+
+	// Audience is the intended audience of the bound ServiceAccount tokens
+	// of the subjects, recorded in the operators.coreos.io/audience
+	// annotation of every binding for downstream tooling.
+	// +optional
+	Audience string `json:"audience,omitempty"`
+
+	// ExpiresAt is when the bindings of this ScopeInstance are deleted. It is
+	// extended by annotating the ScopeInstance with
+	// operators.coreos.io/renew: <RFC 3339 timestamp>.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
+
+	// Priority orders the reconciliation of ScopeInstances waiting to be
+	// reconciled, higher first, such as break-glass access during a mass
+	// reconvergence. Defaults to 0.
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
+
+	// BindingNameTemplate is a Go template rendering the name of each
+	// RoleBinding, such as "{{.ClusterRole}}-{{.Namespace}}", instead of a
+	// generated one. It is rendered with .ScopeInstance, .Namespace,
+	// .ClusterRole, the name of the bound role, and .GenerateName, that of
+	// the ScopeTemplate entry. No RoleBinding is written under a name that
+	// is rendered twice in a namespace or taken by another RoleBinding.
+	// ClusterRoleBindings keep generated names.
+	// +optional
+	BindingNameTemplate string `json:"bindingNameTemplate,omitempty"`
+}
+
+const (
+	// NamespaceMatchModeUnion selects namespaces that are listed or annotated.
+	NamespaceMatchModeUnion = "Union"
+	// NamespaceMatchModeIntersection selects namespaces that are listed and
+	// annotated.
+	NamespaceMatchModeIntersection = "Intersection"
+)
+
+// DefaultSubjectNamespaceBinding is the DefaultSubjectNamespace giving each
+// RoleBinding its own namespace.
+const DefaultSubjectNamespaceBinding = "BindingNamespace"
+
+// ScopeTemplateReference identifies a ScopeTemplate.
+type ScopeTemplateReference struct {
+	// Name is the name of the ScopeTemplate.
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the ScopeTemplate. It is empty for
+	// cluster scoped ScopeTemplates.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // ScopeInstanceStatus defines the observed state of ScopeInstance
@@ -40,14 +146,96 @@ type ScopeInstanceStatus struct {
 
 	// Conditions represent the latest available observations of an object's state
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+
+	// TerminatingNamespaces lists the namespaces that were skipped because
+	// they are being deleted, such as namespaces stuck on finalizers.
+	// +optional
+	TerminatingNamespaces []string `json:"terminatingNamespaces,omitempty"`
+
+	// ForbiddenNamespaces lists the namespaces that were skipped because the
+	// operator is not allowed to write RoleBindings in them.
+	// +optional
+	ForbiddenNamespaces []string `json:"forbiddenNamespaces,omitempty"`
+
+	// ObservedForceSync is the value of the operators.coreos.io/force-sync
+	// annotation for which every binding was last rewritten.
+	// +optional
+	ObservedForceSync string `json:"observedForceSync,omitempty"`
+
+	// Namespaces lists the namespaces the ScopeInstance resolved to, without
+	// duplicates, as of the last reconcile that created its bindings.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// GeneratedBindings lists the role referenced by the bindings of each
+	// ClusterRole of the ScopeTemplate, as of the last successful reconcile.
+	// +optional
+	GeneratedBindings []GeneratedBinding `json:"generatedBindings,omitempty"`
+
+	// DryRunDiscrepancies lists, for a ScopeInstance annotated with
+	// operators.coreos.io/dry-run, how the API server would store its
+	// bindings differently from how the operator writes them, e.g. because
+	// of mutating admission webhooks, as of its last dry run.
+	// +optional
+	DryRunDiscrepancies []string `json:"dryRunDiscrepancies,omitempty"`
+
+	// PendingClusterRoles lists the ClusterRoles referenced by the
+	// ScopeTemplate that do not exist yet. Their bindings are only created
+	// once they exist, while those of the other ClusterRoles already are.
+	// +optional
+	PendingClusterRoles []string `json:"pendingClusterRoles,omitempty"`
+}
+
+// GeneratedBinding describes the bindings created for a single ClusterRole
+// of the ScopeTemplate.
+type GeneratedBinding struct {
+	// GenerateName is the generateName of the ClusterRole in the ScopeTemplate.
+	GenerateName string `json:"generateName"`
+
+	// Kind is ClusterRoleBinding, or RoleBinding if a RoleBinding is created
+	// in each namespace of the ScopeInstance.
+	Kind string `json:"kind"`
+
+	// RoleRef is the role the bindings reference.
+	RoleRef rbacv1.RoleRef `json:"roleRef"`
 }
 
 const (
 	TypeScoped = "Scoped"
 
-	ReasonScopeTemplateNotFound = "ScopeTemplateNotFound"
-	ReasonScopingFailed         = "ScopingFailed"
-	ReasonScopingSuccessful     = "ScopingSuccessful"
+	// TypeProgressing is True while a reconcile writes bindings and False
+	// once a reconcile finds every binding as desired.
+	TypeProgressing = "Progressing"
+
+	// TypeTemplateUnhealthy is True while the ScopeTemplate referenced by the
+	// ScopeInstance reports a failure, with the reason and message of its
+	// Templated condition.
+	TypeTemplateUnhealthy = "TemplateUnhealthy"
+
+	ReasonScopeTemplateNotFound      = "ScopeTemplateNotFound"
+	ReasonScopingFailed              = "ScopingFailed"
+	ReasonScopingSuccessful          = "ScopingSuccessful"
+	ReasonWaitingForClusterRole      = "WaitingForClusterRole"
+	ReasonDeletionGuard              = "DeletionGuard"
+	ReasonInvalidSubjectExpression   = "InvalidSubjectExpression"
+	ReasonDuplicateBindings          = "DuplicateBindings"
+	ReasonExpired                    = "Expired"
+	ReasonOperatorSubject            = "OperatorSubject"
+	ReasonCircuitOpen                = "CircuitOpen"
+	ReasonInvalidBindingNameTemplate = "InvalidBindingNameTemplate"
+	ReasonBindingNameCollision       = "BindingNameCollision"
+	ReasonBindingsChanging           = "BindingsChanging"
+	ReasonConverged                  = "Converged"
+	ReasonDeletionsUnobserved        = "DeletionsUnobserved"
+	ReasonClusterWideViolation       = "ClusterWideViolation"
+	ReasonTooManySubjects            = "TooManySubjects"
+	ReasonDryRun                     = "DryRun"
+	ReasonTemplateHealthy            = "TemplateHealthy"
+	ReasonInvalidClusterRoleSelector = "InvalidClusterRoleSelector"
+
+	// Reasons of the events recorded on the namespaces of RoleBindings
+	ReasonRoleBindingCreated = "RoleBindingCreated"
+	ReasonRoleBindingDeleted = "RoleBindingDeleted"
 )
 
 //+kubebuilder:object:root=true
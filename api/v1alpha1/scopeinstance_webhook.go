@@ -0,0 +1,338 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// ScopeAnnotation records whether a ScopeInstance is bound cluster wide.
+	ScopeAnnotation = "operators.coreos.io/scope"
+
+	// ScopeCluster is the value of the ScopeAnnotation for ScopeInstances
+	// that select no namespaces, and are therefore bound cluster wide.
+	ScopeCluster = "Cluster"
+
+	// CreatedByAnnotation records the user that created a ScopeInstance.
+	CreatedByAnnotation = "operators.coreos.io/created-by"
+
+	// CreatedAtAnnotation records when a ScopeInstance was created, in RFC 3339.
+	CreatedAtAnnotation = "operators.coreos.io/created-at"
+
+	// SkipScopeTemplateCheckAnnotation admits a ScopeInstance referencing a
+	// ScopeTemplate that does not exist yet when set to "true", such as when
+	// a GitOps tool applies the ScopeInstance first.
+	SkipScopeTemplateCheckAnnotation = "operators.coreos.io/skip-scope-template-check"
+)
+
+// scopeinstancelog is for logging in this package.
+var scopeinstancelog = logf.Log.WithName("scopeinstance-resource")
+
+func (r *ScopeInstance) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register("/validate-operators-io-operator-framework-v1alpha1-scopeinstance",
+		&webhook.Admission{Handler: &ScopeInstanceWarner{Client: mgr.GetClient()}})
+	mgr.GetWebhookServer().Register("/mutate-operators-io-operator-framework-v1alpha1-scopeinstance-creator",
+		&webhook.Admission{Handler: &ScopeInstanceCreatorStamper{}})
+	mgr.GetWebhookServer().Register("/validate-operators-io-operator-framework-v1alpha1-scopeinstance-scopetemplate",
+		&webhook.Admission{Handler: &ScopeTemplateValidator{Client: mgr.GetClient()}})
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-operators-io-operator-framework-v1alpha1-scopeinstance,mutating=true,failurePolicy=fail,sideEffects=None,groups=operators.io.operator-framework,resources=scopeinstances,verbs=create;update,versions=v1alpha1,name=mscopeinstance.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &ScopeInstance{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type.
+// A ScopeInstance that selects no namespaces is annotated with the cluster
+// scope, making it explicit that a ClusterRoleBinding will be created.
+func (r *ScopeInstance) Default() {
+	scopeinstancelog.V(2).Info("default", "name", r.Name)
+
+	if len(r.Spec.Namespaces) > 0 || len(r.Spec.NamespaceAnnotationSelector) > 0 || r.Spec.BindInSubjectNamespaces {
+		if r.Annotations[ScopeAnnotation] == ScopeCluster {
+			delete(r.Annotations, ScopeAnnotation)
+		}
+		return
+	}
+
+	if r.Annotations == nil {
+		r.Annotations = map[string]string{}
+	}
+	r.Annotations[ScopeAnnotation] = ScopeCluster
+}
+
+//+kubebuilder:webhook:path=/validate-operators-io-operator-framework-v1alpha1-scopeinstance,mutating=false,failurePolicy=ignore,sideEffects=None,groups=operators.io.operator-framework,resources=scopeinstances,verbs=create;update,versions=v1alpha1,name=vscopeinstance.kb.io,admissionReviewVersions=v1
+
+// ScopeInstanceWarner admits every ScopeInstance, returning warnings for
+// risky configurations so that users are nudged without being blocked.
+// +kubebuilder:object:generate=false
+type ScopeInstanceWarner struct {
+	Client  client.Client
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = &ScopeInstanceWarner{}
+
+// InjectDecoder implements admission.DecoderInjector.
+func (w *ScopeInstanceWarner) InjectDecoder(d *admission.Decoder) error {
+	w.decoder = d
+	return nil
+}
+
+// Handle implements admission.Handler.
+func (w *ScopeInstanceWarner) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation == admissionv1.Delete {
+		return admission.Allowed("")
+	}
+
+	si := &ScopeInstance{}
+	if err := w.decoder.Decode(req, si); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	warnings, err := w.Warnings(ctx, si)
+	if err != nil {
+		scopeinstancelog.Error(err, "computing warnings", "name", si.Name)
+	}
+	return admission.Allowed("").WithWarnings(warnings...)
+}
+
+// Warnings returns a warning for every ClusterRole of the referenced
+// ScopeTemplate that grants every verb on every resource cluster wide, for
+// every Role that cannot be bound, and for every subject that binds all
+// (un)authenticated users.
+func (w *ScopeInstanceWarner) Warnings(ctx context.Context, si *ScopeInstance) ([]string, error) {
+	key := client.ObjectKey{Name: si.Spec.ScopeTemplateName}
+	if ref := si.Spec.ScopeTemplateRef; ref != nil {
+		key = client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}
+	}
+
+	st := &ScopeTemplate{}
+	if err := w.Client.Get(ctx, key, st); err != nil {
+		if k8sapierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	clusterWide := len(si.Spec.Namespaces) == 0 && len(si.Spec.NamespaceAnnotationSelector) == 0 && !si.Spec.BindInSubjectNamespaces
+
+	var warnings []string
+	for _, cr := range st.Spec.ClusterRoles {
+		if (clusterWide || cr.Scope == ClusterRoleScopeCluster) && grantsEverything(cr.Rules) {
+			warnings = append(warnings, fmt.Sprintf("ClusterRole %s grants every verb on every resource and will be bound cluster wide", cr.GenerateName))
+		}
+	}
+	for _, bt := range st.Spec.BindingTemplates() {
+		if bt.RoleRef.Kind == "Role" && (clusterWide || bt.Scope == ClusterRoleScopeCluster) {
+			warnings = append(warnings, fmt.Sprintf("Role %s cannot be bound cluster wide and will not be bound", bt.RoleRef.Name))
+		}
+		broadGroups := map[string]bool{}
+		subjects := append([]rbacv1.Subject{}, bt.Subjects...)
+		for _, namespaceSubjects := range bt.NamespaceSubjects {
+			subjects = append(subjects, namespaceSubjects...)
+		}
+		for _, subject := range subjects {
+			if subject.Kind != rbacv1.GroupKind {
+				continue
+			}
+			if name := si.Spec.GroupPrefix + subject.Name; name == "system:authenticated" || name == "system:unauthenticated" {
+				broadGroups[name] = true
+			}
+		}
+		for _, name := range []string{"system:authenticated", "system:unauthenticated"} {
+			if broadGroups[name] {
+				warnings = append(warnings, fmt.Sprintf("%s %s will be bound to the %s group", bt.RoleRef.Kind, bt.RoleRef.Name, name))
+			}
+		}
+	}
+	return warnings, nil
+}
+
+// grantsEverything returns true if any of the rules allows every verb on
+// every resource.
+func grantsEverything(rules []rbacv1.PolicyRule) bool {
+	for _, rule := range rules {
+		if contains(rule.Verbs, rbacv1.VerbAll) && contains(rule.Resources, rbacv1.ResourceAll) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+//+kubebuilder:webhook:path=/mutate-operators-io-operator-framework-v1alpha1-scopeinstance-creator,mutating=true,failurePolicy=fail,sideEffects=None,groups=operators.io.operator-framework,resources=scopeinstances,verbs=create;update,versions=v1alpha1,name=cscopeinstance.kb.io,admissionReviewVersions=v1
+
+// ScopeInstanceCreatorStamper annotates every ScopeInstance with the user
+// that created it and when. The annotations are set on create and carried
+// over on update, so they cannot be forged or changed later.
+// +kubebuilder:object:generate=false
+type ScopeInstanceCreatorStamper struct {
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = &ScopeInstanceCreatorStamper{}
+
+// InjectDecoder implements admission.DecoderInjector.
+func (s *ScopeInstanceCreatorStamper) InjectDecoder(d *admission.Decoder) error {
+	s.decoder = d
+	return nil
+}
+
+// Handle implements admission.Handler.
+func (s *ScopeInstanceCreatorStamper) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	si := &ScopeInstance{}
+	if err := s.decoder.Decode(req, si); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	stamp := map[string]string{
+		CreatedByAnnotation: req.UserInfo.Username,
+		CreatedAtAnnotation: time.Now().UTC().Format(time.RFC3339),
+	}
+	if req.Operation == admissionv1.Update {
+		old := &ScopeInstance{}
+		if err := s.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		// Keep the original stamp, or none for ScopeInstances created before the webhook
+		stamp = map[string]string{}
+		for _, key := range []string{CreatedByAnnotation, CreatedAtAnnotation} {
+			if value, ok := old.Annotations[key]; ok {
+				stamp[key] = value
+			}
+		}
+	}
+
+	for _, key := range []string{CreatedByAnnotation, CreatedAtAnnotation} {
+		delete(si.Annotations, key)
+	}
+	if len(stamp) > 0 && si.Annotations == nil {
+		si.Annotations = map[string]string{}
+	}
+	for key, value := range stamp {
+		si.Annotations[key] = value
+	}
+
+	marshalled, err := json.Marshal(si)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshalled)
+}
+
+//+kubebuilder:webhook:path=/validate-operators-io-operator-framework-v1alpha1-scopeinstance-scopetemplate,mutating=false,failurePolicy=ignore,sideEffects=None,groups=operators.io.operator-framework,resources=scopeinstances,verbs=create;update,versions=v1alpha1,name=tscopeinstance.kb.io,admissionReviewVersions=v1
+
+// ScopeTemplateValidator denies ScopeInstances referencing a ScopeTemplate
+// that does not exist, giving immediate feedback on typos. It only checks a
+// reference when it is created or changed, so that a ScopeInstance whose
+// ScopeTemplate was deleted can still be updated, and can be skipped with the
+// SkipScopeTemplateCheckAnnotation.
+// +kubebuilder:object:generate=false
+type ScopeTemplateValidator struct {
+	Client  client.Client
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = &ScopeTemplateValidator{}
+
+// InjectDecoder implements admission.DecoderInjector.
+func (v *ScopeTemplateValidator) InjectDecoder(d *admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// Handle implements admission.Handler.
+func (v *ScopeTemplateValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	si := &ScopeInstance{}
+	if err := v.decoder.Decode(req, si); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if si.Annotations[SkipScopeTemplateCheckAnnotation] == "true" {
+		return admission.Allowed("")
+	}
+
+	key, ok := scopeTemplateReference(si)
+	if !ok {
+		// The default ScopeTemplate of the operator, if any, is used
+		return admission.Allowed("")
+	}
+	if req.Operation == admissionv1.Update {
+		old := &ScopeInstance{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if oldKey, ok := scopeTemplateReference(old); ok && oldKey == key {
+			return admission.Allowed("")
+		}
+	}
+
+	if err := v.Client.Get(ctx, key, &ScopeTemplate{}); err != nil {
+		if k8sapierrors.IsNotFound(err) {
+			name := key.Name
+			if key.Namespace != "" {
+				name = key.String()
+			}
+			return admission.Denied(fmt.Sprintf("ScopeTemplate %s does not exist, annotate the ScopeInstance with %s=true to admit it anyway",
+				name, SkipScopeTemplateCheckAnnotation))
+		}
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.Allowed("")
+}
+
+// scopeTemplateReference returns the key of the ScopeTemplate referenced by
+// the ScopeInstance, and false if it references none.
+func scopeTemplateReference(si *ScopeInstance) (client.ObjectKey, bool) {
+	if ref := si.Spec.ScopeTemplateRef; ref != nil {
+		return client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, true
+	}
+	return client.ObjectKey{Name: si.Spec.ScopeTemplateName}, si.Spec.ScopeTemplateName != ""
+}
@@ -0,0 +1,319 @@
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("ScopeInstance webhook", func() {
+	Describe("Default", func() {
+		It("should annotate a ScopeInstance without namespaces as cluster scoped", func() {
+			si := &ScopeInstance{}
+			si.Default()
+			Expect(si.Annotations).Should(HaveKeyWithValue(ScopeAnnotation, ScopeCluster))
+		})
+		It("should not annotate a ScopeInstance with namespaces", func() {
+			si := &ScopeInstance{Spec: ScopeInstanceSpec{Namespaces: []string{"test"}}}
+			si.Default()
+			Expect(si.Annotations).ShouldNot(HaveKey(ScopeAnnotation))
+		})
+		It("should not annotate a ScopeInstance with a namespace annotation selector", func() {
+			si := &ScopeInstance{Spec: ScopeInstanceSpec{NamespaceAnnotationSelector: map[string]string{"team": "a"}}}
+			si.Default()
+			Expect(si.Annotations).ShouldNot(HaveKey(ScopeAnnotation))
+		})
+		It("should not annotate a ScopeInstance binding in the namespaces of its subjects", func() {
+			si := &ScopeInstance{Spec: ScopeInstanceSpec{BindInSubjectNamespaces: true}}
+			si.Default()
+			Expect(si.Annotations).ShouldNot(HaveKey(ScopeAnnotation))
+		})
+		It("should remove the annotation once namespaces are selected", func() {
+			si := &ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{ScopeAnnotation: ScopeCluster, "other": "value"},
+				},
+				Spec: ScopeInstanceSpec{Namespaces: []string{"test"}},
+			}
+			si.Default()
+			Expect(si.Annotations).Should(Equal(map[string]string{"other": "value"}))
+		})
+	})
+
+	Describe("ScopeInstanceWarner", func() {
+		var (
+			ctx    = context.Background()
+			scheme *runtime.Scheme
+			warner *ScopeInstanceWarner
+		)
+		BeforeEach(func() {
+			scheme = runtime.NewScheme()
+			Expect(AddToScheme(scheme)).To(Succeed())
+
+			st := &ScopeTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "risky"},
+				Spec: ScopeTemplateSpec{
+					ClusterRoles: []ClusterRoleTemplate{
+						{
+							GenerateName: "admin",
+							Rules: []rbacv1.PolicyRule{{
+								APIGroups: []string{"*"},
+								Resources: []string{"*"},
+								Verbs:     []string{"*"},
+							}},
+							Subjects: []rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "manager"}},
+						},
+						{
+							GenerateName: "view",
+							Rules: []rbacv1.PolicyRule{{
+								APIGroups: []string{""},
+								Resources: []string{"configmaps"},
+								Verbs:     []string{"get"},
+							}},
+							Subjects: []rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "system:authenticated"}},
+						},
+					},
+				},
+			}
+			warner = &ScopeInstanceWarner{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(st).Build()}
+		})
+
+		It("should warn about cluster wide privileged bindings and broad groups", func() {
+			warnings, err := warner.Warnings(ctx, &ScopeInstance{Spec: ScopeInstanceSpec{ScopeTemplateName: "risky"}})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(warnings).Should(ConsistOf(
+				"ClusterRole admin grants every verb on every resource and will be bound cluster wide",
+				"ClusterRole view will be bound to the system:authenticated group",
+			))
+		})
+
+		It("should not warn about privileged bindings in selected namespaces", func() {
+			warnings, err := warner.Warnings(ctx, &ScopeInstance{Spec: ScopeInstanceSpec{
+				ScopeTemplateName: "risky",
+				Namespaces:        []string{"test"},
+				GroupPrefix:       "oidc:",
+			}})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(warnings).Should(BeEmpty())
+		})
+
+		It("should warn about Roles that cannot be bound cluster wide", func() {
+			st := &ScopeTemplate{}
+			Expect(warner.Client.Get(ctx, client.ObjectKey{Name: "risky"}, st)).To(Succeed())
+			st.Spec.Bindings = []BindingTemplate{{
+				GenerateName: "deployer",
+				RoleRef:      rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "deployer"},
+				Subjects:     []rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "system:authenticated"}},
+			}}
+			Expect(warner.Client.Update(ctx, st)).To(Succeed())
+
+			warnings, err := warner.Warnings(ctx, &ScopeInstance{Spec: ScopeInstanceSpec{ScopeTemplateName: "risky"}})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(warnings).Should(ContainElements(
+				"Role deployer cannot be bound cluster wide and will not be bound",
+				"Role deployer will be bound to the system:authenticated group",
+			))
+
+			warnings, err = warner.Warnings(ctx, &ScopeInstance{Spec: ScopeInstanceSpec{
+				ScopeTemplateName: "risky",
+				Namespaces:        []string{"test"},
+			}})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(warnings).Should(ConsistOf(
+				"ClusterRole view will be bound to the system:authenticated group",
+				"Role deployer will be bound to the system:authenticated group",
+			))
+		})
+
+		It("should not warn when the ScopeTemplate does not exist", func() {
+			warnings, err := warner.Warnings(ctx, &ScopeInstance{Spec: ScopeInstanceSpec{ScopeTemplateName: "missing"}})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(warnings).Should(BeEmpty())
+		})
+
+		It("should allow the ScopeInstance with the warnings", func() {
+			decoder, err := admission.NewDecoder(scheme)
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(warner.InjectDecoder(decoder)).To(Succeed())
+
+			raw, err := json.Marshal(&ScopeInstance{
+				TypeMeta: metav1.TypeMeta{APIVersion: GroupVersion.String(), Kind: "ScopeInstance"},
+				Spec:     ScopeInstanceSpec{ScopeTemplateName: "risky"},
+			})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			resp := warner.Handle(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Object:    runtime.RawExtension{Raw: raw},
+			}})
+			Expect(resp.Allowed).Should(BeTrue())
+			Expect(resp.Warnings).Should(HaveLen(2))
+		})
+	})
+
+	Describe("ScopeTemplateValidator", func() {
+		var (
+			ctx       = context.Background()
+			validator *ScopeTemplateValidator
+		)
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(AddToScheme(scheme)).To(Succeed())
+			decoder, err := admission.NewDecoder(scheme)
+			Expect(err).ShouldNot(HaveOccurred())
+			validator = &ScopeTemplateValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				&ScopeTemplate{ObjectMeta: metav1.ObjectMeta{Name: "existing"}},
+			).Build()}
+			Expect(validator.InjectDecoder(decoder)).To(Succeed())
+		})
+
+		admit := func(operation admissionv1.Operation, si, old *ScopeInstance) admission.Response {
+			req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: operation}}
+			si.TypeMeta = metav1.TypeMeta{APIVersion: GroupVersion.String(), Kind: "ScopeInstance"}
+			raw, err := json.Marshal(si)
+			Expect(err).ShouldNot(HaveOccurred())
+			req.Object = runtime.RawExtension{Raw: raw}
+			if old != nil {
+				old.TypeMeta = si.TypeMeta
+				raw, err := json.Marshal(old)
+				Expect(err).ShouldNot(HaveOccurred())
+				req.OldObject = runtime.RawExtension{Raw: raw}
+			}
+			return validator.Handle(ctx, req)
+		}
+
+		It("should allow a ScopeInstance referencing an existing ScopeTemplate", func() {
+			resp := admit(admissionv1.Create, &ScopeInstance{Spec: ScopeInstanceSpec{ScopeTemplateName: "existing"}}, nil)
+			Expect(resp.Allowed).Should(BeTrue())
+
+			resp = admit(admissionv1.Create, &ScopeInstance{Spec: ScopeInstanceSpec{
+				ScopeTemplateName: "missing",
+				ScopeTemplateRef:  &ScopeTemplateReference{Name: "existing"},
+			}}, nil)
+			Expect(resp.Allowed).Should(BeTrue())
+		})
+
+		It("should deny a ScopeInstance referencing a missing ScopeTemplate", func() {
+			resp := admit(admissionv1.Create, &ScopeInstance{Spec: ScopeInstanceSpec{ScopeTemplateName: "mising"}}, nil)
+			Expect(resp.Allowed).Should(BeFalse())
+			Expect(string(resp.Result.Reason)).Should(HavePrefix("ScopeTemplate mising does not exist"))
+
+			By("denying an update that changes the reference to a missing ScopeTemplate")
+			resp = admit(admissionv1.Update,
+				&ScopeInstance{Spec: ScopeInstanceSpec{ScopeTemplateName: "mising"}},
+				&ScopeInstance{Spec: ScopeInstanceSpec{ScopeTemplateName: "existing"}})
+			Expect(resp.Allowed).Should(BeFalse())
+		})
+
+		It("should allow an update that keeps a reference to a deleted ScopeTemplate", func() {
+			resp := admit(admissionv1.Update,
+				&ScopeInstance{Spec: ScopeInstanceSpec{ScopeTemplateName: "deleted", Namespaces: []string{"test"}}},
+				&ScopeInstance{Spec: ScopeInstanceSpec{ScopeTemplateName: "deleted"}})
+			Expect(resp.Allowed).Should(BeTrue())
+		})
+
+		It("should allow a ScopeInstance referencing no ScopeTemplate", func() {
+			resp := admit(admissionv1.Create, &ScopeInstance{}, nil)
+			Expect(resp.Allowed).Should(BeTrue())
+		})
+
+		It("should allow a missing ScopeTemplate when the check is skipped", func() {
+			resp := admit(admissionv1.Create, &ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{SkipScopeTemplateCheckAnnotation: "true"}},
+				Spec:       ScopeInstanceSpec{ScopeTemplateName: "applied-later"},
+			}, nil)
+			Expect(resp.Allowed).Should(BeTrue())
+		})
+	})
+
+	Describe("ScopeInstanceCreatorStamper", func() {
+		var (
+			ctx     = context.Background()
+			stamper *ScopeInstanceCreatorStamper
+		)
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(AddToScheme(scheme)).To(Succeed())
+			decoder, err := admission.NewDecoder(scheme)
+			Expect(err).ShouldNot(HaveOccurred())
+			stamper = &ScopeInstanceCreatorStamper{}
+			Expect(stamper.InjectDecoder(decoder)).To(Succeed())
+		})
+
+		// admit runs the stamper as the given user and returns the annotations
+		// of the resulting ScopeInstance.
+		admit := func(operation admissionv1.Operation, user string, si, old *ScopeInstance) map[string]string {
+			req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: operation,
+				UserInfo:  authenticationv1.UserInfo{Username: user},
+			}}
+			si.TypeMeta = metav1.TypeMeta{APIVersion: GroupVersion.String(), Kind: "ScopeInstance"}
+			raw, err := json.Marshal(si)
+			Expect(err).ShouldNot(HaveOccurred())
+			req.Object = runtime.RawExtension{Raw: raw}
+			if old != nil {
+				old.TypeMeta = si.TypeMeta
+				raw, err := json.Marshal(old)
+				Expect(err).ShouldNot(HaveOccurred())
+				req.OldObject = runtime.RawExtension{Raw: raw}
+			}
+
+			resp := stamper.Handle(ctx, req)
+			Expect(resp.Allowed).Should(BeTrue())
+
+			patched, err := json.Marshal(si)
+			Expect(err).ShouldNot(HaveOccurred())
+			if len(resp.Patches) > 0 {
+				patch, err := json.Marshal(resp.Patches)
+				Expect(err).ShouldNot(HaveOccurred())
+				decoded, err := jsonpatch.DecodePatch(patch)
+				Expect(err).ShouldNot(HaveOccurred())
+				patched, err = decoded.Apply(patched)
+				Expect(err).ShouldNot(HaveOccurred())
+			}
+			out := &ScopeInstance{}
+			Expect(json.Unmarshal(patched, out)).To(Succeed())
+			return out.Annotations
+		}
+
+		It("should stamp the creator once and preserve it across updates", func() {
+			created := admit(admissionv1.Create, "alice", &ScopeInstance{ObjectMeta: metav1.ObjectMeta{
+				// A forged creator is overwritten
+				Annotations: map[string]string{CreatedByAnnotation: "mallory"},
+			}}, nil)
+			Expect(created).Should(HaveKeyWithValue(CreatedByAnnotation, "alice"))
+			createdAt, err := time.Parse(time.RFC3339, created[CreatedAtAnnotation])
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(createdAt).Should(BeTemporally("~", time.Now(), 5*time.Second))
+
+			old := &ScopeInstance{ObjectMeta: metav1.ObjectMeta{Annotations: created}}
+			updated := admit(admissionv1.Update, "bob", &ScopeInstance{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{CreatedByAnnotation: "bob", "other": "value"},
+			}}, old)
+			Expect(updated).Should(Equal(map[string]string{
+				CreatedByAnnotation: "alice",
+				CreatedAtAnnotation: created[CreatedAtAnnotation],
+				"other":             "value",
+			}))
+		})
+
+		It("should not stamp the updater of a ScopeInstance created before the webhook", func() {
+			updated := admit(admissionv1.Update, "bob", &ScopeInstance{}, &ScopeInstance{})
+			Expect(updated).ShouldNot(HaveKey(CreatedByAnnotation))
+			Expect(updated).ShouldNot(HaveKey(CreatedAtAnnotation))
+		})
+	})
+})
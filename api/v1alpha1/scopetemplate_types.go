@@ -31,12 +31,153 @@ type ScopeTemplateSpec struct {
 
 	// Foo is an example field of ScopeTemplate. Edit scopetemplate_types.go to remove/update
 	ClusterRoles []ClusterRoleTemplate `json:"clusterRoles,omitempty"`
+
+	// Bindings bind existing Roles or ClusterRoles, alongside the
+	// ClusterRoles created from ClusterRoles. The generateName of every
+	// entry of ClusterRoles and Bindings must be unique.
+	// +optional
+	Bindings []BindingTemplate `json:"bindings,omitempty"`
+}
+
+// BindingTemplate binds an existing Role or ClusterRole to subjects for each
+// ScopeInstance referencing the ScopeTemplate. A Role is looked up in the
+// namespace of each RoleBinding, so an entry binding a Role is only bound in
+// the namespaces selected by the ScopeInstance, and is skipped for a
+// ScopeInstance bound cluster wide.
+type BindingTemplate struct {
+	GenerateName string `json:"generateName"`
+
+	// RoleRef is the Role or ClusterRole to bind. It is ignored when
+	// ClusterRoleSelector is set.
+	// +optional
+	RoleRef rbacv1.RoleRef `json:"roleRef"`
+
+	// ClusterRoleSelector binds every ClusterRole matching the selector
+	// instead of the RoleRef, e.g. to compose the ClusterRoles labeled for
+	// aggregation. Each ClusterRole gets its own bindings, as for an entry
+	// whose generateName is the generateName of this one followed by a dash
+	// and the name of the ClusterRole. The bindings follow the ClusterRoles
+	// as they come and go. The selector must not be empty.
+	// +optional
+	ClusterRoleSelector *metav1.LabelSelector `json:"clusterRoleSelector,omitempty"`
+
+	Subjects []rbacv1.Subject `json:"subjects"`
+
+	// ServiceAccountSelector binds every ServiceAccount matching the selector
+	// in addition to the listed Subjects.
+	// +optional
+	ServiceAccountSelector *ServiceAccountSelector `json:"serviceAccountSelector,omitempty"`
+
+	// GroupSets names group sets whose Groups are bound in addition to the
+	// listed Subjects, as for ClusterRoleTemplate.GroupSets.
+	// +optional
+	GroupSets []string `json:"groupSets,omitempty"`
+
+	// SubjectExpression is a CEL expression computing additional subjects for
+	// each binding, as for ClusterRoleTemplate.SubjectExpression.
+	// +optional
+	SubjectExpression string `json:"subjectExpression,omitempty"`
+
+	// NamespaceSubjects lists additional subjects for the RoleBinding in each
+	// namespace, keyed by namespace.
+	// +optional
+	NamespaceSubjects map[string][]rbacv1.Subject `json:"namespaceSubjects,omitempty"`
+
+	// Scope may be set to Cluster to always bind a ClusterRole with a
+	// ClusterRoleBinding. It must not be set for a Role.
+	// +kubebuilder:validation:Enum=Cluster
+	// +optional
+	Scope string `json:"scope,omitempty"`
+}
+
+// BindingTemplates returns the bindings of the ScopeTemplate, those of the
+// ClusterRoles it creates followed by Bindings.
+func (s *ScopeTemplateSpec) BindingTemplates() []BindingTemplate {
+	if len(s.ClusterRoles) == 0 {
+		return s.Bindings
+	}
+	templates := make([]BindingTemplate, 0, len(s.ClusterRoles)+len(s.Bindings))
+	for i := range s.ClusterRoles {
+		templates = append(templates, s.ClusterRoles[i].BindingTemplate())
+	}
+	return append(templates, s.Bindings...)
 }
 
 type ClusterRoleTemplate struct {
 	GenerateName string              `json:"generateName"`
 	Rules        []rbacv1.PolicyRule `json:"rules"`
 	Subjects     []rbacv1.Subject    `json:"subjects"`
+
+	// ServiceAccountSelector binds every ServiceAccount matching the selector
+	// in addition to the listed Subjects.
+	// +optional
+	ServiceAccountSelector *ServiceAccountSelector `json:"serviceAccountSelector,omitempty"`
+
+	// GroupSets names group sets, ConfigMaps in the group set namespace of
+	// the operator listing one group per line under the groups key, whose
+	// Groups are bound in addition to the listed Subjects. The bindings
+	// follow changes to the group sets.
+	// +optional
+	GroupSets []string `json:"groupSets,omitempty"`
+
+	// SubjectExpression is a CEL expression computing additional subjects for
+	// each binding. It is evaluated with the variables scopeInstance, the
+	// ScopeInstance as a map, and namespaceName, the namespace of the
+	// RoleBinding or "" for a ClusterRoleBinding. It must return a list of
+	// subjects, e.g.
+	// [{"kind": "ServiceAccount", "name": namespaceName, "namespace": namespaceName}]
+	// +optional
+	SubjectExpression string `json:"subjectExpression,omitempty"`
+
+	// NamespaceSubjects lists additional subjects for the RoleBinding in each
+	// namespace, keyed by namespace, so that a single ClusterRole can be
+	// granted to different subjects per namespace. Namespaces that are not
+	// selected by the ScopeInstance are ignored, as are ClusterRoleBindings.
+	// +optional
+	NamespaceSubjects map[string][]rbacv1.Subject `json:"namespaceSubjects,omitempty"`
+
+	// Scope may be set to Cluster to always bind the ClusterRole with a
+	// ClusterRoleBinding, e.g. for cluster scoped resources, even when the
+	// ScopeInstance selects namespaces. By default the ClusterRole is bound
+	// in the namespaces selected by the ScopeInstance, or cluster wide if it
+	// selects none.
+	// +kubebuilder:validation:Enum=Cluster
+	// +optional
+	Scope string `json:"scope,omitempty"`
+}
+
+// BindingTemplate returns the binding of the ClusterRole created for the
+// ClusterRoleTemplate.
+func (crt *ClusterRoleTemplate) BindingTemplate() BindingTemplate {
+	return BindingTemplate{
+		GenerateName: crt.GenerateName,
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     crt.GenerateName,
+		},
+		Subjects:               crt.Subjects,
+		ServiceAccountSelector: crt.ServiceAccountSelector,
+		GroupSets:              crt.GroupSets,
+		SubjectExpression:      crt.SubjectExpression,
+		NamespaceSubjects:      crt.NamespaceSubjects,
+		Scope:                  crt.Scope,
+	}
+}
+
+// ClusterRoleScopeCluster is the Scope of a ClusterRoleTemplate that is
+// always bound cluster wide.
+const ClusterRoleScopeCluster = "Cluster"
+
+// ServiceAccountSelector selects a dynamic set of ServiceAccount subjects.
+type ServiceAccountSelector struct {
+	// Namespace is the namespace of the selected ServiceAccounts. An empty
+	// namespace selects ServiceAccounts in every namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Selector is a label selector for the ServiceAccounts to bind.
+	Selector metav1.LabelSelector `json:"selector"`
 }
 
 // ScopeTemplateStatus defines the observed state of ScopeTemplate
@@ -46,6 +187,15 @@ type ScopeTemplateStatus struct {
 
 	// Conditions represent the latest available observations of an object's state
 	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type" protobuf:"bytes,1,rep,name=conditions"`
+
+	// ScopeInstances lists the names of the ScopeInstances referencing the ScopeTemplate.
+	// +optional
+	ScopeInstances []string `json:"scopeInstances,omitempty"`
+
+	// BindingCount is the total number of (Cluster)RoleBindings created for
+	// the ScopeInstances referencing the ScopeTemplate.
+	// +optional
+	BindingCount int `json:"bindingCount,omitempty"`
 }
 
 const (
@@ -53,6 +203,11 @@ const (
 
 	ReasonTemplatingFailed     = "TemplatingFailed"
 	ReasonTemplatingSuccessful = "TemplatingSuccessful"
+
+	// ReasonPolicyViolation is set on ScopeTemplates, and on the
+	// ScopeInstances referencing them, whose ClusterRoles grant a rule
+	// forbidden by the policy of the operator.
+	ReasonPolicyViolation = "PolicyViolation"
 )
 
 //+kubebuilder:object:root=true
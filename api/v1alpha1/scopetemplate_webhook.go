@@ -0,0 +1,119 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// scopetemplatelog is for logging in this package.
+var scopetemplatelog = logf.Log.WithName("scopetemplate-resource")
+
+func (r *ScopeTemplate) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-operators-io-operator-framework-v1alpha1-scopetemplate,mutating=false,failurePolicy=fail,sideEffects=None,groups=operators.io.operator-framework,resources=scopetemplates,verbs=create;update,versions=v1alpha1,name=vscopetemplate.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &ScopeTemplate{}
+
+// ValidateCreate implements webhook.Validator and denies a ScopeTemplate
+// whose entries share a generateName, as their bindings are told apart by it,
+// or that has an empty clusterRoleSelector, which would bind every
+// ClusterRole.
+func (r *ScopeTemplate) ValidateCreate() error {
+	scopetemplatelog.V(2).Info("validate create", "name", r.Name)
+
+	if err := duplicateGenerateNamesError(r.duplicateGenerateNames(nil)); err != nil {
+		return err
+	}
+	return emptyClusterRoleSelectorsError(r.emptyClusterRoleSelectors(nil))
+}
+
+// ValidateUpdate implements webhook.Validator. Only the duplicates and empty
+// clusterRoleSelectors an update introduces are denied, so that a
+// ScopeTemplate admitted before the webhook can still be updated, such as to
+// remove its finalizer or to fix it.
+func (r *ScopeTemplate) ValidateUpdate(old runtime.Object) error {
+	scopetemplatelog.V(2).Info("validate update", "name", r.Name)
+
+	var existing, existingEmpty []string
+	if oldTemplate, ok := old.(*ScopeTemplate); ok {
+		existing = oldTemplate.duplicateGenerateNames(nil)
+		existingEmpty = oldTemplate.emptyClusterRoleSelectors(nil)
+	}
+	if err := duplicateGenerateNamesError(r.duplicateGenerateNames(existing)); err != nil {
+		return err
+	}
+	return emptyClusterRoleSelectorsError(r.emptyClusterRoleSelectors(existingEmpty))
+}
+
+// ValidateDelete implements webhook.Validator.
+func (r *ScopeTemplate) ValidateDelete() error {
+	return nil
+}
+
+// duplicateGenerateNames returns the generateNames shared by several entries
+// of the ScopeTemplate, ClusterRoles and Bindings alike, leaving out the
+// allowed ones.
+func (r *ScopeTemplate) duplicateGenerateNames(allowed []string) []string {
+	counts := map[string]int{}
+	var duplicates []string
+	for _, bt := range r.Spec.BindingTemplates() {
+		counts[bt.GenerateName]++
+		if counts[bt.GenerateName] == 2 && !contains(allowed, bt.GenerateName) {
+			duplicates = append(duplicates, bt.GenerateName)
+		}
+	}
+	return duplicates
+}
+
+func duplicateGenerateNamesError(duplicates []string) error {
+	if len(duplicates) == 0 {
+		return nil
+	}
+	return fmt.Errorf("the generateName of each ClusterRole and Binding must be unique, found several %s", strings.Join(duplicates, ", "))
+}
+
+// emptyClusterRoleSelectors returns the generateNames of the Bindings of the
+// ScopeTemplate whose clusterRoleSelector is set but empty, leaving out the
+// allowed ones.
+func (r *ScopeTemplate) emptyClusterRoleSelectors(allowed []string) []string {
+	var empty []string
+	for _, b := range r.Spec.Bindings {
+		selector := b.ClusterRoleSelector
+		if selector != nil && len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0 && !contains(allowed, b.GenerateName) {
+			empty = append(empty, b.GenerateName)
+		}
+	}
+	return empty
+}
+
+func emptyClusterRoleSelectorsError(empty []string) error {
+	if len(empty) == 0 {
+		return nil
+	}
+	return fmt.Errorf("the clusterRoleSelector of a Binding must not be empty, as it would bind every ClusterRole, found an empty one in %s", strings.Join(empty, ", "))
+}
@@ -0,0 +1,66 @@
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("ScopeTemplate webhook", func() {
+	var st *ScopeTemplate
+	BeforeEach(func() {
+		st = &ScopeTemplate{Spec: ScopeTemplateSpec{
+			ClusterRoles: []ClusterRoleTemplate{{GenerateName: "view"}, {GenerateName: "edit"}},
+			Bindings: []BindingTemplate{{
+				GenerateName: "admin",
+				RoleRef:      rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "admin"},
+			}},
+		}}
+	})
+
+	It("should admit a ScopeTemplate with unique generateNames", func() {
+		Expect(st.ValidateCreate()).To(Succeed())
+	})
+
+	It("should deny a ScopeTemplate with colliding generateNames", func() {
+		st.Spec.ClusterRoles[1].GenerateName = "view"
+		st.Spec.Bindings[0].GenerateName = "view"
+		Expect(st.ValidateCreate()).To(MatchError(ContainSubstring("found several view")))
+
+		By("colliding between a ClusterRole and a Binding")
+		st.Spec.ClusterRoles[1].GenerateName = "edit"
+		st.Spec.Bindings[0].GenerateName = "edit"
+		Expect(st.ValidateCreate()).To(MatchError(ContainSubstring("found several edit")))
+	})
+
+	It("should only deny the collisions introduced by an update", func() {
+		old := st.DeepCopy()
+		old.Spec.ClusterRoles[1].GenerateName = "view"
+		Expect(old.DeepCopy().ValidateUpdate(old)).To(Succeed())
+
+		updated := old.DeepCopy()
+		updated.Spec.Bindings[0].GenerateName = "admin-2"
+		Expect(updated.ValidateUpdate(old)).To(Succeed())
+
+		Expect(st.ValidateUpdate(st.DeepCopy())).To(Succeed())
+		updated = st.DeepCopy()
+		updated.Spec.Bindings[0].GenerateName = "edit"
+		Expect(updated.ValidateUpdate(st)).To(MatchError(ContainSubstring("found several edit")))
+	})
+
+	It("should deny an empty clusterRoleSelector", func() {
+		st.Spec.Bindings[0].ClusterRoleSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+		Expect(st.ValidateCreate()).To(Succeed())
+
+		st.Spec.Bindings[0].ClusterRoleSelector = &metav1.LabelSelector{}
+		Expect(st.ValidateCreate()).To(MatchError(ContainSubstring("found an empty one in admin")))
+
+		By("admitting the updates of a ScopeTemplate that had it already")
+		Expect(st.ValidateUpdate(st.DeepCopy())).To(Succeed())
+		old := st.DeepCopy()
+		old.Spec.Bindings[0].ClusterRoleSelector = nil
+		Expect(st.ValidateUpdate(old)).To(MatchError(ContainSubstring("found an empty one in admin")))
+	})
+})
@@ -24,9 +24,61 @@ package v1alpha1
 import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BindingTemplate) DeepCopyInto(out *BindingTemplate) {
+	*out = *in
+	out.RoleRef = in.RoleRef
+	if in.ClusterRoleSelector != nil {
+		in, out := &in.ClusterRoleSelector, &out.ClusterRoleSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Subjects != nil {
+		in, out := &in.Subjects, &out.Subjects
+		*out = make([]rbacv1.Subject, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServiceAccountSelector != nil {
+		in, out := &in.ServiceAccountSelector, &out.ServiceAccountSelector
+		*out = new(ServiceAccountSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GroupSets != nil {
+		in, out := &in.GroupSets, &out.GroupSets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSubjects != nil {
+		in, out := &in.NamespaceSubjects, &out.NamespaceSubjects
+		*out = make(map[string][]rbacv1.Subject, len(*in))
+		for key, val := range *in {
+			var outVal []rbacv1.Subject
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]rbacv1.Subject, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BindingTemplate.
+func (in *BindingTemplate) DeepCopy() *BindingTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(BindingTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterRoleTemplate) DeepCopyInto(out *ClusterRoleTemplate) {
 	*out = *in
@@ -42,6 +94,32 @@ func (in *ClusterRoleTemplate) DeepCopyInto(out *ClusterRoleTemplate) {
 		*out = make([]rbacv1.Subject, len(*in))
 		copy(*out, *in)
 	}
+	if in.ServiceAccountSelector != nil {
+		in, out := &in.ServiceAccountSelector, &out.ServiceAccountSelector
+		*out = new(ServiceAccountSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GroupSets != nil {
+		in, out := &in.GroupSets, &out.GroupSets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSubjects != nil {
+		in, out := &in.NamespaceSubjects, &out.NamespaceSubjects
+		*out = make(map[string][]rbacv1.Subject, len(*in))
+		for key, val := range *in {
+			var outVal []rbacv1.Subject
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]rbacv1.Subject, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterRoleTemplate.
@@ -54,6 +132,22 @@ func (in *ClusterRoleTemplate) DeepCopy() *ClusterRoleTemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GeneratedBinding) DeepCopyInto(out *GeneratedBinding) {
+	*out = *in
+	out.RoleRef = in.RoleRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GeneratedBinding.
+func (in *GeneratedBinding) DeepCopy() *GeneratedBinding {
+	if in == nil {
+		return nil
+	}
+	out := new(GeneratedBinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScopeInstance) DeepCopyInto(out *ScopeInstance) {
 	*out = *in
@@ -121,6 +215,29 @@ func (in *ScopeInstanceSpec) DeepCopyInto(out *ScopeInstanceSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ScopeTemplateRef != nil {
+		in, out := &in.ScopeTemplateRef, &out.ScopeTemplateRef
+		*out = new(ScopeTemplateReference)
+		**out = **in
+	}
+	if in.NamespaceAnnotationSelector != nil {
+		in, out := &in.NamespaceAnnotationSelector, &out.NamespaceAnnotationSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ClusterRoleNameOverrides != nil {
+		in, out := &in.ClusterRoleNameOverrides, &out.ClusterRoleNameOverrides
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScopeInstanceSpec.
@@ -143,6 +260,36 @@ func (in *ScopeInstanceStatus) DeepCopyInto(out *ScopeInstanceStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TerminatingNamespaces != nil {
+		in, out := &in.TerminatingNamespaces, &out.TerminatingNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ForbiddenNamespaces != nil {
+		in, out := &in.ForbiddenNamespaces, &out.ForbiddenNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.GeneratedBindings != nil {
+		in, out := &in.GeneratedBindings, &out.GeneratedBindings
+		*out = make([]GeneratedBinding, len(*in))
+		copy(*out, *in)
+	}
+	if in.DryRunDiscrepancies != nil {
+		in, out := &in.DryRunDiscrepancies, &out.DryRunDiscrepancies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingClusterRoles != nil {
+		in, out := &in.PendingClusterRoles, &out.PendingClusterRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScopeInstanceStatus.
@@ -214,6 +361,21 @@ func (in *ScopeTemplateList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScopeTemplateReference) DeepCopyInto(out *ScopeTemplateReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScopeTemplateReference.
+func (in *ScopeTemplateReference) DeepCopy() *ScopeTemplateReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ScopeTemplateReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScopeTemplateSpec) DeepCopyInto(out *ScopeTemplateSpec) {
 	*out = *in
@@ -224,6 +386,13 @@ func (in *ScopeTemplateSpec) DeepCopyInto(out *ScopeTemplateSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Bindings != nil {
+		in, out := &in.Bindings, &out.Bindings
+		*out = make([]BindingTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScopeTemplateSpec.
@@ -246,6 +415,11 @@ func (in *ScopeTemplateStatus) DeepCopyInto(out *ScopeTemplateStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ScopeInstances != nil {
+		in, out := &in.ScopeInstances, &out.ScopeInstances
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScopeTemplateStatus.
@@ -257,3 +431,19 @@ func (in *ScopeTemplateStatus) DeepCopy() *ScopeTemplateStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceAccountSelector) DeepCopyInto(out *ServiceAccountSelector) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceAccountSelector.
+func (in *ServiceAccountSelector) DeepCopy() *ServiceAccountSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceAccountSelector)
+	in.DeepCopyInto(out)
+	return out
+}
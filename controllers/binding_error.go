@@ -0,0 +1,79 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// bindingError is an error caused by an operation on a single
+// (Cluster)RoleBinding, identifying the binding involved.
+type bindingError struct {
+	// Op is the operation that failed, such as "create" or "delete".
+	Op string
+	// Kind is either ClusterRoleBinding or RoleBinding.
+	Kind string
+	// Namespace is empty for a ClusterRoleBinding.
+	Namespace string
+	// Name is the name of the binding, or its generateName if it was not
+	// found or created.
+	Name string
+	Err  error
+}
+
+// newBindingError wraps err with the metadata of the binding, returning nil
+// if err is nil.
+func newBindingError(op string, binding client.Object, err error) error {
+	if err == nil {
+		return nil
+	}
+	kind := bindingKind(binding)
+	if kind == "" {
+		kind = binding.GetObjectKind().GroupVersionKind().Kind
+	}
+	name := binding.GetName()
+	if name == "" {
+		name = binding.GetGenerateName()
+	}
+	return &bindingError{
+		Op:        op,
+		Kind:      kind,
+		Namespace: binding.GetNamespace(),
+		Name:      name,
+		Err:       err,
+	}
+}
+
+func (e *bindingError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Op, e.object(), e.Err)
+}
+
+func (e *bindingError) Unwrap() error {
+	return e.Err
+}
+
+// object describes the binding as <Kind> [<namespace>/]<name>.
+func (e *bindingError) object() string {
+	return e.Kind + " " + client.ObjectKey{Namespace: e.Namespace, Name: e.Name}.String()
+}
+
+// keysAndValues returns the binding metadata for a structured log line.
+func (e *bindingError) keysAndValues() []interface{} {
+	return []interface{}{"kind", e.Kind, "namespace", e.Namespace, "name", e.Name}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+var _ = Describe("BindingError", func() {
+	It("should expose the metadata of the binding that failed", func() {
+		si := &operatorsv1.ScopeInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "scopeinstance-binding-error",
+				UID:  "scopeinstance-binding-error-uid",
+			},
+			Spec: operatorsv1.ScopeInstanceSpec{
+				ScopeTemplateName: "scopetemplate-binding-error",
+				Namespaces:        []string{"ns-1"},
+			},
+		}
+		recorder := record.NewFakeRecorder(10)
+		r := &ScopeInstanceReconciler{
+			Client: &failingCreateClient{
+				Client:    newFakeClient(si, newTestScopeTemplate(si.Spec.ScopeTemplateName), newTestClusterRole("test")),
+				namespace: "ns-1",
+			},
+			Scheme:   scheme.Scheme,
+			Recorder: recorder,
+		}
+
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+		Expect(err).To(HaveOccurred())
+
+		var bindingErr *bindingError
+		Expect(errors.As(err, &bindingErr)).To(BeTrue())
+		Expect(bindingErr.Op).To(Equal("create"))
+		Expect(bindingErr.Kind).To(Equal("RoleBinding"))
+		Expect(bindingErr.Namespace).To(Equal("ns-1"))
+		Expect(bindingErr.Name).To(HavePrefix("test-"))
+		Expect(err.Error()).To(HavePrefix("create RoleBinding ns-1/test-"))
+
+		Expect(recorder.Events).To(Receive(And(
+			HavePrefix(corev1.EventTypeWarning+" "+operatorsv1.ReasonScopingFailed),
+			ContainSubstring("Failed to create RoleBinding ns-1/test-"),
+		)))
+	})
+
+	It("should name the binding that is not owned by a ScopeInstance", func() {
+		unowned := &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "unowned"}}
+		r := &ScopeInstanceReconciler{Client: newFakeClient(unowned), Scheme: scheme.Scheme}
+
+		err := r.deleteBindings(ctx, []client.Object{unowned})
+		Expect(err).To(MatchError(errUnscopedDelete))
+
+		var bindingErr *bindingError
+		Expect(errors.As(err, &bindingErr)).To(BeTrue())
+		Expect(bindingErr.Op).To(Equal("delete"))
+		Expect(bindingErr.Kind).To(Equal("ClusterRoleBinding"))
+		Expect(bindingErr.Namespace).To(BeEmpty())
+		Expect(bindingErr.Name).To(Equal("unowned"))
+	})
+})
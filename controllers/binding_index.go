@@ -0,0 +1,151 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+)
+
+// bindingIndexKey identifies the binding a ScopeInstance creates for a single
+// ClusterRole in a namespace. ClusterRoleBindings use an empty namespace.
+type bindingIndexKey struct {
+	kind             string
+	scopeInstanceUID string
+	generateName     string
+	namespace        string
+}
+
+// bindingIndexKeyFor returns the key of a binding created by the
+// ScopeInstance controller, based on its bookkeeping labels.
+func bindingIndexKeyFor(keys *keyMapping, obj client.Object) (bindingIndexKey, bool) {
+	key := bindingIndexKey{
+		scopeInstanceUID: obj.GetLabels()[keys.key(scopeInstanceUIDKey)],
+		generateName:     obj.GetLabels()[keys.key(clusterRoleBindingGenerateKey)],
+		namespace:        obj.GetNamespace(),
+	}
+	key.kind = bindingKind(obj)
+	return key, key.kind != "" && key.scopeInstanceUID != ""
+}
+
+// bindingKind returns the kind of a (Cluster)RoleBinding, or an empty
+// string for any other object.
+func bindingKind(obj client.Object) string {
+	switch obj.(type) {
+	case *rbacv1.RoleBinding:
+		return "RoleBinding"
+	case *rbacv1.ClusterRoleBinding:
+		return "ClusterRoleBinding"
+	default:
+		return ""
+	}
+}
+
+// bindingRoleRef returns the roleRef of a (Cluster)RoleBinding.
+func bindingRoleRef(binding client.Object) rbacv1.RoleRef {
+	switch b := binding.(type) {
+	case *rbacv1.RoleBinding:
+		return b.RoleRef
+	case *rbacv1.ClusterRoleBinding:
+		return b.RoleRef
+	default:
+		return rbacv1.RoleRef{}
+	}
+}
+
+// bindingIndex remembers the (Cluster)RoleBinding found for each
+// ScopeInstance, ClusterRole and namespace so that steady state reconciles
+// do not need to list bindings again. Entries are dropped whenever the
+// binding watch observes a change to the binding. A nil bindingIndex is
+// valid and never caches anything.
+type bindingIndex struct {
+	keys *keyMapping
+
+	mu       sync.RWMutex
+	bindings map[bindingIndexKey]client.Object
+}
+
+func newBindingIndex(keys *keyMapping) *bindingIndex {
+	return &bindingIndex{keys: keys, bindings: map[bindingIndexKey]client.Object{}}
+}
+
+// get returns a copy of the binding stored for the key.
+func (i *bindingIndex) get(key bindingIndexKey) (client.Object, bool) {
+	if i == nil {
+		return nil, false
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	obj, ok := i.bindings[key]
+	if !ok {
+		return nil, false
+	}
+	return obj.DeepCopyObject().(client.Object), true
+}
+
+// set stores a copy of the binding under its key.
+func (i *bindingIndex) set(obj client.Object) {
+	if i == nil {
+		return
+	}
+	key, ok := bindingIndexKeyFor(i.keys, obj)
+	if !ok {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.bindings[key] = obj.DeepCopyObject().(client.Object)
+}
+
+// invalidate drops the entry stored for the binding, if any.
+func (i *bindingIndex) invalidate(obj client.Object) {
+	if i == nil || obj == nil {
+		return
+	}
+	key, ok := bindingIndexKeyFor(i.keys, obj)
+	if !ok {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.bindings, key)
+}
+
+// eventHandler returns a handler that invalidates the index for every
+// binding event without enqueuing any requests.
+func (i *bindingIndex) eventHandler() handler.EventHandler {
+	return handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, _ workqueue.RateLimitingInterface) {
+			i.invalidate(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent, _ workqueue.RateLimitingInterface) {
+			i.invalidate(e.ObjectOld)
+			i.invalidate(e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent, _ workqueue.RateLimitingInterface) {
+			i.invalidate(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent, _ workqueue.RateLimitingInterface) {
+			i.invalidate(e.Object)
+		},
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+var _ = Describe("bindingIndex", func() {
+	var (
+		index *bindingIndex
+		rb    *rbacv1.RoleBinding
+		key   bindingIndexKey
+	)
+	BeforeEach(func() {
+		index = newBindingIndex(nil)
+		rb = &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-x8hdc",
+				Namespace: "default",
+				Labels: map[string]string{
+					scopeInstanceUIDKey:           "scopeinstance-uid",
+					clusterRoleBindingGenerateKey: "test",
+				},
+			},
+		}
+		key = bindingIndexKey{
+			kind:             "RoleBinding",
+			scopeInstanceUID: "scopeinstance-uid",
+			generateName:     "test",
+			namespace:        "default",
+		}
+	})
+
+	It("should return a copy of a stored binding", func() {
+		index.set(rb)
+		cached, ok := index.get(key)
+		Expect(ok).To(BeTrue())
+		Expect(cached).To(Equal(rb))
+		Expect(cached).NotTo(BeIdenticalTo(rb))
+	})
+
+	It("should ignore bindings without a ScopeInstance UID", func() {
+		delete(rb.Labels, scopeInstanceUIDKey)
+		index.set(rb)
+		Expect(index.bindings).To(BeEmpty())
+	})
+
+	It("should be invalidated by binding events", func() {
+		handler := index.eventHandler()
+
+		index.set(rb)
+		handler.Create(event.CreateEvent{Object: rb}, nil)
+		_, ok := index.get(key)
+		Expect(ok).To(BeFalse())
+
+		index.set(rb)
+		handler.Update(event.UpdateEvent{ObjectOld: rb, ObjectNew: rb.DeepCopy()}, nil)
+		_, ok = index.get(key)
+		Expect(ok).To(BeFalse())
+
+		index.set(rb)
+		handler.Delete(event.DeleteEvent{Object: rb}, nil)
+		_, ok = index.get(key)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("should never cache anything when nil", func() {
+		var nilIndex *bindingIndex
+		nilIndex.set(rb)
+		_, ok := nilIndex.get(key)
+		Expect(ok).To(BeFalse())
+	})
+})
+
+// listCountingClient counts the List calls made through the client.
+type listCountingClient struct {
+	client.Client
+	lists int
+}
+
+func (c *listCountingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	c.lists++
+	return c.Client.List(ctx, list, opts...)
+}
+
+func BenchmarkReconcileWithoutBindingIndex(b *testing.B) {
+	benchmarkReconcile(b, nil)
+}
+
+func BenchmarkReconcileWithBindingIndex(b *testing.B) {
+	benchmarkReconcile(b, newBindingIndex(nil))
+}
+
+// benchmarkReconcile measures steady state reconciles of a ScopeInstance
+// that binds a ClusterRole in 50 namespaces.
+func benchmarkReconcile(b *testing.B, index *bindingIndex) {
+	if err := operatorsv1.AddToScheme(scheme.Scheme); err != nil {
+		b.Fatal(err)
+	}
+
+	st := newTestScopeTemplate("scopetemplate-benchmark")
+	si := &operatorsv1.ScopeInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "scopeinstance-benchmark",
+			UID:  "scopeinstance-benchmark-uid",
+		},
+		Spec: operatorsv1.ScopeInstanceSpec{
+			ScopeTemplateName: st.GetName(),
+		},
+	}
+	for i := 0; i < 50; i++ {
+		si.Spec.Namespaces = append(si.Spec.Namespaces, fmt.Sprintf("namespace-%d", i))
+	}
+
+	c := &listCountingClient{Client: newFakeClient(si, st, newTestClusterRole("test"))}
+	r := &ScopeInstanceReconciler{
+		Client:   c,
+		Scheme:   scheme.Scheme,
+		bindings: index,
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}}
+	ctx := context.Background()
+
+	// Create the bindings so that the benchmark only covers steady state reconciles
+	if _, err := r.Reconcile(ctx, req); err != nil {
+		b.Fatal(err)
+	}
+
+	c.lists = 0
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(c.lists)/float64(b.N), "lists/op")
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"k8s.io/apimachinery/pkg/api/validation/path"
+	"k8s.io/apimachinery/pkg/types"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+// errInvalidBindingNameTemplate is wrapped by every error caused by the
+// BindingNameTemplate of a ScopeInstance, as opposed to the cluster.
+var errInvalidBindingNameTemplate = errors.New("invalid binding name template")
+
+// errBindingNameCollision is wrapped by the errors of RoleBinding names
+// rendered by a BindingNameTemplate that are already taken.
+var errBindingNameCollision = errors.New("RoleBinding name collision")
+
+// bindingNameData is what a BindingNameTemplate is rendered with.
+type bindingNameData struct {
+	ScopeInstance string
+	Namespace     string
+	ClusterRole   string
+	GenerateName  string
+}
+
+// roleBindingName returns the name rendered by the BindingNameTemplate of the
+// ScopeInstance for the RoleBinding of the ClusterRole in namespace, or an
+// empty name if the ScopeInstance has none.
+func (r *ScopeInstanceReconciler) roleBindingName(cr *operatorsv1.BindingTemplate, in *operatorsv1.ScopeInstance, namespace string) (string, error) {
+	if in.Spec.BindingNameTemplate == "" {
+		return "", nil
+	}
+
+	tmpl, err := template.New("bindingNameTemplate").Parse(in.Spec.BindingNameTemplate)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", errInvalidBindingNameTemplate, err)
+	}
+	var name strings.Builder
+	if err := tmpl.Execute(&name, bindingNameData{
+		ScopeInstance: in.GetName(),
+		Namespace:     namespace,
+		ClusterRole:   roleRef(cr, in).Name,
+		GenerateName:  cr.GenerateName,
+	}); err != nil {
+		return "", fmt.Errorf("%w: %s", errInvalidBindingNameTemplate, err)
+	}
+
+	rendered := r.ShadowPrefix + name.String()
+	if rendered == "" {
+		return "", fmt.Errorf("%w: rendered an empty name for ClusterRole %s in namespace %s", errInvalidBindingNameTemplate, cr.GenerateName, namespace)
+	}
+	if msgs := path.IsValidPathSegmentName(rendered); len(msgs) > 0 {
+		return "", fmt.Errorf("%w: rendered name %q is invalid: %s", errInvalidBindingNameTemplate, rendered, strings.Join(msgs, ", "))
+	}
+	return rendered, nil
+}
+
+// bindingNames records the RoleBinding names rendered during a reconcile and
+// the ScopeTemplate entry each was rendered for, so that two entries sharing
+// a name are reported instead of taking turns at the same RoleBinding.
+type bindingNames map[types.NamespacedName]string
+
+// claim records the name of the RoleBinding of the entry in namespace. An
+// empty name is generated, and never collides.
+func (n bindingNames) claim(namespace, name, generateName string) error {
+	if name == "" {
+		return nil
+	}
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	if other, ok := n[key]; ok && other != generateName {
+		return fmt.Errorf("%w: ClusterRoles %s and %s both render RoleBinding %s", errBindingNameCollision, other, generateName, key)
+	}
+	n[key] = generateName
+	return nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+var _ = Describe("forEachBounded", func() {
+	It("should call fn in order up to the first error with a single worker", func() {
+		var called []int
+		err := forEachBounded(5, 1, func(i int) error {
+			called = append(called, i)
+			if i == 2 {
+				return errors.New("failed")
+			}
+			return nil
+		})
+		Expect(err).To(MatchError("failed"))
+		Expect(called).To(Equal([]int{0, 1, 2}))
+	})
+
+	It("should aggregate the errors in index order", func() {
+		// Both calls are started before either fails
+		var started sync.WaitGroup
+		started.Add(2)
+		err := forEachBounded(2, 2, func(i int) error {
+			started.Done()
+			started.Wait()
+			return fmt.Errorf("failed %d", i)
+		})
+		Expect(err).To(MatchError("[failed 0, failed 1]"))
+	})
+
+	It("should succeed without calling fn when there is nothing to do", func() {
+		Expect(forEachBounded(0, 4, func(int) error {
+			return errors.New("called")
+		})).To(Succeed())
+	})
+})
+
+// latencyClient delays every call to the API server, as the fake client
+// answers instantly.
+type latencyClient struct {
+	client.Client
+	latency time.Duration
+}
+
+func (c *latencyClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	time.Sleep(c.latency)
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c *latencyClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	time.Sleep(c.latency)
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *latencyClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	time.Sleep(c.latency)
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *latencyClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	time.Sleep(c.latency)
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func BenchmarkCreateRoleBindingsSerially(b *testing.B) {
+	benchmarkCreateRoleBindings(b, 1)
+}
+
+func BenchmarkCreateRoleBindingsConcurrently(b *testing.B) {
+	benchmarkCreateRoleBindings(b, 8)
+}
+
+// benchmarkCreateRoleBindings measures the first reconcile of a ScopeInstance
+// that binds a ClusterRole in 200 namespaces, against an API server
+// answering in a millisecond.
+func benchmarkCreateRoleBindings(b *testing.B, workers int) {
+	if err := operatorsv1.AddToScheme(scheme.Scheme); err != nil {
+		b.Fatal(err)
+	}
+
+	st := newTestScopeTemplate("scopetemplate-benchmark")
+	si := &operatorsv1.ScopeInstance{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "scopeinstance-benchmark",
+			UID:  "scopeinstance-benchmark-uid",
+		},
+		Spec: operatorsv1.ScopeInstanceSpec{
+			ScopeTemplateName: st.GetName(),
+		},
+	}
+	for i := 0; i < 200; i++ {
+		si.Spec.Namespaces = append(si.Spec.Namespaces, fmt.Sprintf("namespace-%d", i))
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}}
+	ctx := context.Background()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		r := &ScopeInstanceReconciler{
+			Client: &latencyClient{
+				Client:  newFakeClient(si.DeepCopy(), st.DeepCopy(), newTestClusterRole("test")),
+				latency: time.Millisecond,
+			},
+			Scheme:                     scheme.Scheme,
+			MaxConcurrentBindingWrites: workers,
+		}
+		b.StartTimer()
+
+		if _, err := r.Reconcile(ctx, req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+// The conformance suite codifies the acceptance criteria of the original
+// design against the API server started by envtest. Envtest does not run the
+// garbage collector, so bindings removed along with their ScopeInstance are
+// verified through their owner references.
+var _ = Describe("Conformance", func() {
+	const clusterRoleName = "conformance"
+
+	var (
+		st         *operatorsv1.ScopeTemplate
+		si         *operatorsv1.ScopeInstance
+		namespace  *corev1.Namespace
+		namespace2 *corev1.Namespace
+	)
+	BeforeEach(func() {
+		namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "conformance-"}}
+		Expect(k8sClient.Create(ctx, namespace)).To(Succeed())
+		namespace2 = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "conformance-"}}
+		Expect(k8sClient.Create(ctx, namespace2)).To(Succeed())
+
+		st = newTestScopeTemplate("scopetemplate-conformance")
+		st.SetUID("")
+		st.Spec.ClusterRoles[0].GenerateName = clusterRoleName
+		Expect(k8sClient.Create(ctx, st)).To(Succeed())
+	})
+	AfterEach(func() {
+		if si != nil {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, si))).To(Succeed())
+			si = nil
+		}
+		Expect(k8sClient.Delete(ctx, st)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, namespace)).To(Succeed())
+		Expect(k8sClient.Delete(ctx, namespace2)).To(Succeed())
+
+		// cleanup ClusterRoles since OwnerReferences do not work in envtest
+		cr := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: clusterRoleName}}
+		Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, cr))).To(Succeed())
+	})
+
+	createScopeInstance := func(name, scopeTemplateName string, namespaces ...string) {
+		si = &operatorsv1.ScopeInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: operatorsv1.ScopeInstanceSpec{
+				ScopeTemplateName: scopeTemplateName,
+				Namespaces:        namespaces,
+			},
+		}
+		Expect(k8sClient.Create(ctx, si)).To(Succeed())
+	}
+	updateScopeInstance := func(mutate func(*operatorsv1.ScopeInstance)) {
+		Eventually(func() error {
+			latest := &operatorsv1.ScopeInstance{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(si), latest); err != nil {
+				return err
+			}
+			mutate(latest)
+			return k8sClient.Update(ctx, latest)
+		}, timeout, interval).Should(Succeed())
+	}
+	bindingLabels := func() map[string]string {
+		return map[string]string{scopeInstanceUIDKey: string(si.GetUID())}
+	}
+	expectClusterRoleDeleted := func() {
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, client.ObjectKey{Name: clusterRoleName}, &rbacv1.ClusterRole{})
+			return k8sapierrors.IsNotFound(err)
+		}, timeout, interval).Should(BeTrue())
+	}
+
+	It("should not create RBAC for a ScopeInstance referencing a nonexistent ScopeTemplate", func() {
+		createScopeInstance("scopeinstance-conformance-nonexistent", "nonexistent", namespace.GetName())
+
+		Eventually(func() string {
+			latest := &operatorsv1.ScopeInstance{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(si), latest); err != nil {
+				return err.Error()
+			}
+			if cond := meta.FindStatusCondition(latest.Status.Conditions, operatorsv1.TypeScoped); cond != nil {
+				return cond.Reason
+			}
+			return ""
+		}, timeout, interval).Should(Equal(operatorsv1.ReasonScopeTemplateNotFound))
+
+		Consistently(func() int {
+			crbs := &rbacv1.ClusterRoleBindingList{}
+			rbs := &rbacv1.RoleBindingList{}
+			Expect(k8sClient.List(ctx, crbs, client.MatchingLabels(bindingLabels()))).To(Succeed())
+			Expect(k8sClient.List(ctx, rbs, client.MatchingLabels(bindingLabels()))).To(Succeed())
+			return len(crbs.Items) + len(rbs.Items)
+		}, 2*time.Second, interval).Should(BeZero())
+		Expect(k8sapierrors.IsNotFound(k8sClient.Get(ctx, client.ObjectKey{Name: clusterRoleName}, &rbacv1.ClusterRole{}))).To(BeTrue())
+	})
+
+	It("should create a RoleBinding in each namespace of a ScopeInstance", func() {
+		createScopeInstance("scopeinstance-conformance-namespaced", st.GetName(), namespace.GetName(), namespace2.GetName())
+
+		for _, ns := range []*corev1.Namespace{namespace, namespace2} {
+			rbs := listRoleBinding(ns.GetName(), 1, bindingLabels())
+			Expect(rbs.Items[0].RoleRef.Name).To(Equal(clusterRoleName))
+		}
+		listClusterRoleBinding(0, bindingLabels())
+	})
+
+	It("should create a ClusterRoleBinding for a ScopeInstance without namespaces", func() {
+		createScopeInstance("scopeinstance-conformance-cluster", st.GetName())
+
+		crbs := listClusterRoleBinding(1, bindingLabels())
+		Expect(crbs.Items[0].RoleRef.Name).To(Equal(clusterRoleName))
+		listRoleBinding(namespace.GetName(), 0, bindingLabels())
+	})
+
+	It("should delete the RBAC of a ScopeInstance updated to reference a nonexistent ScopeTemplate", func() {
+		createScopeInstance("scopeinstance-conformance-retarget", st.GetName(), namespace.GetName())
+		listRoleBinding(namespace.GetName(), 1, bindingLabels())
+
+		updateScopeInstance(func(in *operatorsv1.ScopeInstance) {
+			in.Spec.ScopeTemplateName = "nonexistent"
+		})
+
+		listRoleBinding(namespace.GetName(), 0, bindingLabels())
+		expectClusterRoleDeleted()
+	})
+
+	It("should adjust the RoleBindings as namespaces are added and removed", func() {
+		createScopeInstance("scopeinstance-conformance-adjust", st.GetName(), namespace.GetName())
+		listRoleBinding(namespace.GetName(), 1, bindingLabels())
+		listRoleBinding(namespace2.GetName(), 0, bindingLabels())
+
+		By("adding a namespace")
+		updateScopeInstance(func(in *operatorsv1.ScopeInstance) {
+			in.Spec.Namespaces = []string{namespace.GetName(), namespace2.GetName()}
+		})
+		listRoleBinding(namespace.GetName(), 1, bindingLabels())
+		listRoleBinding(namespace2.GetName(), 1, bindingLabels())
+
+		By("removing a namespace")
+		updateScopeInstance(func(in *operatorsv1.ScopeInstance) {
+			in.Spec.Namespaces = []string{namespace2.GetName()}
+		})
+		listRoleBinding(namespace.GetName(), 0, bindingLabels())
+		listRoleBinding(namespace2.GetName(), 1, bindingLabels())
+	})
+
+	It("should remove the RBAC of a deleted ScopeInstance", func() {
+		createScopeInstance("scopeinstance-conformance-delete", st.GetName(), namespace.GetName())
+		rbs := listRoleBinding(namespace.GetName(), 1, bindingLabels())
+
+		// The garbage collector deletes the bindings along with their owner
+		Expect(rbs.Items[0].OwnerReferences).To(ConsistOf(metav1.OwnerReference{
+			APIVersion:         operatorsv1.GroupVersion.String(),
+			Kind:               "ScopeInstance",
+			Name:               si.GetName(),
+			UID:                si.GetUID(),
+			Controller:         pointer.Bool(true),
+			BlockOwnerDeletion: pointer.Bool(true),
+		}))
+
+		Expect(k8sClient.Delete(ctx, si)).To(Succeed())
+		expectClusterRoleDeleted()
+	})
+})
+
+func listClusterRoleBinding(numberOfExpectedClusterRoleBindings int, labels map[string]string) *rbacv1.ClusterRoleBindingList {
+	clusterRoleBindingList := &rbacv1.ClusterRoleBindingList{}
+	Eventually(func() error {
+		if err := k8sClient.List(ctx, clusterRoleBindingList, client.MatchingLabels(labels)); err != nil {
+			return err
+		}
+
+		if len(clusterRoleBindingList.Items) != numberOfExpectedClusterRoleBindings {
+			return fmt.Errorf("Expected %d clusterRoleBinding, found %d", numberOfExpectedClusterRoleBindings, len(clusterRoleBindingList.Items))
+		}
+
+		return nil
+	}, timeout, interval).Should(BeNil())
+
+	return clusterRoleBindingList
+}
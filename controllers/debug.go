@@ -0,0 +1,159 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+// DebugPath is the path the debug state is served on.
+const DebugPath = "/debug/scopeinstances"
+
+// debugScopeInstance is the debug state of a single ScopeInstance.
+type debugScopeInstance struct {
+	Name                  string             `json:"name"`
+	ScopeTemplate         string             `json:"scopeTemplate"`
+	Namespaces            []string           `json:"namespaces"`
+	ClusterRoleBindings   int                `json:"clusterRoleBindings"`
+	RoleBindings          int                `json:"roleBindings"`
+	TerminatingNamespaces []string           `json:"terminatingNamespaces,omitempty"`
+	ForbiddenNamespaces   []string           `json:"forbiddenNamespaces,omitempty"`
+	Conditions            []metav1.Condition `json:"conditions"`
+}
+
+// DebugHandler serves every ScopeInstance as JSON, with the namespaces it
+// resolves to, the number of bindings it owns and the conditions of its last
+// reconcile.
+func (r *ScopeInstanceReconciler) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(w, "only GET is supported", http.StatusMethodNotAllowed)
+			return
+		}
+
+		state, err := r.debugState(req.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(state); err != nil {
+			log.Log.Error(err, "writing debug state")
+		}
+	})
+}
+
+func (r *ScopeInstanceReconciler) debugState(ctx context.Context) ([]debugScopeInstance, error) {
+	scopeInstanceList := &operatorsv1.ScopeInstanceList{}
+	if err := r.Client.List(ctx, scopeInstanceList); err != nil {
+		return nil, err
+	}
+	sort.Slice(scopeInstanceList.Items, func(i, j int) bool {
+		return scopeInstanceList.Items[i].GetName() < scopeInstanceList.Items[j].GetName()
+	})
+
+	state := make([]debugScopeInstance, 0, len(scopeInstanceList.Items))
+	for i := range scopeInstanceList.Items {
+		in := &scopeInstanceList.Items[i]
+
+		namespaces, err := r.resolveNamespaces(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+
+		listOption := client.MatchingLabels{
+			r.keys.key(scopeInstanceUIDKey): r.bindingOwner(in),
+		}
+		crbList := &rbacv1.ClusterRoleBindingList{}
+		if err := r.Client.List(ctx, crbList, listOption); err != nil {
+			return nil, err
+		}
+		rbList := &rbacv1.RoleBindingList{}
+		if err := r.Client.List(ctx, rbList, listOption); err != nil {
+			return nil, err
+		}
+
+		state = append(state, debugScopeInstance{
+			Name:                  in.GetName(),
+			ScopeTemplate:         scopeTemplateKey(in, r.DefaultScopeTemplate).Name,
+			Namespaces:            append([]string{}, namespaces...),
+			ClusterRoleBindings:   len(crbList.Items),
+			RoleBindings:          len(rbList.Items),
+			TerminatingNamespaces: in.Status.TerminatingNamespaces,
+			ForbiddenNamespaces:   in.Status.ForbiddenNamespaces,
+			Conditions:            append([]metav1.Condition{}, in.Status.Conditions...),
+		})
+	}
+	return state, nil
+}
+
+// DebugServer returns a runnable serving the DebugHandler on addr, which must
+// be a loopback address so that the state is not exposed outside the pod.
+func (r *ScopeInstanceReconciler) DebugServer(addr string) (manager.Runnable, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid debug address %q: %w", addr, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("debug address %q is not a loopback address", addr)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(DebugPath, r.DebugHandler())
+	return &debugServer{server: &http.Server{Addr: addr, Handler: mux}}, nil
+}
+
+type debugServer struct {
+	server *http.Server
+}
+
+var _ manager.LeaderElectionRunnable = &debugServer{}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, so that the
+// state can be inspected on every replica.
+func (s *debugServer) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *debugServer) Start(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		if err := s.server.Shutdown(context.Background()); err != nil {
+			log.Log.Error(err, "shutting down the debug server")
+		}
+	}()
+
+	log.Log.Info("serving debug state", "address", s.server.Addr, "path", DebugPath)
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
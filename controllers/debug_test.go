@@ -0,0 +1,107 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+var _ = Describe("DebugHandler", func() {
+	var r *ScopeInstanceReconciler
+	BeforeEach(func() {
+		st := newTestScopeTemplate("scopetemplate-debug")
+		clusterScoped := &operatorsv1.ScopeInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "scopeinstance-debug-a",
+				UID:  "scopeinstance-debug-a-uid",
+			},
+			Spec: operatorsv1.ScopeInstanceSpec{
+				ScopeTemplateName: st.GetName(),
+			},
+		}
+		namespaceScoped := &operatorsv1.ScopeInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "scopeinstance-debug-b",
+				UID:  "scopeinstance-debug-b-uid",
+			},
+			Spec: operatorsv1.ScopeInstanceSpec{
+				ScopeTemplateName: st.GetName(),
+				Namespaces:        []string{"ns-1", "ns-2"},
+			},
+		}
+		r = &ScopeInstanceReconciler{
+			Client: newFakeClient(clusterScoped, namespaceScoped, st, newTestClusterRole("test")),
+			Scheme: scheme.Scheme,
+		}
+
+		for _, in := range []*operatorsv1.ScopeInstance{clusterScoped, namespaceScoped} {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: in.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	It("should serve every ScopeInstance as JSON", func() {
+		rec := httptest.NewRecorder()
+		r.DebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DebugPath, nil))
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		var state []map[string]interface{}
+		Expect(json.Unmarshal(rec.Body.Bytes(), &state)).To(Succeed())
+		Expect(state).To(HaveLen(2))
+
+		Expect(state[0]).To(HaveKeyWithValue("name", "scopeinstance-debug-a"))
+		Expect(state[0]).To(HaveKeyWithValue("scopeTemplate", "scopetemplate-debug"))
+		Expect(state[0]).To(HaveKeyWithValue("namespaces", BeEmpty()))
+		Expect(state[0]).To(HaveKeyWithValue("clusterRoleBindings", BeNumerically("==", 1)))
+		Expect(state[0]).To(HaveKeyWithValue("roleBindings", BeNumerically("==", 0)))
+
+		Expect(state[1]).To(HaveKeyWithValue("name", "scopeinstance-debug-b"))
+		Expect(state[1]).To(HaveKeyWithValue("namespaces", ConsistOf("ns-1", "ns-2")))
+		Expect(state[1]).To(HaveKeyWithValue("clusterRoleBindings", BeNumerically("==", 0)))
+		Expect(state[1]).To(HaveKeyWithValue("roleBindings", BeNumerically("==", 2)))
+		Expect(state[1]).To(HaveKeyWithValue("conditions", ConsistOf(And(
+			HaveKeyWithValue("type", operatorsv1.TypeScoped),
+			HaveKeyWithValue("reason", operatorsv1.ReasonScopingSuccessful),
+		), And(
+			HaveKeyWithValue("type", operatorsv1.TypeProgressing),
+			HaveKeyWithValue("reason", operatorsv1.ReasonBindingsChanging),
+		))))
+	})
+
+	It("should only be served on loopback addresses", func() {
+		for _, addr := range []string{"127.0.0.1:8082", "localhost:8082", "[::1]:8082"} {
+			_, err := r.DebugServer(addr)
+			Expect(err).NotTo(HaveOccurred(), addr)
+		}
+		for _, addr := range []string{":8082", "0.0.0.0:8082", "10.0.0.1:8082", "8082"} {
+			_, err := r.DebugServer(addr)
+			Expect(err).To(HaveOccurred(), addr)
+		}
+	})
+})
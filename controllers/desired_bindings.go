@@ -0,0 +1,217 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+// DesiredBindings returns the bindings that should exist for the
+// ScopeInstance given the entries of its ScopeTemplate and the namespaces it
+// selects, without reading or writing anything. The ScopeTemplate has its
+// ClusterRoleSelectors expanded by withSelectedClusterRoles, and the entries
+// are those returned for it by resolvedBindingTemplates, which take the API.
+// Each binding is a *rbacv1.ClusterRoleBinding or a *rbacv1.RoleBinding, in
+// the order of the entries and, for each entry, of the namespaces. An entry
+// gets a ClusterRoleBinding if the ScopeInstance is cluster scoped or the
+// entry is always bound cluster wide. Otherwise it gets a RoleBinding in each
+// of the namespaces, and in the namespaces of its ServiceAccount subjects if
+// BindInSubjectNamespaces is set, unless they are excluded.
+func (r *ScopeInstanceReconciler) DesiredBindings(in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate, templates []operatorsv1.BindingTemplate, namespaces []string, excluded sets.String) ([]client.Object, error) {
+	namespaces = sets.NewString(namespaces...).List()
+
+	var desired []client.Object
+	names := bindingNames{}
+	for _, cr := range templates {
+		// An entry still selecting ClusterRoles has none to bind
+		if cr.ClusterRoleSelector != nil {
+			continue
+		}
+		if name := shortGenerateName(cr.GenerateName); name != cr.GenerateName {
+			log.Log.V(1).Info("warning: ClusterRole generateName is too long, shortening it in binding names and labels", "generateName", cr.GenerateName, "shortened", name)
+		}
+		// A namespace given to every binding adds to the subject namespaces
+		cr = withDefaultSubjectNamespace(cr, in, "")
+
+		if isClusterScoped(in) || isClusterBound(&cr) {
+			// A Role cannot be bound cluster wide
+			if bindsRole(&cr) {
+				continue
+			}
+			crbCR, err := withExpressionSubjects(cr, in, "")
+			if err != nil {
+				return nil, err
+			}
+			crbCR = withDefaultSubjectNamespace(crbCR, in, "")
+			desired = append(desired, r.clusterRoleBindingManifest(&crbCR, in, st))
+			continue
+		}
+
+		for _, ns := range bindingNamespaces(in, &cr, namespaces, excluded) {
+			rbCR, err := withExpressionSubjects(withNamespaceSubjects(cr, ns), in, ns)
+			if err != nil {
+				return nil, err
+			}
+			rbCR = withDefaultSubjectNamespace(rbCR, in, ns)
+			rb := r.roleBindingManifest(&rbCR, in, st, ns)
+			name, err := r.roleBindingName(&rbCR, in, rb.Namespace)
+			if err != nil {
+				return nil, err
+			}
+			if err := names.claim(rb.Namespace, name, rbCR.GenerateName); err != nil {
+				return nil, err
+			}
+			if name != "" {
+				rb.Name, rb.GenerateName = name, ""
+			}
+			desired = append(desired, rb)
+		}
+	}
+	return desired, nil
+}
+
+// desiredBindings returns the bindings DesiredBindings computes for the
+// ScopeInstance once the entries of the ScopeTemplate are resolved.
+func (r *ScopeInstanceReconciler) desiredBindings(ctx context.Context, in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate, namespaces []string, excluded sets.String) ([]client.Object, error) {
+	templates, err := r.resolvedBindingTemplates(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+	return r.DesiredBindings(in, st, templates, namespaces, excluded)
+}
+
+// resolvedBindingTemplates returns the entries of the ScopeTemplate with the
+// ServiceAccounts matched by their ServiceAccountSelector and the Groups of
+// their GroupSets added to their subjects.
+func (r *ScopeInstanceReconciler) resolvedBindingTemplates(ctx context.Context, st *operatorsv1.ScopeTemplate) ([]operatorsv1.BindingTemplate, error) {
+	// BindingTemplates may return the entries of the ScopeTemplate itself
+	templates := append([]operatorsv1.BindingTemplate(nil), st.Spec.BindingTemplates()...)
+	for i := range templates {
+		resolved, err := r.resolveServiceAccountSubjects(ctx, templates[i])
+		if err != nil {
+			return nil, err
+		}
+		if resolved, err = r.resolveGroupSetSubjects(ctx, resolved); err != nil {
+			return nil, err
+		}
+		templates[i] = resolved
+	}
+	return templates, nil
+}
+
+// errInvalidClusterRoleSelector is wrapped by the errors returned for
+// ScopeTemplates whose clusterRoleSelectors cannot be bound, as they select
+// every ClusterRole or their entries would share a generateName.
+var errInvalidClusterRoleSelector = errors.New("invalid clusterRoleSelector")
+
+// withSelectedClusterRoles returns the ScopeTemplate with each of its
+// Bindings selecting ClusterRoles by label replaced by an entry binding each
+// of the matching ClusterRoles, in the order of their names, so that they are
+// bound as if they were listed in the ScopeTemplate. The ScopeTemplate is
+// returned as is if none of its Bindings selects ClusterRoles. An empty
+// selector, which would bind every ClusterRole of the cluster, and entries
+// whose generateName is taken by another entry are refused.
+func (r *ScopeInstanceReconciler) withSelectedClusterRoles(ctx context.Context, st *operatorsv1.ScopeTemplate) (*operatorsv1.ScopeTemplate, error) {
+	if !selectsClusterRoles(st) {
+		return st, nil
+	}
+
+	expanded := st.DeepCopy()
+	expanded.Spec.Bindings = nil
+	for _, b := range st.Spec.Bindings {
+		if b.ClusterRoleSelector == nil {
+			expanded.Spec.Bindings = append(expanded.Spec.Bindings, b)
+			continue
+		}
+		if len(b.ClusterRoleSelector.MatchLabels) == 0 && len(b.ClusterRoleSelector.MatchExpressions) == 0 {
+			return nil, fmt.Errorf("%w: the clusterRoleSelector of binding %s is empty and would select every ClusterRole", errInvalidClusterRoleSelector, b.GenerateName)
+		}
+		selector, err := metav1.LabelSelectorAsSelector(b.ClusterRoleSelector)
+		if err != nil {
+			return nil, fmt.Errorf("%w: clusterRoleSelector of binding %s: %v", errInvalidClusterRoleSelector, b.GenerateName, err)
+		}
+		crList := &rbacv1.ClusterRoleList{}
+		if err := r.Client.List(ctx, crList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, err
+		}
+		sort.Slice(crList.Items, func(i, j int) bool { return crList.Items[i].GetName() < crList.Items[j].GetName() })
+		for _, cr := range crList.Items {
+			selected := *b.DeepCopy()
+			selected.GenerateName = b.GenerateName + "-" + cr.GetName()
+			selected.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: cr.GetName()}
+			selected.ClusterRoleSelector = nil
+			expanded.Spec.Bindings = append(expanded.Spec.Bindings, selected)
+		}
+	}
+
+	// The bindings of each entry are told apart by its generateName
+	generateNames := sets.NewString()
+	for _, bt := range expanded.Spec.BindingTemplates() {
+		if generateNames.Has(bt.GenerateName) {
+			return nil, fmt.Errorf("%w: several entries would have the generateName %s once the selected ClusterRoles are bound", errInvalidClusterRoleSelector, bt.GenerateName)
+		}
+		generateNames.Insert(bt.GenerateName)
+	}
+	return expanded, nil
+}
+
+// selectsClusterRoles returns whether any Binding of the ScopeTemplate
+// selects ClusterRoles by label.
+func selectsClusterRoles(st *operatorsv1.ScopeTemplate) bool {
+	for _, b := range st.Spec.Bindings {
+		if b.ClusterRoleSelector != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// withDefaultSubjectNamespace returns a copy of the given BindingTemplate
+// whose ServiceAccount subjects without a namespace are given the
+// DefaultSubjectNamespace of the ScopeInstance, or namespace, that of the
+// RoleBinding, for DefaultSubjectNamespaceBinding. The namespace is empty for
+// a ClusterRoleBinding.
+func withDefaultSubjectNamespace(cr operatorsv1.BindingTemplate, in *operatorsv1.ScopeInstance, namespace string) operatorsv1.BindingTemplate {
+	defaulted := in.Spec.DefaultSubjectNamespace
+	if defaulted == operatorsv1.DefaultSubjectNamespaceBinding {
+		defaulted = namespace
+	}
+	if defaulted == "" {
+		return cr
+	}
+
+	subjects := make([]rbacv1.Subject, 0, len(cr.Subjects))
+	for _, subject := range cr.Subjects {
+		if subject.Kind == rbacv1.ServiceAccountKind && subject.Namespace == "" {
+			subject.Namespace = defaulted
+		}
+		subjects = append(subjects, subject)
+	}
+	cr.Subjects = subjects
+	return cr
+}
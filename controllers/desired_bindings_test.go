@@ -0,0 +1,270 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+var _ = Describe("DesiredBindings", func() {
+	var (
+		r  *ScopeInstanceReconciler
+		st *operatorsv1.ScopeTemplate
+		si *operatorsv1.ScopeInstance
+	)
+	BeforeEach(func() {
+		r = &ScopeInstanceReconciler{
+			Client: newFakeClient(newTestServiceAccount("ns-1", "sa-1", map[string]string{"team": "a"})),
+			Scheme: scheme.Scheme,
+		}
+		st = newTestScopeTemplate("scopetemplate-desired")
+		si = &operatorsv1.ScopeInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-desired", UID: "scopeinstance-desired-uid"},
+			Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName()},
+		}
+	})
+
+	// kindAndNamespace describes each binding as its kind and namespace.
+	kindAndNamespace := func(bindings []client.Object) []string {
+		var described []string
+		for _, binding := range bindings {
+			described = append(described, bindingKind(binding)+" "+binding.GetNamespace())
+		}
+		return described
+	}
+
+	It("should bind a cluster scoped ScopeInstance with a ClusterRoleBinding", func() {
+		desired, err := r.desiredBindings(ctx, si, st, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(desired).To(HaveLen(1))
+
+		crb, ok := desired[0].(*rbacv1.ClusterRoleBinding)
+		Expect(ok).To(BeTrue())
+		Expect(crb.GenerateName).To(Equal("test-"))
+		Expect(crb.RoleRef.Name).To(Equal("test"))
+		Expect(crb.Subjects).To(Equal(st.Spec.ClusterRoles[0].Subjects))
+		Expect(crb.Labels).To(Equal(map[string]string{
+			scopeInstanceUIDKey:           string(si.GetUID()),
+			clusterRoleBindingGenerateKey: "test",
+		}))
+		Expect(crb.Annotations).To(HaveKeyWithValue(referenceHashKey, hashScopeInstanceAndTemplate(si, st)))
+		Expect(metav1.IsControlledBy(crb, si)).To(BeTrue())
+	})
+
+	It("should bind each selected namespace once with a RoleBinding", func() {
+		si.Spec.Namespaces = []string{"ns-2", "ns-1"}
+		desired, err := r.desiredBindings(ctx, si, st, []string{"ns-2", "ns-1", "ns-2"}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kindAndNamespace(desired)).To(Equal([]string{"RoleBinding ns-1", "RoleBinding ns-2"}))
+
+		By("binding the namespaces selected by annotations the same way")
+		si.Spec.Namespaces = nil
+		si.Spec.NamespaceAnnotationSelector = map[string]string{"team": "a"}
+		selected, err := r.desiredBindings(ctx, si, st, []string{"ns-1", "ns-2"}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kindAndNamespace(selected)).To(Equal([]string{"RoleBinding ns-1", "RoleBinding ns-2"}))
+
+		By("binding nothing if no namespace is selected")
+		none, err := r.desiredBindings(ctx, si, st, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(none).To(BeEmpty())
+	})
+
+	It("should follow the scope of each entry of the ScopeTemplate", func() {
+		clusterWide := st.Spec.ClusterRoles[0]
+		clusterWide.GenerateName = "cluster-view"
+		clusterWide.Scope = operatorsv1.ClusterRoleScopeCluster
+		st.Spec.ClusterRoles = append(st.Spec.ClusterRoles, clusterWide)
+		st.Spec.Bindings = []operatorsv1.BindingTemplate{{
+			GenerateName: "role",
+			RoleRef:      rbacv1.RoleRef{Kind: "Role", Name: "role", APIGroup: rbacv1.GroupName},
+			Subjects:     st.Spec.ClusterRoles[0].Subjects,
+		}}
+		si.Spec.Namespaces = []string{"ns-1"}
+
+		desired, err := r.desiredBindings(ctx, si, st, si.Spec.Namespaces, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kindAndNamespace(desired)).To(Equal([]string{"RoleBinding ns-1", "ClusterRoleBinding ", "RoleBinding ns-1"}))
+		Expect(desired[2].(*rbacv1.RoleBinding).RoleRef.Kind).To(Equal("Role"))
+
+		By("leaving out the Role once the ScopeInstance is cluster scoped")
+		si.Spec.Namespaces = nil
+		desired, err = r.desiredBindings(ctx, si, st, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kindAndNamespace(desired)).To(Equal([]string{"ClusterRoleBinding ", "ClusterRoleBinding "}))
+	})
+
+	It("should compute the subjects of each binding", func() {
+		st.Spec.ClusterRoles[0].NamespaceSubjects = map[string][]rbacv1.Subject{
+			"ns-1": {{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice"}},
+		}
+		st.Spec.ClusterRoles[0].SubjectExpression = `[{"kind": "User", "apiGroup": "rbac.authorization.k8s.io", "name": namespaceName + "-admin"}]`
+		st.Spec.ClusterRoles[0].ServiceAccountSelector = &operatorsv1.ServiceAccountSelector{
+			Selector: metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+		}
+		si.Spec.Namespaces = []string{"ns-1", "ns-2"}
+		si.Spec.GroupPrefix = "prefix:"
+
+		desired, err := r.desiredBindings(ctx, si, st, si.Spec.Namespaces, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(desired).To(HaveLen(2))
+		Expect(desired[0].(*rbacv1.RoleBinding).Subjects).To(ConsistOf(
+			rbacv1.Subject{Kind: "Group", APIGroup: rbacv1.GroupName, Name: "prefix:manager"},
+			rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice"},
+			rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "ns-1-admin"},
+			rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "ns-1", Name: "sa-1"},
+		))
+		Expect(desired[1].(*rbacv1.RoleBinding).Subjects).To(ConsistOf(
+			rbacv1.Subject{Kind: "Group", APIGroup: rbacv1.GroupName, Name: "prefix:manager"},
+			rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "ns-2-admin"},
+			rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "ns-1", Name: "sa-1"},
+		))
+
+		By("reporting an invalid expression")
+		st.Spec.ClusterRoles[0].SubjectExpression = `namespaceName`
+		_, err = r.desiredBindings(ctx, si, st, si.Spec.Namespaces, nil)
+		Expect(err).To(MatchError(errInvalidSubjectExpression))
+	})
+
+	It("should bind in the namespaces of the ServiceAccount subjects", func() {
+		st.Spec.ClusterRoles[0].Subjects = append(st.Spec.ClusterRoles[0].Subjects,
+			rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "ns-3", Name: "sa-1"})
+		si.Spec.BindInSubjectNamespaces = true
+
+		desired, err := r.desiredBindings(ctx, si, st, []string{"ns-1"}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kindAndNamespace(desired)).To(Equal([]string{"RoleBinding ns-1", "RoleBinding ns-3"}))
+
+		By("leaving out the excluded namespaces")
+		excluded, err := r.desiredBindings(ctx, si, st, []string{"ns-1"}, sets.NewString("ns-3"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kindAndNamespace(excluded)).To(Equal([]string{"RoleBinding ns-1"}))
+	})
+
+	It("should name the RoleBindings and shadow them", func() {
+		si.Spec.Namespaces = []string{"ns-1"}
+		si.Spec.BindingNameTemplate = "{{.ScopeInstance}}-{{.Namespace}}"
+		r.ShadowPrefix = "shadow-"
+
+		desired, err := r.desiredBindings(ctx, si, st, si.Spec.Namespaces, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(desired).To(HaveLen(1))
+		rb := desired[0].(*rbacv1.RoleBinding)
+		Expect(rb.Namespace).To(Equal("ns-1"))
+		Expect(rb.Name).To(Equal("shadow-scopeinstance-desired-ns-1"))
+		Expect(rb.GenerateName).To(BeEmpty())
+		Expect(rb.Labels).To(HaveKeyWithValue(scopeInstanceUIDKey, "shadow-"+string(si.GetUID())))
+		Expect(rb.Subjects).To(ConsistOf(rbacv1.Subject{Kind: "Group", APIGroup: rbacv1.GroupName, Name: "shadow-manager"}))
+
+		By("reporting two entries rendering the same name")
+		second := st.Spec.ClusterRoles[0]
+		second.GenerateName = "test-2"
+		st.Spec.ClusterRoles = append(st.Spec.ClusterRoles, second)
+		_, err = r.desiredBindings(ctx, si, st, si.Spec.Namespaces, nil)
+		Expect(err).To(MatchError(errBindingNameCollision))
+	})
+
+	When("ServiceAccount subjects lack a namespace", func() {
+		// serviceAccounts returns the ServiceAccount subjects of each binding
+		serviceAccounts := func(bindings []client.Object) []string {
+			var described []string
+			for _, binding := range bindings {
+				var subjects []rbacv1.Subject
+				switch b := binding.(type) {
+				case *rbacv1.ClusterRoleBinding:
+					subjects = b.Subjects
+				case *rbacv1.RoleBinding:
+					subjects = b.Subjects
+				}
+				for _, subject := range subjects {
+					if subject.Kind == rbacv1.ServiceAccountKind {
+						described = append(described, bindingKind(binding)+" "+binding.GetNamespace()+": "+subject.Namespace+"/"+subject.Name)
+					}
+				}
+			}
+			return described
+		}
+
+		BeforeEach(func() {
+			clusterWide := st.Spec.ClusterRoles[0]
+			clusterWide.GenerateName = "cluster-view"
+			clusterWide.Scope = operatorsv1.ClusterRoleScopeCluster
+			st.Spec.ClusterRoles = append(st.Spec.ClusterRoles, clusterWide)
+			for i := range st.Spec.ClusterRoles {
+				st.Spec.ClusterRoles[i].Subjects = []rbacv1.Subject{
+					{Kind: rbacv1.ServiceAccountKind, Name: "sa-1"},
+					{Kind: rbacv1.ServiceAccountKind, Namespace: "ns-3", Name: "sa-2"},
+				}
+			}
+			si.Spec.Namespaces = []string{"ns-1", "ns-2"}
+		})
+
+		It("should leave them without a namespace by default", func() {
+			desired, err := r.desiredBindings(ctx, si, st, si.Spec.Namespaces, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(serviceAccounts(desired)).To(ContainElements(
+				"RoleBinding ns-1: /sa-1",
+				"RoleBinding ns-2: /sa-1",
+				"ClusterRoleBinding : /sa-1",
+			))
+		})
+
+		It("should default them to the namespace of each RoleBinding", func() {
+			si.Spec.DefaultSubjectNamespace = operatorsv1.DefaultSubjectNamespaceBinding
+			desired, err := r.desiredBindings(ctx, si, st, si.Spec.Namespaces, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(serviceAccounts(desired)).To(Equal([]string{
+				"RoleBinding ns-1: ns-1/sa-1",
+				"RoleBinding ns-1: ns-3/sa-2",
+				"RoleBinding ns-2: ns-2/sa-1",
+				"RoleBinding ns-2: ns-3/sa-2",
+				"ClusterRoleBinding : /sa-1",
+				"ClusterRoleBinding : ns-3/sa-2",
+			}))
+		})
+
+		It("should default them to the given namespace in every binding", func() {
+			si.Spec.DefaultSubjectNamespace = "ns-4"
+			desired, err := r.desiredBindings(ctx, si, st, si.Spec.Namespaces, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(serviceAccounts(desired)).To(Equal([]string{
+				"RoleBinding ns-1: ns-4/sa-1",
+				"RoleBinding ns-1: ns-3/sa-2",
+				"RoleBinding ns-2: ns-4/sa-1",
+				"RoleBinding ns-2: ns-3/sa-2",
+				"ClusterRoleBinding : ns-4/sa-1",
+				"ClusterRoleBinding : ns-3/sa-2",
+			}))
+
+			By("binding in the given namespace along with the other subject namespaces")
+			si.Spec.BindInSubjectNamespaces = true
+			desired, err = r.desiredBindings(ctx, si, st, si.Spec.Namespaces, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kindAndNamespace(desired)).To(Equal([]string{
+				"RoleBinding ns-1", "RoleBinding ns-2", "RoleBinding ns-3", "RoleBinding ns-4", "ClusterRoleBinding ",
+			}))
+		})
+	})
+})
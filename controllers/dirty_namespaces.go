@@ -0,0 +1,117 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// dirtyNamespaces records why each ScopeInstance was enqueued, so that a
+// ScopeInstance enqueued only by namespace events reconciles the RoleBindings
+// of those namespaces alone. Any other event, a requeue or a failed reconcile
+// leads to a full reconcile. A nil dirtyNamespaces is valid and always leads
+// to a full reconcile.
+type dirtyNamespaces struct {
+	mu sync.Mutex
+	// pending holds the changed namespaces of each enqueued ScopeInstance, or
+	// nil once any other event enqueued it.
+	pending map[string]sets.String
+}
+
+func newDirtyNamespaces() *dirtyNamespaces {
+	return &dirtyNamespaces{pending: map[string]sets.String{}}
+}
+
+// markNamespace records that an event for the namespace enqueued the named
+// ScopeInstance.
+func (d *dirtyNamespaces) markNamespace(name, namespace string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	changed, ok := d.pending[name]
+	if ok && changed == nil {
+		return
+	}
+	if !ok {
+		changed = sets.NewString()
+		d.pending[name] = changed
+	}
+	changed.Insert(namespace)
+}
+
+// markFull records that the named ScopeInstance needs a full reconcile.
+func (d *dirtyNamespaces) markFull(name string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pending[name] = nil
+}
+
+// take returns the namespaces whose events enqueued the named ScopeInstance,
+// or nil if it needs a full reconcile, and forgets them.
+func (d *dirtyNamespaces) take(name string) sets.String {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	changed := d.pending[name]
+	delete(d.pending, name)
+	return changed
+}
+
+// handler wraps h so that every request it enqueues needs a full reconcile.
+func (d *dirtyNamespaces) handler(h handler.EventHandler) handler.EventHandler {
+	return handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			h.Create(e, &fullReconcileQueue{RateLimitingInterface: q, dirty: d})
+		},
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			h.Update(e, &fullReconcileQueue{RateLimitingInterface: q, dirty: d})
+		},
+		DeleteFunc: func(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			h.Delete(e, &fullReconcileQueue{RateLimitingInterface: q, dirty: d})
+		},
+		GenericFunc: func(e event.GenericEvent, q workqueue.RateLimitingInterface) {
+			h.Generic(e, &fullReconcileQueue{RateLimitingInterface: q, dirty: d})
+		},
+	}
+}
+
+// fullReconcileQueue marks the requests added by an event handler as needing
+// a full reconcile.
+type fullReconcileQueue struct {
+	workqueue.RateLimitingInterface
+	dirty *dirtyNamespaces
+}
+
+func (q *fullReconcileQueue) Add(item interface{}) {
+	if req, ok := item.(reconcile.Request); ok {
+		q.dirty.markFull(req.Name)
+	}
+	q.RateLimitingInterface.Add(item)
+}
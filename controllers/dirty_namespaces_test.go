@@ -0,0 +1,184 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+var _ = Describe("dirtyNamespaces", func() {
+	It("should only reconcile the RoleBindings of the changed namespaces", func() {
+		st := newTestScopeTemplate("scopetemplate-dirty")
+		si := &operatorsv1.ScopeInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-dirty", UID: "scopeinstance-dirty-uid"},
+			Spec: operatorsv1.ScopeInstanceSpec{
+				ScopeTemplateName:           st.GetName(),
+				NamespaceAnnotationSelector: map[string]string{"team": "a"},
+			},
+		}
+		c := &roleBindingNamespaceRecorder{Client: newFakeClient(si, st, newTestClusterRole("test"),
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-1", Annotations: map[string]string{"team": "a"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-2", Annotations: map[string]string{"team": "a"}}},
+		)}
+		r := &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme, dirty: newDirtyNamespaces()}
+		req := ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}}
+
+		By("reconciling every namespace the first time")
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.take()).To(Equal(sets.NewString("ns-1", "ns-2")))
+		Expect(listFakeRoleBindings(c, "ns-2", si)).To(HaveLen(1))
+
+		By("deselecting a namespace")
+		ns := &corev1.Namespace{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "ns-2"}, ns)).To(Succeed())
+		ns.Annotations = nil
+		Expect(c.Update(ctx, ns)).To(Succeed())
+		c.take()
+		Expect(r.mapNamespaceToScopeInstance(ns)).To(ConsistOf(req))
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.take()).To(Equal(sets.NewString("ns-2")))
+		Expect(listFakeRoleBindings(c, "ns-1", si)).To(HaveLen(1))
+		Expect(listFakeRoleBindings(c, "ns-2", si)).To(BeEmpty())
+
+		By("selecting the namespace again")
+		Expect(c.Get(ctx, client.ObjectKey{Name: "ns-2"}, ns)).To(Succeed())
+		ns.Annotations = map[string]string{"team": "a"}
+		Expect(c.Update(ctx, ns)).To(Succeed())
+		c.take()
+		r.mapNamespaceToScopeInstance(ns)
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.take()).To(Equal(sets.NewString("ns-2")))
+		Expect(listFakeRoleBindings(c, "ns-2", si)).To(HaveLen(1))
+
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+		Expect(si.Status.Namespaces).To(Equal([]string{"ns-1", "ns-2"}))
+
+		By("leaving the other namespaces alone")
+		Expect(c.Delete(ctx, &listFakeRoleBindings(c, "ns-1", si)[0])).To(Succeed())
+		c.take()
+		r.dirty.markNamespace(si.GetName(), "ns-2")
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.take().Has("ns-1")).To(BeFalse())
+		Expect(listFakeRoleBindings(c, "ns-1", si)).To(BeEmpty())
+
+		By("reconciling every namespace once any other event enqueues the ScopeInstance")
+		r.dirty.markNamespace(si.GetName(), "ns-2")
+		r.dirty.markFull(si.GetName())
+		_, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.take().Has("ns-1")).To(BeTrue())
+		Expect(listFakeRoleBindings(c, "ns-1", si)).To(HaveLen(1))
+	})
+
+	It("should mark the requests of wrapped handlers as needing a full reconcile", func() {
+		d := newDirtyNamespaces()
+		d.markNamespace("scopeinstance-dirty", "ns-1")
+		d.markNamespace("scopeinstance-dirty", "ns-2")
+		Expect(d.take("scopeinstance-dirty")).To(Equal(sets.NewString("ns-1", "ns-2")))
+		Expect(d.take("scopeinstance-dirty")).To(BeNil())
+
+		q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer q.ShutDown()
+		d.markNamespace("scopeinstance-dirty", "ns-1")
+		d.handler(&handler.EnqueueRequestForObject{}).Create(event.CreateEvent{
+			Object: &operatorsv1.ScopeInstance{ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-dirty"}},
+		}, q)
+		Expect(q.Len()).To(Equal(1))
+		d.markNamespace("scopeinstance-dirty", "ns-2")
+		Expect(d.take("scopeinstance-dirty")).To(BeNil())
+
+		var nilDirty *dirtyNamespaces
+		nilDirty.markNamespace("scopeinstance-dirty", "ns-1")
+		Expect(nilDirty.take("scopeinstance-dirty")).To(BeNil())
+	})
+})
+
+// roleBindingNamespaceRecorder records the namespaces of the RoleBindings
+// read or written through it.
+type roleBindingNamespaceRecorder struct {
+	client.Client
+	mu         sync.Mutex
+	namespaces sets.String
+}
+
+func (c *roleBindingNamespaceRecorder) record(obj client.Object) {
+	if _, ok := obj.(*rbacv1.RoleBinding); !ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.namespaces == nil {
+		c.namespaces = sets.NewString()
+	}
+	c.namespaces.Insert(obj.GetNamespace())
+}
+
+// take returns the recorded namespaces and forgets them.
+func (c *roleBindingNamespaceRecorder) take() sets.String {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	namespaces := c.namespaces
+	c.namespaces = nil
+	return namespaces
+}
+
+func (c *roleBindingNamespaceRecorder) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	obj.SetNamespace(key.Namespace)
+	c.record(obj)
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c *roleBindingNamespaceRecorder) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.record(obj)
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *roleBindingNamespaceRecorder) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.record(obj)
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *roleBindingNamespaceRecorder) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.record(obj)
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *roleBindingNamespaceRecorder) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.record(obj)
+	return c.Client.Delete(ctx, obj, opts...)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+// isDryRun returns whether the ScopeInstance is annotated for a dry run.
+func isDryRun(in *operatorsv1.ScopeInstance) bool {
+	return in.GetAnnotations()[dryRunKey] == "true"
+}
+
+// dryRunBindings asks the API server, with a server-side dry run, how it
+// would store each of the bindings that should exist for the ScopeInstance,
+// and records in its status how they would differ from the desired ones.
+// Unlike comparing the bindings locally, this accounts for the admission
+// webhooks of the cluster. Nothing is written or deleted.
+func (r *ScopeInstanceReconciler) dryRunBindings(ctx context.Context, in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate, namespaces []string, excluded sets.String) error {
+	desired, err := r.desiredBindings(ctx, in, st, namespaces, excluded)
+	if err != nil {
+		return err
+	}
+
+	var discrepancies []string
+	for _, binding := range desired {
+		stored := binding.DeepCopyObject().(client.Object)
+		if err := r.dryRunWrite(ctx, stored); err != nil {
+			return err
+		}
+		discrepancies = append(discrepancies, bindingDiscrepancies(r.keys, binding, stored)...)
+	}
+	in.Status.DryRunDiscrepancies = discrepancies
+	updateStatusDryRun(in, len(desired), len(discrepancies))
+	return nil
+}
+
+// dryRunWrite writes the binding with a server-side dry run, leaving in it
+// what the API server would store. A binding with a name may already exist,
+// so it is applied rather than created.
+func (r *ScopeInstanceReconciler) dryRunWrite(ctx context.Context, binding client.Object) error {
+	if binding.GetName() == "" {
+		return r.Client.Create(ctx, binding, client.DryRunAll, r.fieldOwner())
+	}
+	binding.GetObjectKind().SetGroupVersionKind(rbacv1.SchemeGroupVersion.WithKind(bindingKind(binding)))
+	return r.Client.Patch(ctx, binding, client.Apply, client.DryRunAll, r.fieldOwner(), client.ForceOwnership)
+}
+
+// bindingDiscrepancies describes how the binding stored by the API server
+// would differ from the desired one in its role, subjects, labels and
+// annotations.
+func bindingDiscrepancies(keys *keyMapping, desired, stored client.Object) []string {
+	var discrepancies []string
+	describe := func(format string, args ...interface{}) {
+		discrepancies = append(discrepancies, describeBinding(keys, desired)+": "+fmt.Sprintf(format, args...))
+	}
+
+	if want, got := bindingRoleRef(desired), bindingRoleRef(stored); want != got {
+		describe("roleRef %s %s would be %s %s", want.Kind, want.Name, got.Kind, got.Name)
+	}
+	if want, got := bindingSubjects(desired), bindingSubjects(stored); !equalSubjects(want, got) {
+		describe("%d subjects would be %d, %v", len(want), len(got), got)
+	}
+	for _, key := range changedKeys(desired.GetLabels(), stored.GetLabels()) {
+		describe("label %s would be %q", key, stored.GetLabels()[key])
+	}
+	for _, key := range changedKeys(desired.GetAnnotations(), stored.GetAnnotations()) {
+		describe("annotation %s would be %q", key, stored.GetAnnotations()[key])
+	}
+	return discrepancies
+}
+
+// changedKeys returns the sorted keys whose values differ between the two
+// maps, including the keys only one of them has.
+func changedKeys(want, got map[string]string) []string {
+	changed := sets.NewString()
+	for key, value := range want {
+		if stored, ok := got[key]; !ok || stored != value {
+			changed.Insert(key)
+		}
+	}
+	for key := range got {
+		if _, ok := want[key]; !ok {
+			changed.Insert(key)
+		}
+	}
+	return changed.List()
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+// ExportManagedRBAC writes every (Cluster)RoleBinding managed by the
+// operator to w as a YAML stream. The bindings are grouped by the
+// ScopeInstance that owns them, each group starting with a comment naming
+// the ScopeInstance. The bindings are found by their labels under the
+// prefix, which must pass ValidateKeyPrefix.
+func ExportManagedRBAC(ctx context.Context, c client.Client, prefix string, w io.Writer) error {
+	keys, err := newKeyMapping(prefix)
+	if err != nil {
+		return err
+	}
+
+	scopeInstanceList := &operatorsv1.ScopeInstanceList{}
+	if err := c.List(ctx, scopeInstanceList); err != nil {
+		return err
+	}
+	sort.Slice(scopeInstanceList.Items, func(i, j int) bool {
+		return scopeInstanceList.Items[i].GetName() < scopeInstanceList.Items[j].GetName()
+	})
+
+	for _, in := range scopeInstanceList.Items {
+		listOption := client.MatchingLabels{
+			keys.key(scopeInstanceUIDKey): string(in.GetUID()),
+		}
+
+		crbList := &rbacv1.ClusterRoleBindingList{}
+		if err := c.List(ctx, crbList, listOption); err != nil {
+			return err
+		}
+		rbList := &rbacv1.RoleBindingList{}
+		if err := c.List(ctx, rbList, listOption); err != nil {
+			return err
+		}
+
+		var bindings []client.Object
+		for i := range crbList.Items {
+			crb := &crbList.Items[i]
+			crb.SetGroupVersionKind(rbacv1.SchemeGroupVersion.WithKind("ClusterRoleBinding"))
+			bindings = append(bindings, crb)
+		}
+		for i := range rbList.Items {
+			rb := &rbList.Items[i]
+			rb.SetGroupVersionKind(rbacv1.SchemeGroupVersion.WithKind("RoleBinding"))
+			bindings = append(bindings, rb)
+		}
+		sortBindings(bindings)
+
+		if len(bindings) == 0 {
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "# ScopeInstance: %s\n", in.GetName()); err != nil {
+			return err
+		}
+		for _, binding := range bindings {
+			b, err := yaml.Marshal(binding)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "---\n%s", b); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// sortBindings orders ClusterRoleBindings before RoleBindings, then by
+// namespace and name, so that exports are stable.
+func sortBindings(bindings []client.Object) {
+	sort.SliceStable(bindings, func(i, j int) bool {
+		a, b := bindings[i], bindings[j]
+		if a.GetNamespace() != b.GetNamespace() {
+			return a.GetNamespace() < b.GetNamespace()
+		}
+		return a.GetName() < b.GetName()
+	})
+}
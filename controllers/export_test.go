@@ -0,0 +1,118 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/yaml"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+var _ = Describe("ExportManagedRBAC", func() {
+	var (
+		r               *ScopeInstanceReconciler
+		clusterScoped   *operatorsv1.ScopeInstance
+		namespaceScoped *operatorsv1.ScopeInstance
+	)
+	BeforeEach(func() {
+		st := newTestScopeTemplate("scopetemplate-export")
+		clusterScoped = &operatorsv1.ScopeInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "scopeinstance-export-a",
+				UID:  "scopeinstance-export-a-uid",
+			},
+			Spec: operatorsv1.ScopeInstanceSpec{
+				ScopeTemplateName: st.GetName(),
+			},
+		}
+		namespaceScoped = &operatorsv1.ScopeInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "scopeinstance-export-b",
+				UID:  "scopeinstance-export-b-uid",
+			},
+			Spec: operatorsv1.ScopeInstanceSpec{
+				ScopeTemplateName: st.GetName(),
+				Namespaces:        []string{"ns-1", "ns-2"},
+			},
+		}
+		r = &ScopeInstanceReconciler{
+			Client: newFakeClient(clusterScoped, namespaceScoped, st, newTestClusterRole("test")),
+			Scheme: scheme.Scheme,
+		}
+
+		for _, in := range []*operatorsv1.ScopeInstance{clusterScoped, namespaceScoped} {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: in.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		// Bindings that are not managed by the operator should not be exported
+		Expect(r.Client.Create(ctx, &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "unmanaged", Namespace: "ns-1"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "test", APIGroup: rbacv1.GroupName},
+		})).To(Succeed())
+	})
+
+	It("should export the bindings of every ScopeInstance", func() {
+		out := &bytes.Buffer{}
+		Expect(ExportManagedRBAC(ctx, r.Client, DefaultKeyPrefix, out)).To(Succeed())
+
+		groups := strings.Split(out.String(), "# ScopeInstance: ")
+		Expect(groups).To(HaveLen(3))
+		Expect(groups[0]).To(BeEmpty())
+
+		By("grouping the ClusterRoleBinding under the cluster scoped ScopeInstance")
+		objs := decodeExportGroup(groups[1], clusterScoped.GetName())
+		Expect(objs).To(HaveLen(1))
+		Expect(objs[0].GetKind()).To(Equal("ClusterRoleBinding"))
+		Expect(objs[0].GetAPIVersion()).To(Equal(rbacv1.SchemeGroupVersion.String()))
+		Expect(objs[0].GetLabels()).To(HaveKeyWithValue(scopeInstanceUIDKey, string(clusterScoped.GetUID())))
+
+		By("grouping the RoleBindings under the namespace scoped ScopeInstance")
+		objs = decodeExportGroup(groups[2], namespaceScoped.GetName())
+		Expect(objs).To(HaveLen(2))
+		for i, ns := range []string{"ns-1", "ns-2"} {
+			Expect(objs[i].GetKind()).To(Equal("RoleBinding"))
+			Expect(objs[i].GetNamespace()).To(Equal(ns))
+			Expect(objs[i].GetLabels()).To(HaveKeyWithValue(scopeInstanceUIDKey, string(namespaceScoped.GetUID())))
+		}
+	})
+})
+
+// decodeExportGroup decodes the objects exported for a single ScopeInstance.
+func decodeExportGroup(group, name string) []*unstructured.Unstructured {
+	docs := strings.Split(group, "---\n")
+	Expect(strings.TrimSpace(docs[0])).To(Equal(name))
+
+	var objs []*unstructured.Unstructured
+	for _, doc := range docs[1:] {
+		obj := &unstructured.Unstructured{}
+		Expect(yaml.Unmarshal([]byte(doc), &obj.Object)).To(Succeed())
+		objs = append(objs, obj)
+	}
+	return objs
+}
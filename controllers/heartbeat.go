@@ -0,0 +1,170 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// DefaultHeartbeatInterval is how often the heartbeat Lease is renewed if
+// Heartbeat.Interval is not set.
+const DefaultHeartbeatInterval = 10 * time.Second
+
+//+kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;create;update
+
+// Heartbeat maintains a Lease renewed as the ScopeInstance reconciler makes
+// progress, so that external systems can detect a stalled controller from
+// its renewTime without relying on leader election. The Lease is renewed
+// after every reconcile, at most once per Interval, and every Interval while
+// the reconciler is idle. It is not renewed while a reconcile is running, so
+// a reconcile that never returns lets the Lease expire. A nil Heartbeat is
+// valid and does nothing.
+type Heartbeat struct {
+	// Client writes the Lease. Reader reads it, defaulting to Client, so
+	// that the Lease need not be cached.
+	Client client.Client
+	Reader client.Reader
+
+	// Lease is the namespace and name of the Lease.
+	Lease types.NamespacedName
+
+	// Identity is the holderIdentity written to the Lease, such as the name
+	// of the pod.
+	Identity string
+
+	// Interval defaults to DefaultHeartbeatInterval. The Lease advertises a
+	// duration of twice the Interval.
+	Interval time.Duration
+
+	mu        sync.Mutex
+	inFlight  int
+	renewedAt time.Time
+}
+
+var _ manager.LeaderElectionRunnable = &Heartbeat{}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, so that the
+// Lease is only renewed by the replica running the reconcilers.
+func (h *Heartbeat) NeedLeaderElection() bool {
+	return true
+}
+
+// Start renews the Lease every Interval while no reconcile is running, until
+// ctx is done.
+func (h *Heartbeat) Start(ctx context.Context) error {
+	ticker := time.NewTicker(h.interval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			h.mu.Lock()
+			idle := h.inFlight == 0
+			h.mu.Unlock()
+			if idle {
+				h.renew(ctx)
+			}
+		}
+	}
+}
+
+// reconciling records that a reconcile started. The returned func records
+// that it returned and renews the Lease if it was not renewed in the last
+// Interval.
+func (h *Heartbeat) reconciling() func(context.Context) {
+	if h == nil {
+		return func(context.Context) {}
+	}
+	h.mu.Lock()
+	h.inFlight++
+	h.mu.Unlock()
+
+	return func(ctx context.Context) {
+		h.mu.Lock()
+		h.inFlight--
+		due := time.Since(h.renewedAt) >= h.interval()
+		h.mu.Unlock()
+		if due {
+			h.renew(ctx)
+		}
+	}
+}
+
+func (h *Heartbeat) interval() time.Duration {
+	if h.Interval <= 0 {
+		return DefaultHeartbeatInterval
+	}
+	return h.Interval
+}
+
+// renew creates or renews the Lease. Failures are logged, a missed renewal
+// is the signal of the Lease.
+func (h *Heartbeat) renew(ctx context.Context) {
+	if err := h.write(ctx); err != nil {
+		log.Log.Error(err, "renewing the heartbeat Lease", "lease", h.Lease.String())
+		return
+	}
+	h.mu.Lock()
+	h.renewedAt = time.Now()
+	h.mu.Unlock()
+}
+
+func (h *Heartbeat) write(ctx context.Context) error {
+	reader := h.Reader
+	if reader == nil {
+		reader = h.Client
+	}
+
+	now := metav1.NewMicroTime(time.Now())
+	durationSeconds := int32(2 * h.interval() / time.Second)
+	lease := &coordinationv1.Lease{}
+	err := reader.Get(ctx, h.Lease, lease)
+	if k8sapierrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Namespace: h.Lease.Namespace, Name: h.Lease.Name},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &h.Identity,
+				LeaseDurationSeconds: &durationSeconds,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		return h.Client.Create(ctx, lease)
+	}
+	if err != nil {
+		return err
+	}
+
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != h.Identity {
+		lease.Spec.HolderIdentity = &h.Identity
+		lease.Spec.AcquireTime = &now
+	}
+	lease.Spec.LeaseDurationSeconds = &durationSeconds
+	lease.Spec.RenewTime = &now
+	return h.Client.Update(ctx, lease)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+var _ = Describe("Heartbeat", func() {
+	var (
+		h   *Heartbeat
+		key types.NamespacedName
+	)
+	BeforeEach(func() {
+		key = types.NamespacedName{Namespace: "oria-system", Name: "oria-heartbeat"}
+		h = &Heartbeat{Client: newFakeClient(), Lease: key, Identity: "oria-operator-0", Interval: time.Hour}
+	})
+
+	// lease returns the heartbeat Lease.
+	lease := func() *coordinationv1.Lease {
+		lease := &coordinationv1.Lease{}
+		ExpectWithOffset(1, h.Client.Get(ctx, key, lease)).To(Succeed())
+		return lease
+	}
+
+	It("should renew the Lease as ScopeInstances are reconciled", func() {
+		st := newTestScopeTemplate("scopetemplate-heartbeat")
+		si := &operatorsv1.ScopeInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-heartbeat", UID: "scopeinstance-heartbeat-uid"},
+			Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName(), Namespaces: []string{"ns-1"}},
+		}
+		h.Client = newFakeClient(si, st, newTestClusterRole("test"))
+		r := &ScopeInstanceReconciler{Client: h.Client, Scheme: scheme.Scheme, Heartbeat: h}
+		reconcile := func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		}
+
+		reconcile()
+		created := lease()
+		Expect(created.Spec.HolderIdentity).To(HaveValue(Equal("oria-operator-0")))
+		Expect(created.Spec.LeaseDurationSeconds).To(HaveValue(BeEquivalentTo(7200)))
+		Expect(created.Spec.RenewTime).NotTo(BeNil())
+
+		By("not renewing the Lease more than once per interval")
+		reconcile()
+		Expect(lease().ResourceVersion).To(Equal(created.ResourceVersion))
+
+		By("renewing the Lease once the interval passed")
+		h.renewedAt = time.Now().Add(-time.Hour)
+		reconcile()
+		renewed := lease()
+		Expect(renewed.ResourceVersion).NotTo(Equal(created.ResourceVersion))
+		Expect(renewed.Spec.AcquireTime).To(Equal(created.Spec.AcquireTime))
+	})
+
+	It("should renew the Lease while idle but not while a reconcile is running", func() {
+		h.Interval = 10 * time.Millisecond
+		heartbeatCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			Expect(h.Start(heartbeatCtx)).To(Succeed())
+		}()
+
+		resourceVersion := func() string {
+			lease := &coordinationv1.Lease{}
+			if err := h.Client.Get(ctx, key, lease); err != nil {
+				return ""
+			}
+			return lease.ResourceVersion
+		}
+		Eventually(resourceVersion).ShouldNot(BeEmpty())
+
+		By("letting the Lease expire while a reconcile does not return")
+		reconciled := h.reconciling()
+		// Let a renewal started before the reconcile finish
+		time.Sleep(2 * h.Interval)
+		stalled := resourceVersion()
+		Consistently(resourceVersion, 100*time.Millisecond).Should(Equal(stalled))
+
+		By("renewing the Lease again once it returns")
+		reconciled(ctx)
+		returned := resourceVersion()
+		Expect(returned).NotTo(Equal(stalled))
+		Eventually(resourceVersion).ShouldNot(Equal(returned))
+
+		cancel()
+		Eventually(done).Should(BeClosed())
+	})
+
+	It("should take over a Lease held by another identity", func() {
+		other := "oria-operator-1"
+		Expect(h.Client.Create(ctx, &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+			Spec:       coordinationv1.LeaseSpec{HolderIdentity: &other},
+		})).To(Succeed())
+
+		h.reconciling()(ctx)
+		Expect(lease().Spec.HolderIdentity).To(HaveValue(Equal("oria-operator-0")))
+		Expect(lease().Spec.AcquireTime).NotTo(BeNil())
+	})
+})
@@ -0,0 +1,211 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DefaultKeyPrefix is the prefix of the labels and annotations the
+// reconcilers keep their bookkeeping in when none is configured.
+const DefaultKeyPrefix = "operators.coreos.io/"
+
+// bookkeepingKeys are the keys moved under the prefix of a keyMapping. The
+// keys ending with a slash are themselves the prefix of a key per
+// ScopeInstance.
+var bookkeepingKeys = []string{
+	scopeInstanceUIDKey,
+	referenceHashKey,
+	scopeTemplateNameKey,
+	audienceKey,
+	provenanceScopeInstanceKey,
+	provenanceScopeTemplateKey,
+	clusterRoleBindingGenerateKey,
+	sharedClusterRoleBindingKey,
+	sharedOwnerKeyPrefix,
+	sharedSubjectsKeyPrefix,
+	sharedProvenanceKeyPrefix,
+	scopeTemplateUIDKey,
+	scopeTemplateHashKey,
+	rulesHashKey,
+	clusterRoleGenerateKey,
+}
+
+// keyMapping maps each bookkeeping key, declared under the DefaultKeyPrefix,
+// to the key under the prefix a reconciler is configured with. A nil
+// keyMapping is valid and keeps every key under the DefaultKeyPrefix.
+type keyMapping struct {
+	prefixed map[string]string
+}
+
+// newKeyMapping returns the keyMapping of the prefix, a DNS subdomain
+// optionally followed by a slash, or nil for the DefaultKeyPrefix.
+func newKeyMapping(prefix string) (*keyMapping, error) {
+	if prefix == "" {
+		return nil, nil
+	}
+	domain := strings.TrimSuffix(prefix, "/")
+	if errs := validation.IsDNS1123Subdomain(domain); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid key prefix %q: %s", prefix, strings.Join(errs, ", "))
+	}
+
+	defaultDomain := strings.TrimSuffix(DefaultKeyPrefix, "/")
+	if domain == defaultDomain {
+		return nil, nil
+	}
+	m := &keyMapping{prefixed: make(map[string]string, len(bookkeepingKeys))}
+	for _, key := range bookkeepingKeys {
+		m.prefixed[key] = strings.Replace(key, defaultDomain, domain, 1)
+	}
+	return m, nil
+}
+
+// ValidateKeyPrefix returns an error if the prefix is not a DNS subdomain
+// optionally followed by a slash.
+func ValidateKeyPrefix(prefix string) error {
+	_, err := newKeyMapping(prefix)
+	return err
+}
+
+// key returns the given bookkeeping key under the prefix.
+func (m *keyMapping) key(key string) string {
+	if m == nil {
+		return key
+	}
+	if prefixed, ok := m.prefixed[key]; ok {
+		return prefixed
+	}
+	return key
+}
+
+// migratedKey returns the key under the prefix of the given key, and whether
+// it is a bookkeeping key under the DefaultKeyPrefix.
+func (m *keyMapping) migratedKey(key string) (string, bool) {
+	if m == nil {
+		return key, false
+	}
+	if prefixed, ok := m.prefixed[key]; ok {
+		return prefixed, true
+	}
+	for legacy, prefixed := range m.prefixed {
+		if strings.HasSuffix(legacy, "/") && strings.HasPrefix(key, legacy) {
+			return prefixed + strings.TrimPrefix(key, legacy), true
+		}
+	}
+	return key, false
+}
+
+// migratedKeys returns the given labels or annotations with their bookkeeping
+// keys moved under the prefix, and whether any was moved. A key already
+// present under the prefix is kept over the legacy one.
+func (m *keyMapping) migratedKeys(keys map[string]string) (map[string]string, bool) {
+	migrated := make(map[string]string, len(keys))
+	legacy := map[string]string{}
+	for key, value := range keys {
+		if prefixed, ok := m.migratedKey(key); ok {
+			legacy[prefixed] = value
+			continue
+		}
+		migrated[key] = value
+	}
+	if len(legacy) == 0 {
+		return keys, false
+	}
+	for key, value := range legacy {
+		if _, ok := migrated[key]; !ok {
+			migrated[key] = value
+		}
+	}
+	return migrated, true
+}
+
+// migrateKeys moves the bookkeeping keys of the labels and annotations of the
+// object under the prefix with a merge patch, which removes the legacy keys,
+// and returns whether the object was patched.
+func (m *keyMapping) migrateKeys(ctx context.Context, c client.Client, obj client.Object) (bool, error) {
+	keys, relabeled := m.migratedKeys(obj.GetLabels())
+	annotations, reannotated := m.migratedKeys(obj.GetAnnotations())
+	if !relabeled && !reannotated {
+		return false, nil
+	}
+
+	original := obj.DeepCopyObject().(client.Object)
+	obj.SetLabels(keys)
+	obj.SetAnnotations(annotations)
+	if err := c.Patch(ctx, obj, client.MergeFrom(original)); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return true, nil
+}
+
+// MigrateKeys moves the bookkeeping keys of the (Cluster)RoleBindings and
+// ClusterRoles made under the DefaultKeyPrefix under the given prefix. The
+// reconcilers only select the keys under their prefix, so it is run once
+// before they start, and does nothing for the DefaultKeyPrefix. The labels of
+// the ScopeInstances are migrated by their reconciles.
+func MigrateKeys(ctx context.Context, c client.Client, prefix string) error {
+	m, err := newKeyMapping(prefix)
+	if err != nil || m == nil {
+		return err
+	}
+
+	var objs []client.Object
+	for _, key := range []string{scopeInstanceUIDKey, sharedClusterRoleBindingKey} {
+		crbList := &rbacv1.ClusterRoleBindingList{}
+		if err := c.List(ctx, crbList, client.HasLabels{key}); err != nil {
+			return err
+		}
+		for i := range crbList.Items {
+			objs = append(objs, &crbList.Items[i])
+		}
+	}
+	rbList := &rbacv1.RoleBindingList{}
+	if err := c.List(ctx, rbList, client.HasLabels{scopeInstanceUIDKey}); err != nil {
+		return err
+	}
+	for i := range rbList.Items {
+		objs = append(objs, &rbList.Items[i])
+	}
+	crList := &rbacv1.ClusterRoleList{}
+	if err := c.List(ctx, crList, client.HasLabels{scopeTemplateUIDKey}); err != nil {
+		return err
+	}
+	for i := range crList.Items {
+		objs = append(objs, &crList.Items[i])
+	}
+
+	migrated := 0
+	for _, obj := range objs {
+		patched, err := m.migrateKeys(ctx, c, obj)
+		if err != nil {
+			return fmt.Errorf("migrating the keys of %s: %w", client.ObjectKeyFromObject(obj), err)
+		}
+		if patched {
+			log.Log.V(1).Info("migrated the keys", "namespace", obj.GetNamespace(), "name", obj.GetName())
+			migrated++
+		}
+	}
+	log.Log.Info("migrated the bookkeeping keys under the prefix", "prefix", prefix, "count", migrated)
+	return nil
+}
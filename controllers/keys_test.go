@@ -0,0 +1,172 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+var _ = Describe("keyMapping", func() {
+	var (
+		c  client.Client
+		r  *ScopeInstanceReconciler
+		st *operatorsv1.ScopeTemplate
+		si *operatorsv1.ScopeInstance
+	)
+	BeforeEach(func() {
+		st = newTestScopeTemplate("scopetemplate-key-prefix")
+		si = &operatorsv1.ScopeInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-key-prefix", UID: "scopeinstance-key-prefix-uid"},
+			Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName(), Namespaces: []string{"ns-1"}},
+		}
+		c = newFakeClient(si, st, newTestClusterRole("test"))
+		r = &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme}
+	})
+
+	// exampleKeys returns the keyMapping of the example.com/ prefix
+	exampleKeys := func() *keyMapping {
+		keys, err := newKeyMapping("example.com/")
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return keys
+	}
+
+	reconcileScopeInstance := func() {
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	}
+
+	// listExampleRoleBindings lists the RoleBindings of the ScopeInstance
+	// labeled under the example.com/ prefix
+	listExampleRoleBindings := func() []rbacv1.RoleBinding {
+		rbList := &rbacv1.RoleBindingList{}
+		ExpectWithOffset(1, c.List(ctx, rbList, client.InNamespace("ns-1"), client.MatchingLabels{"example.com/scopeInstanceUID": string(si.GetUID())})).To(Succeed())
+		return rbList.Items
+	}
+
+	It("should reject a prefix that is not a DNS subdomain", func() {
+		Expect(ValidateKeyPrefix("Example_com/")).To(MatchError(ContainSubstring(`invalid key prefix "Example_com/"`)))
+		Expect(ValidateKeyPrefix("example.com")).To(Succeed())
+
+		keys, err := newKeyMapping(DefaultKeyPrefix)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys).To(BeNil())
+		Expect(keys.key(scopeInstanceUIDKey)).To(Equal(DefaultKeyPrefix + "scopeInstanceUID"))
+	})
+
+	It("should keep the bookkeeping of bindings under the prefix", func() {
+		r.keys = exampleKeys()
+		reconcileScopeInstance()
+
+		rbs := listExampleRoleBindings()
+		Expect(rbs).To(HaveLen(1))
+		Expect(rbs[0].Labels).To(HaveKeyWithValue("example.com/generateName", "test"))
+		Expect(rbs[0].Annotations).To(HaveKey("example.com/scopeInstanceHash"))
+		for key := range rbs[0].Labels {
+			Expect(key).NotTo(HavePrefix(DefaultKeyPrefix))
+		}
+		for key := range rbs[0].Annotations {
+			Expect(key).NotTo(HavePrefix(DefaultKeyPrefix))
+		}
+
+		// The bindings are still selected by the reconciler
+		expectIdempotentReconcile(r, si.GetName())
+		Expect(listExampleRoleBindings()).To(HaveLen(1))
+	})
+
+	It("should migrate the bindings made under the default prefix", func() {
+		reconcileScopeInstance()
+		legacy := listFakeRoleBindings(c, "ns-1", si)
+		Expect(legacy).To(HaveLen(1))
+
+		Expect(MigrateKeys(ctx, c, "example.com/")).To(Succeed())
+		Expect(listFakeRoleBindings(c, "ns-1", si)).To(BeEmpty())
+		migrated := listExampleRoleBindings()
+		Expect(migrated).To(HaveLen(1))
+		Expect(migrated[0].GetName()).To(Equal(legacy[0].GetName()))
+		Expect(migrated[0].Annotations).To(HaveKey("example.com/scopeInstanceHash"))
+		Expect(migrated[0].Annotations).NotTo(HaveKey(DefaultKeyPrefix + "scopeInstanceHash"))
+
+		r.keys = exampleKeys()
+		reconcileScopeInstance()
+		existing := &operatorsv1.ScopeInstance{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(si), existing)).To(Succeed())
+		Expect(existing.Labels).To(HaveKeyWithValue("example.com/scopeTemplate", st.GetName()))
+		Expect(existing.Labels).NotTo(HaveKey(DefaultKeyPrefix + "scopeTemplate"))
+
+		expectIdempotentReconcile(r, si.GetName())
+		rbs := listExampleRoleBindings()
+		Expect(rbs).To(HaveLen(1))
+		Expect(rbs[0].GetName()).To(Equal(legacy[0].GetName()))
+	})
+
+	It("should migrate the ClusterRoles made under the default prefix", func() {
+		// The ClusterRole is made by the ScopeTemplate controller this time
+		c = newFakeClient(si, st)
+		str := &ScopeTemplateReconciler{Client: c, Scheme: scheme.Scheme}
+		_, err := str.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+		Expect(err).NotTo(HaveOccurred())
+		legacy := &rbacv1.ClusterRoleList{}
+		Expect(c.List(ctx, legacy, client.MatchingLabels{scopeTemplateUIDKey: string(st.GetUID())})).To(Succeed())
+		Expect(legacy.Items).To(HaveLen(1))
+
+		Expect(MigrateKeys(ctx, c, "example.com")).To(Succeed())
+		str.keys = exampleKeys()
+		_, err = str.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+		Expect(err).NotTo(HaveOccurred())
+
+		migrated := &rbacv1.ClusterRoleList{}
+		Expect(c.List(ctx, migrated, client.MatchingLabels{"example.com/scopeTemplateUID": string(st.GetUID())})).To(Succeed())
+		Expect(migrated.Items).To(HaveLen(1))
+		Expect(migrated.Items[0].GetName()).To(Equal(legacy.Items[0].GetName()))
+		Expect(migrated.Items[0].Annotations).To(HaveKey("example.com/rulesHash"))
+		Expect(migrated.Items[0].Annotations).NotTo(HaveKey(DefaultKeyPrefix + "rulesHash"))
+	})
+
+	It("should not migrate anything for the default prefix", func() {
+		reconcileScopeInstance()
+		Expect(MigrateKeys(ctx, c, DefaultKeyPrefix)).To(Succeed())
+		Expect(listFakeRoleBindings(c, "ns-1", si)).To(HaveLen(1))
+	})
+
+	It("should migrate the per ScopeInstance keys of shared ClusterRoleBindings", func() {
+		shared := &rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      map[string]string{DefaultKeyPrefix + "shared": "true", "owner.operators.coreos.io/uid": "true", "app": "test"},
+				Annotations: map[string]string{"subjects.operators.coreos.io/uid": "[]"},
+			},
+		}
+		keys := exampleKeys()
+		relabeledKeys, relabeled := keys.migratedKeys(shared.Labels)
+		Expect(relabeled).To(BeTrue())
+		Expect(relabeledKeys).To(Equal(map[string]string{"example.com/shared": "true", "owner.example.com/uid": "true", "app": "test"}))
+		annotations, reannotated := keys.migratedKeys(shared.Annotations)
+		Expect(reannotated).To(BeTrue())
+		Expect(annotations).To(Equal(map[string]string{"subjects.example.com/uid": "[]"}))
+
+		_, relabeled = keys.migratedKeys(relabeledKeys)
+		Expect(relabeled).To(BeFalse())
+	})
+})
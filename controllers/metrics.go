@@ -0,0 +1,72 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// The operations and outcomes counted by bindingOperations.
+const (
+	opCreate = "create"
+	opUpdate = "update"
+	opDelete = "delete"
+
+	outcomeSuccess = "success"
+	outcomeError   = "error"
+)
+
+var (
+	// bindingsCreated counts the (Cluster)RoleBindings created by the ScopeInstance controller.
+	bindingsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "oria_bindings_created_total",
+		Help: "Number of (Cluster)RoleBindings created by the ScopeInstance controller.",
+	}, []string{"kind"})
+
+	// bindingsDeleted counts the (Cluster)RoleBindings deleted by the ScopeInstance controller.
+	bindingsDeleted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "oria_bindings_deleted_total",
+		Help: "Number of (Cluster)RoleBindings deleted by the ScopeInstance controller.",
+	}, []string{"kind"})
+
+	// bindingOperations counts the writes of (Cluster)RoleBindings by the ScopeInstance controller by outcome.
+	bindingOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "oria_binding_operations_total",
+		Help: "Number of creates, updates and deletes of (Cluster)RoleBindings by the ScopeInstance controller, by outcome.",
+	}, []string{"kind", "op", "outcome"})
+
+	// resolvedNamespaces is the number of namespaces each ScopeInstance resolved to on its last reconcile.
+	resolvedNamespaces = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "oria_resolved_namespaces",
+		Help: "Number of namespaces each ScopeInstance resolved to on its last reconcile.",
+	}, []string{"scopeinstance"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(bindingsCreated, bindingsDeleted, bindingOperations, resolvedNamespaces)
+}
+
+// countBindingOperation counts an operation on a binding of the given kind,
+// which failed if err is not nil.
+func countBindingOperation(kind, op string, err error) {
+	outcome := outcomeSuccess
+	if err != nil {
+		outcome = outcomeError
+	}
+	bindingOperations.WithLabelValues(kind, op, outcome).Inc()
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// namespaceRateLimiter throttles the creation of RoleBindings with a token
+// bucket per namespace, so that a burst of ScopeInstances binding in the same
+// namespace, such as while a cluster is bootstrapped, does not hot spot the
+// API server. Creates in other namespaces are not held back. A nil
+// namespaceRateLimiter is valid and throttles nothing.
+//
+// The bucket of a namespace is forgotten once it has been idle for long
+// enough to have refilled, as a new bucket is full too, so that the buckets
+// of namespaces no longer bound in do not pile up.
+type namespaceRateLimiter struct {
+	qps   float32
+	burst int
+
+	// now returns the current time, and is only replaced by tests
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*namespaceBucket
+	lastSweep time.Time
+}
+
+// namespaceBucket is the token bucket of a namespace, along with the number
+// of creates waiting on it and when the last of them was let through.
+type namespaceBucket struct {
+	limiter  flowcontrol.RateLimiter
+	waiting  int
+	lastUsed time.Time
+}
+
+func newNamespaceRateLimiter(qps float32, burst int) *namespaceRateLimiter {
+	if qps <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &namespaceRateLimiter{qps: qps, burst: burst, now: time.Now, buckets: map[string]*namespaceBucket{}}
+}
+
+// wait blocks until a create in the namespace is allowed, or the context is
+// done. Creates of cluster scoped objects are not throttled.
+func (l *namespaceRateLimiter) wait(ctx context.Context, namespace string) error {
+	if l == nil || namespace == "" {
+		return nil
+	}
+	bucket := l.acquire(namespace)
+	defer l.release(bucket)
+	return bucket.limiter.Wait(ctx)
+}
+
+// acquire returns the bucket of the namespace, creating it if needed, and
+// counts a create waiting on it. The idle buckets are forgotten first, at
+// most once per refill time.
+func (l *namespaceRateLimiter) acquire(namespace string) *namespaceBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if refill := l.refillTime(); now.Sub(l.lastSweep) >= refill {
+		for ns, bucket := range l.buckets {
+			if bucket.waiting == 0 && now.Sub(bucket.lastUsed) >= refill {
+				delete(l.buckets, ns)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	bucket, ok := l.buckets[namespace]
+	if !ok {
+		bucket = &namespaceBucket{limiter: flowcontrol.NewTokenBucketRateLimiter(l.qps, l.burst)}
+		l.buckets[namespace] = bucket
+	}
+	bucket.waiting++
+	return bucket
+}
+
+// release records that a create is no longer waiting on the bucket.
+func (l *namespaceRateLimiter) release(bucket *namespaceBucket) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	bucket.waiting--
+	bucket.lastUsed = l.now()
+}
+
+// refillTime is how long an emptied bucket takes to fill up again.
+func (l *namespaceRateLimiter) refillTime() time.Duration {
+	return time.Duration(float64(l.burst) / float64(l.qps) * float64(time.Second))
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+var _ = Describe("namespaceRateLimiter", func() {
+	// reconcileBurst reconciles a ScopeInstance binding in each of the given
+	// namespaces, returning how long it took and the reconciler.
+	reconcileBurst := func(limiter *namespaceRateLimiter, namespaces ...string) (time.Duration, *ScopeInstanceReconciler) {
+		st := newTestScopeTemplate("scopetemplate-rate-limit")
+		objs := []client.Object{st, newTestClusterRole("test")}
+		for i, ns := range namespaces {
+			objs = append(objs, &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("scopeinstance-rate-limit-%d", i), UID: types.UID(fmt.Sprintf("scopeinstance-rate-limit-%d-uid", i))},
+				Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName(), Namespaces: []string{ns}},
+			})
+		}
+		r := &ScopeInstanceReconciler{Client: newFakeClient(objs...), Scheme: scheme.Scheme, creates: limiter}
+
+		start := time.Now()
+		for i := range namespaces {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: fmt.Sprintf("scopeinstance-rate-limit-%d", i)}})
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		}
+		return time.Since(start), r
+	}
+
+	It("should throttle a burst of creates to one namespace", func() {
+		// Two creates are let through at once, the next four every 50ms
+		elapsed, r := reconcileBurst(newNamespaceRateLimiter(20, 2), "ns-1", "ns-1", "ns-1", "ns-1", "ns-1", "ns-1")
+		Expect(elapsed).To(BeNumerically(">=", 150*time.Millisecond))
+		for i := 0; i < 6; i++ {
+			si := &operatorsv1.ScopeInstance{ObjectMeta: metav1.ObjectMeta{UID: types.UID(fmt.Sprintf("scopeinstance-rate-limit-%d-uid", i))}}
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si)).To(HaveLen(1))
+		}
+	})
+
+	It("should not throttle creates to other namespaces", func() {
+		elapsed, _ := reconcileBurst(newNamespaceRateLimiter(20, 2), "ns-1", "ns-2", "ns-3", "ns-4", "ns-5", "ns-6")
+		Expect(elapsed).To(BeNumerically("<", 100*time.Millisecond))
+	})
+
+	It("should give up once the context is done", func() {
+		limiter := newNamespaceRateLimiter(0.001, 1)
+		Expect(limiter.wait(ctx, "ns-1")).To(Succeed())
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		Expect(limiter.wait(canceled, "ns-1")).NotTo(Succeed())
+		Expect(limiter.wait(canceled, "")).To(Succeed())
+	})
+
+	It("should forget the buckets of idle namespaces", func() {
+		// Each bucket refills within 100ms
+		limiter := newNamespaceRateLimiter(20, 2)
+		now := time.Now()
+		limiter.now = func() time.Time { return now }
+		for _, ns := range []string{"ns-1", "ns-2", "ns-3"} {
+			Expect(limiter.wait(ctx, ns)).To(Succeed())
+		}
+		Expect(limiter.buckets).To(HaveLen(3))
+
+		By("creating in one namespace before the others are idle")
+		now = now.Add(50 * time.Millisecond)
+		Expect(limiter.wait(ctx, "ns-1")).To(Succeed())
+		Expect(limiter.buckets).To(HaveLen(3))
+
+		By("creating in another namespace once they are")
+		now = now.Add(100 * time.Millisecond)
+		Expect(limiter.wait(ctx, "ns-4")).To(Succeed())
+		Expect(limiter.buckets).To(HaveLen(1))
+		Expect(limiter.buckets).To(HaveKey("ns-4"))
+	})
+
+	It("should not forget the bucket of a namespace creates wait on", func() {
+		limiter := newNamespaceRateLimiter(20, 1)
+		now := time.Now()
+		limiter.now = func() time.Time { return now }
+		bucket := limiter.acquire("ns-1")
+
+		now = now.Add(time.Second)
+		Expect(limiter.wait(ctx, "ns-2")).To(Succeed())
+		Expect(limiter.buckets).To(HaveKeyWithValue("ns-1", bucket))
+		limiter.release(bucket)
+	})
+
+	It("should not throttle anything when disabled", func() {
+		Expect(newNamespaceRateLimiter(0, 10)).To(BeNil())
+		var limiter *namespaceRateLimiter
+		Expect(limiter.wait(ctx, "ns-1")).To(Succeed())
+	})
+})
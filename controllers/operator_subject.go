@@ -0,0 +1,95 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+// OperatorSubjectPolicy decides what happens to bindings whose subjects
+// include the operator's own ServiceAccount.
+type OperatorSubjectPolicy string
+
+const (
+	// OperatorSubjectReject refuses to create or update the binding, leaving
+	// the ScopeInstance unscoped until the subject is removed.
+	OperatorSubjectReject OperatorSubjectPolicy = "Reject"
+
+	// OperatorSubjectWarn creates the binding, emitting a warning event for
+	// the ScopeInstance.
+	OperatorSubjectWarn OperatorSubjectPolicy = "Warn"
+)
+
+// errOperatorSubject is wrapped by the errors returned for bindings rejected
+// by the OperatorSubjectReject policy.
+var errOperatorSubject = errors.New("subject references the operator's own ServiceAccount")
+
+// checkOperatorSubjects applies the OperatorSubjectPolicy to the subjects of
+// a binding created for the ScopeInstance. Granting more power to the
+// operator through a ScopeInstance would let anyone allowed to create
+// ScopeInstances escalate through the operator.
+func (r *ScopeInstanceReconciler) checkOperatorSubjects(in *operatorsv1.ScopeInstance, subjects []rbacv1.Subject) error {
+	subject, ok := r.operatorSubject(subjects)
+	if !ok {
+		return nil
+	}
+
+	if r.OperatorSubjectPolicy == OperatorSubjectWarn {
+		log.Log.Info("warning: binding the operator's own ServiceAccount", "scopeInstance", in.GetName(), "kind", subject.Kind, "name", subject.Name)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(in, corev1.EventTypeWarning, operatorsv1.ReasonOperatorSubject,
+				"%s %s grants the permissions of the ScopeInstance to the operator's own ServiceAccount %s", subject.Kind, subject.Name, r.OperatorServiceAccount)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s %s matches %s", errOperatorSubject, subject.Kind, subject.Name, r.OperatorServiceAccount)
+}
+
+// operatorSubject returns the first of the subjects that includes the
+// OperatorServiceAccount, whether as a ServiceAccount, as its username or
+// through the groups every ServiceAccount belongs to.
+func (r *ScopeInstanceReconciler) operatorSubject(subjects []rbacv1.Subject) (rbacv1.Subject, bool) {
+	sa := r.OperatorServiceAccount
+	if sa.Name == "" {
+		return rbacv1.Subject{}, false
+	}
+
+	for _, subject := range subjects {
+		switch subject.Kind {
+		case rbacv1.ServiceAccountKind:
+			if subject.Namespace == sa.Namespace && subject.Name == sa.Name {
+				return subject, true
+			}
+		case rbacv1.UserKind:
+			if subject.Name == "system:serviceaccount:"+sa.Namespace+":"+sa.Name {
+				return subject, true
+			}
+		case rbacv1.GroupKind:
+			if subject.Name == "system:serviceaccounts" || subject.Name == "system:serviceaccounts:"+sa.Namespace {
+				return subject, true
+			}
+		}
+	}
+	return rbacv1.Subject{}, false
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+// uidPattern matches the UIDs the API server gives to objects.
+var uidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// OrphanSweep returns a runnable that deletes, once the caches are synced,
+// the bindings labeled for ScopeInstances that no longer exist, such as those
+// deleted while the operator was down or with an orphaning propagation
+// policy, which nothing reconciles anymore. reader lists the ScopeInstances
+// and should not be a cache, so that a ScopeInstance created since the
+// bindings were listed is not missed.
+func (r *ScopeInstanceReconciler) OrphanSweep(reader client.Reader) manager.Runnable {
+	return &orphanSweep{r: r, reader: reader, confirmationDelay: deletionConfirmationDelay}
+}
+
+type orphanSweep struct {
+	r      *ScopeInstanceReconciler
+	reader client.Reader
+
+	// confirmationDelay is how long to wait before sweeping again to confirm
+	// the deletions deferred by DeletionSafeMode.
+	confirmationDelay time.Duration
+}
+
+var _ manager.LeaderElectionRunnable = &orphanSweep{}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable, so that only
+// the replica running the reconcilers deletes bindings.
+func (s *orphanSweep) NeedLeaderElection() bool {
+	return true
+}
+
+// Start sweeps the orphaned bindings once, and again as long as DeletionSafeMode
+// defers deletions. Failures are logged rather than returned, so that they do
+// not stop the manager, and the bindings are swept again by the next start.
+func (s *orphanSweep) Start(ctx context.Context) error {
+	for {
+		deferred, err := s.r.sweepOrphanedBindings(ctx, s.reader)
+		if err != nil {
+			log.Log.Error(err, "in deleting orphaned (Cluster)RoleBindings")
+			return nil
+		}
+		if !deferred {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(s.confirmationDelay):
+		}
+	}
+}
+
+// sweepOrphanedBindings deletes the orphaned bindings of each ScopeInstance
+// UID like a reconcile of the ScopeInstance would. There is no ScopeInstance
+// to confirm a deletion exceeding MaxDeletesPerReconcile on, so those are
+// refused until the bindings are deleted by hand or the limit is raised.
+// DeletionSafeMode defers each deletion to the next sweep, and whether any
+// was deferred is returned.
+func (r *ScopeInstanceReconciler) sweepOrphanedBindings(ctx context.Context, reader client.Reader) (bool, error) {
+	orphans, err := r.orphanedBindings(ctx, reader)
+	if err != nil {
+		return false, err
+	}
+
+	var owners []string
+	byOwner := map[string][]client.Object{}
+	for _, orphan := range orphans {
+		owner := orphan.GetLabels()[r.keys.key(scopeInstanceUIDKey)]
+		if _, ok := byOwner[owner]; !ok {
+			owners = append(owners, owner)
+		}
+		byOwner[owner] = append(byOwner[owner], orphan)
+	}
+
+	deferred := false
+	deleted := 0
+	for _, owner := range owners {
+		bindings := byOwner[owner]
+		if r.exceedsDeleteLimit(len(bindings)) {
+			log.Log.Info("warning: refusing to delete orphaned (Cluster)RoleBindings, more than the limit", "scopeInstanceUID", owner,
+				"count", len(bindings), "limit", r.MaxDeletesPerReconcile)
+			continue
+		}
+		r.startDeleteIntents(orphanedDeleteIntentsName(owner))
+		confirmed, ownerDeferred := r.confirmedDeletes(orphanedDeleteIntentsName(owner), bindings)
+		deferred = deferred || ownerDeferred
+		if err := r.deleteBindings(ctx, confirmed); err != nil {
+			return false, err
+		}
+		deleted += len(confirmed)
+	}
+	if deleted > 0 {
+		log.Log.Info("deleted orphaned (Cluster)RoleBindings", "count", deleted)
+	}
+	return deferred, nil
+}
+
+// orphanedDeleteIntentsName is the name the deletions of the orphaned
+// bindings labeled with the ScopeInstance UID are deferred under. The slash
+// keeps it from being the name of a ScopeInstance.
+func orphanedDeleteIntentsName(owner string) string {
+	return "orphaned/" + owner
+}
+
+// orphanedBindings returns the bindings labeled for a ScopeInstance that does
+// not exist. Only the labels made by this reconciler, a UID behind the
+// ShadowPrefix, are considered, so that the bindings of a shadow reconciler
+// and those of the real one are never mistaken for orphans by the other. The
+// bindings are listed before the ScopeInstances, which are created first.
+func (r *ScopeInstanceReconciler) orphanedBindings(ctx context.Context, reader client.Reader) ([]client.Object, error) {
+	uidKey := r.keys.key(scopeInstanceUIDKey)
+	crbList, rbList, err := r.labeledBindings(ctx, uidKey)
+	if err != nil {
+		return nil, err
+	}
+	siList := &operatorsv1.ScopeInstanceList{}
+	if err := reader.List(ctx, siList); err != nil {
+		return nil, err
+	}
+
+	owners := sets.NewString()
+	for i := range siList.Items {
+		owners.Insert(r.bindingOwner(&siList.Items[i]))
+	}
+	orphaned := func(owner string) bool {
+		uid := strings.TrimPrefix(owner, r.ShadowPrefix)
+		if r.ShadowPrefix != "" && uid == owner {
+			return false
+		}
+		return uidPattern.MatchString(uid) && !owners.Has(owner)
+	}
+
+	var orphans []client.Object
+	for i := range crbList.Items {
+		if orphaned(crbList.Items[i].Labels[uidKey]) {
+			orphans = append(orphans, &crbList.Items[i])
+		}
+	}
+	for i := range rbList.Items {
+		if orphaned(rbList.Items[i].Labels[uidKey]) {
+			orphans = append(orphans, &rbList.Items[i])
+		}
+	}
+	return orphans, nil
+}
+
+// labeledBindings lists the bindings that have the label key.
+func (r *ScopeInstanceReconciler) labeledBindings(ctx context.Context, key string) (*rbacv1.ClusterRoleBindingList, *rbacv1.RoleBindingList, error) {
+	hasKey, err := labels.NewRequirement(key, selection.Exists, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	selector := client.MatchingLabelsSelector{Selector: labels.NewSelector().Add(*hasKey)}
+
+	crbList := &rbacv1.ClusterRoleBindingList{}
+	if err := r.reader().List(ctx, crbList, selector); err != nil {
+		return nil, nil, err
+	}
+	rbList := &rbacv1.RoleBindingList{}
+	if err := r.reader().List(ctx, rbList, selector); err != nil {
+		return nil, nil, err
+	}
+	return crbList, rbList, nil
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+var _ = Describe("OrphanSweep", func() {
+	const (
+		liveUID    = "2d6a3f4e-7c1b-4b8e-9f0a-5e6d7c8b9a01"
+		deletedUID = "8f1e2d3c-4b5a-4968-8776-a5b4c3d2e1f0"
+	)
+	var (
+		c  client.Client
+		st *operatorsv1.ScopeTemplate
+		si *operatorsv1.ScopeInstance
+	)
+	BeforeEach(func() {
+		st = newTestScopeTemplate("scopetemplate-orphan-sweep")
+		si = &operatorsv1.ScopeInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-orphan-sweep", UID: liveUID},
+			Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName(), Namespaces: []string{"ns-1"}},
+		}
+		c = newFakeClient(si, st, newTestClusterRole("test"))
+	})
+
+	// seedOrphans creates a ClusterRoleBinding and a RoleBinding labeled for
+	// the ScopeInstance with the given UID, as if it had been deleted while
+	// the operator was down.
+	seedOrphans := func(owner string) []client.Object {
+		orphans := []client.Object{
+			&rbacv1.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-orphan", Labels: map[string]string{scopeInstanceUIDKey: owner}},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "test"},
+			},
+			&rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns-2", Name: "test-orphan", Labels: map[string]string{scopeInstanceUIDKey: owner}},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "test"},
+			},
+		}
+		for _, orphan := range orphans {
+			Expect(c.Create(ctx, orphan)).To(Succeed())
+		}
+		return orphans
+	}
+	exists := func(obj client.Object) bool {
+		err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		if k8sapierrors.IsNotFound(err) {
+			return false
+		}
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		return true
+	}
+
+	It("should delete the bindings of ScopeInstances deleted while the operator was down", func() {
+		r := &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+		Expect(err).NotTo(HaveOccurred())
+		live := listFakeRoleBindings(c, "ns-1", si)
+		Expect(live).To(HaveLen(1))
+		orphans := seedOrphans(deletedUID)
+		unlabeled := &rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns-2", Name: "test-unlabeled"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "test"},
+		}
+		Expect(c.Create(ctx, unlabeled)).To(Succeed())
+
+		Expect(r.OrphanSweep(c).Start(ctx)).To(Succeed())
+		for _, orphan := range orphans {
+			Expect(exists(orphan)).To(BeFalse())
+		}
+		Expect(exists(&live[0])).To(BeTrue())
+		Expect(exists(unlabeled)).To(BeTrue())
+	})
+
+	It("should leave the bindings of another ShadowPrefix alone", func() {
+		orphans := seedOrphans("shadow-" + deletedUID)
+
+		r := &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme}
+		Expect(r.OrphanSweep(c).Start(ctx)).To(Succeed())
+		for _, orphan := range orphans {
+			Expect(exists(orphan)).To(BeTrue())
+		}
+
+		shadow := &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme, ShadowPrefix: "shadow-"}
+		Expect(shadow.OrphanSweep(c).Start(ctx)).To(Succeed())
+		for _, orphan := range orphans {
+			Expect(exists(orphan)).To(BeFalse())
+		}
+	})
+
+	It("should refuse to delete more orphans of a ScopeInstance than MaxDeletesPerReconcile", func() {
+		orphans := seedOrphans(deletedUID)
+
+		r := &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme, MaxDeletesPerReconcile: 1}
+		Expect(r.OrphanSweep(c).Start(ctx)).To(Succeed())
+		for _, orphan := range orphans {
+			Expect(exists(orphan)).To(BeTrue())
+		}
+	})
+
+	It("should confirm the deletions with another sweep in DeletionSafeMode", func() {
+		orphans := seedOrphans(deletedUID)
+
+		r := &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme, DeletionSafeMode: true}
+		deferred, err := r.sweepOrphanedBindings(ctx, c)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deferred).To(BeTrue())
+		for _, orphan := range orphans {
+			Expect(exists(orphan)).To(BeTrue())
+		}
+
+		sweep := r.OrphanSweep(c).(*orphanSweep)
+		sweep.confirmationDelay = 0
+		Expect(sweep.Start(ctx)).To(Succeed())
+		for _, orphan := range orphans {
+			Expect(exists(orphan)).To(BeFalse())
+		}
+	})
+
+	It("should delete the orphans migrated from the default prefix", func() {
+		orphans := seedOrphans(deletedUID)
+		Expect(MigrateKeys(ctx, c, "example.com/")).To(Succeed())
+		keys, err := newKeyMapping("example.com/")
+		Expect(err).NotTo(HaveOccurred())
+
+		r := &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme, keys: keys}
+		Expect(r.OrphanSweep(c).Start(ctx)).To(Succeed())
+		for _, orphan := range orphans {
+			Expect(exists(orphan)).To(BeFalse())
+		}
+	})
+})
@@ -0,0 +1,74 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+// errClusterRolesPending is wrapped by the errors returned for bindings
+// withheld until their ClusterRoles exist.
+var errClusterRolesPending = errors.New("ClusterRoles pending")
+
+// withoutPendingClusterRoles returns the desired bindings that do not
+// reference one of the PendingClusterRoles of the ScopeInstance, and an error
+// wrapping errClusterRolesPending if any binding was withheld. A ScopeTemplate
+// whose ClusterRoles are created one by one is then bound as each of them
+// becomes available, without bindings referencing ClusterRoles that do not
+// exist.
+func withoutPendingClusterRoles(keys *keyMapping, in *operatorsv1.ScopeInstance, desired []client.Object) ([]client.Object, error) {
+	pending := sets.NewString(in.Status.PendingClusterRoles...)
+	if pending.Len() == 0 {
+		return desired, nil
+	}
+
+	var available []client.Object
+	var withheld []string
+	for _, binding := range desired {
+		if ref := bindingRoleRef(binding); ref.Kind == "ClusterRole" && pending.Has(ref.Name) {
+			withheld = append(withheld, describeBinding(keys, binding))
+			continue
+		}
+		available = append(available, binding)
+	}
+	if len(withheld) == 0 {
+		return desired, nil
+	}
+	return available, fmt.Errorf("%w: %s", errClusterRolesPending, strings.Join(withheld, ", "))
+}
+
+// withheldBindingsError returns the error reporting the bindings withheld
+// from a reconcile, for pending ClusterRoles or too many subjects. When both
+// withheld some, the error wraps errTooManySubjects, which needs the
+// ScopeTemplate to be fixed, and describes the pending ClusterRoles too, and
+// ensureBindingsFailed still requeues the ScopeInstance for them.
+func withheldBindingsError(pending, tooManySubjects error) error {
+	switch {
+	case pending == nil:
+		return tooManySubjects
+	case tooManySubjects == nil:
+		return pending
+	}
+	return fmt.Errorf("%w; %v", tooManySubjects, pending)
+}
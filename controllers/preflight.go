@@ -0,0 +1,97 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimacherrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// preflightChecks are the permissions the operator needs to manage bindings.
+var preflightChecks = []authorizationv1.ResourceAttributes{
+	{Group: rbacv1.GroupName, Resource: "rolebindings", Verb: "list"},
+	{Group: rbacv1.GroupName, Resource: "rolebindings", Verb: "create"},
+	{Group: rbacv1.GroupName, Resource: "rolebindings", Verb: "delete"},
+	{Group: rbacv1.GroupName, Resource: "clusterrolebindings", Verb: "list"},
+	{Group: rbacv1.GroupName, Resource: "clusterrolebindings", Verb: "create"},
+	{Group: rbacv1.GroupName, Resource: "clusterrolebindings", Verb: "delete"},
+}
+
+// Preflight verifies with SelfSubjectAccessReviews that the operator is
+// allowed to list, create and delete (Cluster)RoleBindings, returning an
+// error naming every missing permission.
+func Preflight(ctx context.Context, c client.Client) error {
+	var errs []error
+	for _, attributes := range preflightChecks {
+		attributes := attributes
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &attributes,
+			},
+		}
+		if err := c.Create(ctx, review); err != nil {
+			return fmt.Errorf("checking permission to %s %s: %w", attributes.Verb, attributes.Resource, err)
+		}
+		if !review.Status.Allowed {
+			errs = append(errs, fmt.Errorf("not allowed to %s %s.%s: %s", attributes.Verb, attributes.Resource, attributes.Group, review.Status.Reason))
+		}
+	}
+	return apimacherrors.NewAggregate(errs)
+}
+
+// rbacAPIResources are the rbac.authorization.k8s.io/v1 resources managed by
+// the operator.
+var rbacAPIResources = []string{"clusterroles", "clusterrolebindings", "rolebindings"}
+
+// CheckRBACAPI verifies with discovery that the cluster serves every
+// rbac.authorization.k8s.io/v1 resource managed by the operator, returning an
+// error naming the missing resources and the version of the cluster, rather
+// than failing on the first reconcile.
+func CheckRBACAPI(dc discovery.DiscoveryInterface) error {
+	gv := rbacv1.SchemeGroupVersion.String()
+	resources, err := dc.ServerResourcesForGroupVersion(gv)
+	if err != nil && !k8sapierrors.IsNotFound(err) {
+		return fmt.Errorf("discovering %s: %w", gv, err)
+	}
+
+	served := sets.NewString()
+	if resources != nil {
+		for _, resource := range resources.APIResources {
+			served.Insert(resource.Name)
+		}
+	}
+	missing := sets.NewString(rbacAPIResources...).Difference(served)
+	if missing.Len() == 0 {
+		return nil
+	}
+
+	version := "of an unknown version"
+	if info, err := dc.ServerVersion(); err == nil {
+		version = info.GitVersion
+	}
+	return fmt.Errorf("the cluster (%s) does not serve %s in %s, which the operator requires",
+		version, strings.Join(missing.List(), ", "), gv)
+}
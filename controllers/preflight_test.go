@@ -0,0 +1,115 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Preflight", func() {
+	It("should pass when every permission is allowed", func() {
+		c := &fakeAuthorizerClient{
+			Client:  newFakeClient(),
+			allowed: func(*authorizationv1.ResourceAttributes) bool { return true },
+		}
+		Expect(Preflight(ctx, c)).To(Succeed())
+		Expect(c.reviews).To(Equal(len(preflightChecks)))
+	})
+
+	It("should fail when a permission is denied", func() {
+		c := &fakeAuthorizerClient{
+			Client: newFakeClient(),
+			allowed: func(attributes *authorizationv1.ResourceAttributes) bool {
+				return attributes.Resource != "clusterrolebindings" || attributes.Verb != "create"
+			},
+		}
+		err := Preflight(ctx, c)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("not allowed to create clusterrolebindings.rbac.authorization.k8s.io: denied"))
+	})
+})
+
+var _ = Describe("CheckRBACAPI", func() {
+	newFakeDiscovery := func(resources ...*metav1.APIResourceList) *fakediscovery.FakeDiscovery {
+		return &fakediscovery.FakeDiscovery{
+			Fake:               &clienttesting.Fake{Resources: resources},
+			FakedServerVersion: &version.Info{GitVersion: "v1.7.0"},
+		}
+	}
+
+	It("should pass when the cluster serves the RBAC resources", func() {
+		dc := newFakeDiscovery(&metav1.APIResourceList{
+			GroupVersion: "rbac.authorization.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "clusterroles"},
+				{Name: "clusterrolebindings"},
+				{Name: "roles"},
+				{Name: "rolebindings"},
+			},
+		})
+		Expect(CheckRBACAPI(dc)).To(Succeed())
+	})
+
+	It("should report a cluster lacking the RBAC group as incompatible", func() {
+		dc := newFakeDiscovery(&metav1.APIResourceList{
+			GroupVersion: "rbac.authorization.k8s.io/v1beta1",
+			APIResources: []metav1.APIResource{{Name: "clusterroles"}},
+		})
+		err := CheckRBACAPI(dc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("the cluster (v1.7.0) does not serve clusterrolebindings, clusterroles, rolebindings in rbac.authorization.k8s.io/v1, which the operator requires"))
+	})
+
+	It("should report the RBAC resources the cluster does not serve", func() {
+		dc := newFakeDiscovery(&metav1.APIResourceList{
+			GroupVersion: "rbac.authorization.k8s.io/v1",
+			APIResources: []metav1.APIResource{{Name: "clusterroles"}, {Name: "rolebindings"}},
+		})
+		err := CheckRBACAPI(dc)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("does not serve clusterrolebindings in"))
+	})
+})
+
+// fakeAuthorizerClient answers SelfSubjectAccessReviews with the allowed func.
+type fakeAuthorizerClient struct {
+	client.Client
+	allowed func(attributes *authorizationv1.ResourceAttributes) bool
+	reviews int
+}
+
+func (c *fakeAuthorizerClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	review, ok := obj.(*authorizationv1.SelfSubjectAccessReview)
+	if !ok {
+		return c.Client.Create(ctx, obj, opts...)
+	}
+	c.reviews++
+	review.Status.Allowed = c.allowed(review.Spec.ResourceAttributes)
+	if !review.Status.Allowed {
+		review.Status.Reason = "denied"
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"container/heap"
+	"sync"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// priorityGate holds the requests enqueued by event handlers and releases
+// them to the controller workqueue in priority order, one at a time, as the
+// workqueue drains. The workqueue itself is first in first out, so during a
+// backlog this makes higher priority requests reconcile first. Requeues made
+// by the controller after a reconcile go to the workqueue directly.
+type priorityGate struct {
+	priority func(reconcile.Request) int32
+
+	mu sync.Mutex
+	// queue is the controller workqueue, known from the first event.
+	queue   workqueue.RateLimitingInterface
+	pending pendingRequests
+	held    map[reconcile.Request]bool
+	seq     uint64
+}
+
+func newPriorityGate(priority func(reconcile.Request) int32) *priorityGate {
+	return &priorityGate{
+		priority: priority,
+		held:     map[reconcile.Request]bool{},
+	}
+}
+
+// handler wraps h so that the requests it enqueues wait in the gate.
+func (g *priorityGate) handler(h handler.EventHandler) handler.EventHandler {
+	return handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			h.Create(e, &gatedQueue{RateLimitingInterface: q, gate: g})
+		},
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			h.Update(e, &gatedQueue{RateLimitingInterface: q, gate: g})
+		},
+		DeleteFunc: func(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			h.Delete(e, &gatedQueue{RateLimitingInterface: q, gate: g})
+		},
+		GenericFunc: func(e event.GenericEvent, q workqueue.RateLimitingInterface) {
+			h.Generic(e, &gatedQueue{RateLimitingInterface: q, gate: g})
+		},
+	}
+}
+
+// add holds the request until the workqueue is empty.
+func (g *priorityGate) add(q workqueue.RateLimitingInterface, req reconcile.Request) {
+	priority := g.priority(req)
+
+	g.mu.Lock()
+	g.queue = q
+	if !g.held[req] {
+		g.held[req] = true
+		g.seq++
+		heap.Push(&g.pending, pendingRequest{request: req, priority: priority, seq: g.seq})
+	}
+	g.mu.Unlock()
+
+	g.next()
+}
+
+// next releases the highest priority requests while the workqueue is empty.
+// It is called on every add and whenever a reconcile starts, as that is when
+// the workqueue drains.
+func (g *priorityGate) next() {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.queue == nil {
+		return
+	}
+	for g.queue.Len() == 0 && g.pending.Len() > 0 {
+		req := heap.Pop(&g.pending).(pendingRequest).request
+		delete(g.held, req)
+		g.queue.Add(req)
+	}
+}
+
+// gatedQueue passes the requests added by an event handler to the gate.
+type gatedQueue struct {
+	workqueue.RateLimitingInterface
+	gate *priorityGate
+}
+
+func (q *gatedQueue) Add(item interface{}) {
+	req, ok := item.(reconcile.Request)
+	if !ok {
+		q.RateLimitingInterface.Add(item)
+		return
+	}
+	q.gate.add(q.RateLimitingInterface, req)
+}
+
+type pendingRequest struct {
+	request  reconcile.Request
+	priority int32
+	// seq keeps requests of the same priority first in first out.
+	seq uint64
+}
+
+// pendingRequests implements heap.Interface, highest priority first.
+type pendingRequests []pendingRequest
+
+func (p pendingRequests) Len() int { return len(p) }
+func (p pendingRequests) Less(i, j int) bool {
+	if p[i].priority != p[j].priority {
+		return p[i].priority > p[j].priority
+	}
+	return p[i].seq < p[j].seq
+}
+func (p pendingRequests) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+
+func (p *pendingRequests) Push(x interface{}) {
+	*p = append(*p, x.(pendingRequest))
+}
+
+func (p *pendingRequests) Pop() interface{} {
+	old := *p
+	last := old[len(old)-1]
+	*p = old[:len(old)-1]
+	return last
+}
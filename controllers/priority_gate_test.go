@@ -0,0 +1,86 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+var _ = Describe("priorityGate", func() {
+	It("should reconcile higher priority ScopeInstances first under a backlog", func() {
+		st := newTestScopeTemplate("scopetemplate-priority")
+		objs := []client.Object{st, newTestClusterRole("test")}
+		var instances []*operatorsv1.ScopeInstance
+		for _, p := range []struct {
+			name     string
+			priority int32
+		}{
+			{"low-a", 0},
+			{"low-b", 0},
+			{"mid", 5},
+			{"break-glass", 10},
+			{"low-c", 0},
+		} {
+			si := &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: p.name, UID: types.UID(p.name + "-uid")},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Priority:          p.priority,
+				},
+			}
+			instances = append(instances, si)
+			objs = append(objs, si)
+		}
+
+		r := &ScopeInstanceReconciler{Client: newFakeClient(objs...), Scheme: scheme.Scheme}
+		r.priorities = newPriorityGate(r.priorityOf)
+		q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer q.ShutDown()
+
+		By("enqueuing every ScopeInstance before any is reconciled")
+		h := r.priorities.handler(&handler.EnqueueRequestForObject{})
+		for _, si := range instances {
+			h.Create(event.CreateEvent{Object: si}, q)
+		}
+		// The first request is released right away, as nothing else was waiting
+		Expect(q.Len()).To(Equal(1))
+
+		By("working through the workqueue like the controller")
+		var order []string
+		for q.Len() > 0 {
+			item, _ := q.Get()
+			req := item.(reconcile.Request)
+			order = append(order, req.Name)
+			_, err := r.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			q.Forget(item)
+			q.Done(item)
+		}
+		Expect(order).To(Equal([]string{"low-a", "break-glass", "mid", "low-b", "low-c"}))
+	})
+})
@@ -0,0 +1,113 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+// reconcilePlan is what a reconcile is going to do to the bindings of a
+// ScopeInstance, each binding described by its kind, namespace and name.
+type reconcilePlan struct {
+	create []string
+	update []string
+	delete []string
+}
+
+// logReconcilePlan logs at V(2) the difference between the desired bindings
+// of the ScopeInstance, as computed by desiredBindings, and the bindings it
+// has, for deep debugging. The bindings are only listed when V(2) is
+// enabled.
+func (r *ScopeInstanceReconciler) logReconcilePlan(ctx context.Context, in *operatorsv1.ScopeInstance, desired []client.Object) {
+	logger := log.FromContext(ctx).V(2)
+	if !logger.Enabled() {
+		return
+	}
+
+	listOption := client.MatchingLabels{r.keys.key(scopeInstanceUIDKey): r.bindingOwner(in)}
+	var existing []client.Object
+	crbList := &rbacv1.ClusterRoleBindingList{}
+	if err := r.Client.List(ctx, crbList, listOption); err != nil {
+		logger.Error(err, "in listing ClusterRoleBindings for the reconcile plan")
+		return
+	}
+	for i := range crbList.Items {
+		existing = append(existing, &crbList.Items[i])
+	}
+	rbList := &rbacv1.RoleBindingList{}
+	if err := r.Client.List(ctx, rbList, listOption); err != nil {
+		logger.Error(err, "in listing RoleBindings for the reconcile plan")
+		return
+	}
+	for i := range rbList.Items {
+		existing = append(existing, &rbList.Items[i])
+	}
+
+	plan := planBindings(r.keys, desired, existing)
+	logger.Info("reconcile plan", "scopeInstance", in.GetName(), "create", plan.create, "update", plan.update, "delete", plan.delete)
+}
+
+// planBindings returns the plan bringing the existing bindings to the
+// desired ones. A desired binding matches the existing one of the same kind,
+// namespace and generateName, which is updated if its hash, role or subjects
+// differ. The existing bindings matching no desired one are deleted.
+func planBindings(keys *keyMapping, desired, existing []client.Object) reconcilePlan {
+	planKey := func(binding client.Object) string {
+		return bindingKind(binding) + "/" + binding.GetNamespace() + "/" + binding.GetLabels()[keys.key(clusterRoleBindingGenerateKey)]
+	}
+	byKey := map[string]client.Object{}
+	for _, binding := range existing {
+		byKey[planKey(binding)] = binding
+	}
+
+	var plan reconcilePlan
+	for _, binding := range desired {
+		key := planKey(binding)
+		current, ok := byKey[key]
+		if !ok {
+			plan.create = append(plan.create, describePlannedBinding(keys, binding))
+			continue
+		}
+		delete(byKey, key)
+		if current.GetAnnotations()[keys.key(referenceHashKey)] != binding.GetAnnotations()[keys.key(referenceHashKey)] ||
+			bindingRoleRef(current) != bindingRoleRef(binding) ||
+			!equalSubjects(bindingSubjects(current), bindingSubjects(binding)) {
+			plan.update = append(plan.update, describePlannedBinding(keys, current))
+		}
+	}
+	for _, binding := range existing {
+		if _, ok := byKey[planKey(binding)]; ok {
+			plan.delete = append(plan.delete, describePlannedBinding(keys, binding))
+		}
+	}
+	return plan
+}
+
+// describePlannedBinding describes a binding by its kind and key, or by its
+// generateName if it has no name yet.
+func describePlannedBinding(keys *keyMapping, binding client.Object) string {
+	if binding.GetName() == "" {
+		return describeBinding(keys, binding)
+	}
+	return bindingKind(binding) + " " + client.ObjectKeyFromObject(binding).String()
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/zap/zapcore"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+var _ = Describe("logReconcilePlan", func() {
+	var (
+		r  *ScopeInstanceReconciler
+		st *operatorsv1.ScopeTemplate
+		si *operatorsv1.ScopeInstance
+	)
+	BeforeEach(func() {
+		st = newTestScopeTemplate("scopetemplate-plan")
+		si = &operatorsv1.ScopeInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-plan", UID: "scopeinstance-plan-uid"},
+			Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName(), Namespaces: []string{"ns-1", "ns-2"}},
+		}
+		r = &ScopeInstanceReconciler{Client: newFakeClient(si, st, newTestClusterRole("test")), Scheme: scheme.Scheme}
+	})
+
+	// plans reconciles the ScopeInstance logging at the given verbosity,
+	// returning the plans it logged
+	plans := func(verbosity int) []map[string]interface{} {
+		buf := &bytes.Buffer{}
+		logCtx := log.IntoContext(ctx, zap.New(zap.WriteTo(buf), zap.Level(zapcore.Level(-verbosity))))
+		_, err := r.Reconcile(logCtx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+		var logged []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			entry := map[string]interface{}{}
+			ExpectWithOffset(1, json.Unmarshal([]byte(line), &entry)).To(Succeed())
+			if entry["msg"] == "reconcile plan" {
+				logged = append(logged, entry)
+			}
+		}
+		return logged
+	}
+
+	It("should log the bindings to create, update and delete at V(2)", func() {
+		Expect(plans(2)).To(ConsistOf(And(
+			HaveKeyWithValue("scopeInstance", si.GetName()),
+			HaveKeyWithValue("create", ConsistOf("RoleBinding test in namespace ns-1", "RoleBinding test in namespace ns-2")),
+			HaveKeyWithValue("update", BeEmpty()),
+			HaveKeyWithValue("delete", BeEmpty()),
+		)))
+		names := map[string]string{}
+		for _, ns := range []string{"ns-1", "ns-2"} {
+			rbs := listFakeRoleBindings(r.Client, ns, si)
+			Expect(rbs).To(HaveLen(1))
+			names[ns] = rbs[0].GetName()
+		}
+
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(st), st)).To(Succeed())
+		st.Spec.ClusterRoles[0].Subjects = append(st.Spec.ClusterRoles[0].Subjects, rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "auditors"})
+		Expect(r.Client.Update(ctx, st)).To(Succeed())
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+		si.Spec.Namespaces = []string{"ns-2", "ns-3"}
+		Expect(r.Client.Update(ctx, si)).To(Succeed())
+
+		Expect(plans(2)).To(ConsistOf(And(
+			HaveKeyWithValue("create", ConsistOf("RoleBinding test in namespace ns-3")),
+			HaveKeyWithValue("update", ConsistOf("RoleBinding ns-2/"+names["ns-2"])),
+			HaveKeyWithValue("delete", ConsistOf("RoleBinding ns-1/"+names["ns-1"])),
+		)))
+	})
+
+	It("should not log the plan below V(2)", func() {
+		Expect(plans(1)).To(BeEmpty())
+		Expect(listFakeRoleBindings(r.Client, "ns-1", si)).To(HaveLen(1))
+	})
+})
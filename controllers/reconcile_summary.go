@@ -0,0 +1,108 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// reconcileSummary counts the bindings written by a single reconcile of a
+// ScopeInstance, so that it is logged once instead of once per binding. A nil
+// reconcileSummary is valid and counts nothing.
+type reconcileSummary struct {
+	created    int64
+	updated    int64
+	deleted    int64
+	namespaces int64
+	// unobserved counts the listed bindings that the reconciler deleted
+	// before the cache observed it.
+	unobserved int64
+}
+
+type reconcileSummaryKey struct{}
+
+// withReconcileSummary returns a copy of ctx carrying a new reconcileSummary.
+func withReconcileSummary(ctx context.Context) (context.Context, *reconcileSummary) {
+	s := &reconcileSummary{}
+	return context.WithValue(ctx, reconcileSummaryKey{}, s), s
+}
+
+// reconcileSummaryFrom returns the reconcileSummary carried by ctx, if any.
+func reconcileSummaryFrom(ctx context.Context) *reconcileSummary {
+	s, _ := ctx.Value(reconcileSummaryKey{}).(*reconcileSummary)
+	return s
+}
+
+func (s *reconcileSummary) bindingCreated() {
+	if s != nil {
+		atomic.AddInt64(&s.created, 1)
+	}
+}
+
+func (s *reconcileSummary) bindingUpdated() {
+	if s != nil {
+		atomic.AddInt64(&s.updated, 1)
+	}
+}
+
+func (s *reconcileSummary) bindingDeleted() {
+	if s != nil {
+		atomic.AddInt64(&s.deleted, 1)
+	}
+}
+
+func (s *reconcileSummary) setNamespaces(n int) {
+	if s != nil {
+		atomic.StoreInt64(&s.namespaces, int64(n))
+	}
+}
+
+func (s *reconcileSummary) deleteUnobserved() {
+	if s != nil {
+		atomic.AddInt64(&s.unobserved, 1)
+	}
+}
+
+// waitingForCache returns true if a binding was listed although the
+// reconciler deleted it.
+func (s *reconcileSummary) waitingForCache() bool {
+	return atomic.LoadInt64(&s.unobserved) > 0
+}
+
+// wrote returns true if any binding was created, updated or deleted.
+func (s *reconcileSummary) wrote() bool {
+	return atomic.LoadInt64(&s.created)+atomic.LoadInt64(&s.updated)+atomic.LoadInt64(&s.deleted) > 0
+}
+
+// writes describes the bindings written.
+func (s *reconcileSummary) writes() string {
+	return fmt.Sprintf("Created %d, updated %d and deleted %d bindings",
+		atomic.LoadInt64(&s.created), atomic.LoadInt64(&s.updated), atomic.LoadInt64(&s.deleted))
+}
+
+// keysAndValues returns the counts as logger key/value pairs.
+func (s *reconcileSummary) keysAndValues() []interface{} {
+	return []interface{}{
+		"created", atomic.LoadInt64(&s.created),
+		"updated", atomic.LoadInt64(&s.updated),
+		"deleted", atomic.LoadInt64(&s.deleted),
+		"namespaces", atomic.LoadInt64(&s.namespaces),
+		"unobservedDeletes", atomic.LoadInt64(&s.unobserved),
+	}
+}
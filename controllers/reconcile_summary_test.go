@@ -0,0 +1,96 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+var _ = Describe("reconcileSummary", func() {
+	It("should log a single summary per reconcile", func() {
+		st := newTestScopeTemplate("scopetemplate-summary")
+		si := &operatorsv1.ScopeInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-summary", UID: "scopeinstance-summary-uid"},
+			Spec: operatorsv1.ScopeInstanceSpec{
+				ScopeTemplateName: st.GetName(),
+				Namespaces:        []string{"ns-1", "ns-2"},
+			},
+		}
+		r := &ScopeInstanceReconciler{Client: newFakeClient(si, st, newTestClusterRole("test")), Scheme: scheme.Scheme}
+
+		// summaries reconciles the ScopeInstance, returning the summaries it logged
+		summaries := func() []map[string]interface{} {
+			buf := &bytes.Buffer{}
+			logCtx := log.IntoContext(ctx, zap.New(zap.WriteTo(buf)))
+			_, err := r.Reconcile(logCtx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			var logged []map[string]interface{}
+			for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+				entry := map[string]interface{}{}
+				Expect(json.Unmarshal([]byte(line), &entry)).To(Succeed())
+				if entry["msg"] == "reconciled ScopeInstance" {
+					logged = append(logged, entry)
+				}
+			}
+			return logged
+		}
+
+		Expect(summaries()).To(ConsistOf(And(
+			HaveKeyWithValue("scopeInstance", si.GetName()),
+			HaveKeyWithValue("created", BeNumerically("==", 2)),
+			HaveKeyWithValue("updated", BeNumerically("==", 0)),
+			HaveKeyWithValue("deleted", BeNumerically("==", 0)),
+			HaveKeyWithValue("namespaces", BeNumerically("==", 2)),
+			HaveKeyWithValue("reason", operatorsv1.ReasonScopingSuccessful),
+		)))
+
+		By("removing a namespace")
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+		si.Spec.Namespaces = []string{"ns-1"}
+		Expect(r.Client.Update(ctx, si)).To(Succeed())
+		Expect(summaries()).To(ConsistOf(And(
+			HaveKeyWithValue("created", BeNumerically("==", 0)),
+			HaveKeyWithValue("deleted", BeNumerically("==", 1)),
+			HaveKeyWithValue("namespaces", BeNumerically("==", 1)),
+		)))
+
+		By("changing the subjects of the ScopeTemplate")
+		Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(st), st)).To(Succeed())
+		st.Spec.ClusterRoles[0].Subjects[0].Name = "auditors"
+		Expect(r.Client.Update(ctx, st)).To(Succeed())
+		Expect(summaries()).To(ConsistOf(And(
+			HaveKeyWithValue("created", BeNumerically("==", 0)),
+			HaveKeyWithValue("updated", BeNumerically("==", 1)),
+			HaveKeyWithValue("deleted", BeNumerically("==", 0)),
+		)))
+	})
+})
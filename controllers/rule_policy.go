@@ -0,0 +1,247 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+// errPolicyViolation is wrapped by the errors returned for ScopeTemplates
+// whose ClusterRoles grant a rule forbidden by the RulePolicy.
+var errPolicyViolation = errors.New("forbidden by the rule policy")
+
+// errClusterWideViolation is wrapped by the errors returned for
+// ScopeInstances that would bind a ClusterRole cluster-wide that grants a verb
+// the ClusterWidePolicy does not allow.
+var errClusterWideViolation = errors.New("not allowed cluster-wide")
+
+// ForbiddenRule is a verb on a resource that no ClusterRole of a ScopeTemplate
+// may grant. Each field may be "*" to forbid any value.
+type ForbiddenRule struct {
+	Verb     string
+	APIGroup string
+	Resource string
+}
+
+// String returns the rule in the format parsed by ParseRulePolicy.
+func (f ForbiddenRule) String() string {
+	if f.APIGroup == "" {
+		return f.Verb + ":" + f.Resource
+	}
+	return f.Verb + ":" + f.Resource + "." + f.APIGroup
+}
+
+// RulePolicy lists the rules forbidden in the ClusterRoles of ScopeTemplates.
+// The zero RulePolicy allows everything.
+type RulePolicy []ForbiddenRule
+
+// ParseRulePolicy parses a comma separated list of forbidden rules, each
+// formatted as <verb>:<resource>[.<group>], such as "*:secrets" or
+// "escalate:clusterroles.rbac.authorization.k8s.io". The group is the core
+// group if omitted.
+func ParseRulePolicy(s string) (RulePolicy, error) {
+	var policy RulePolicy
+	for _, entry := range splitList(s) {
+		verb, resource, ok := strings.Cut(entry, ":")
+		if !ok || verb == "" || resource == "" {
+			return nil, fmt.Errorf("invalid forbidden rule %q, expected <verb>:<resource>[.<group>]", entry)
+		}
+		resource, group, _ := strings.Cut(resource, ".")
+		policy = append(policy, ForbiddenRule{Verb: verb, APIGroup: group, Resource: resource})
+	}
+	return policy, nil
+}
+
+// splitList returns the non-empty entries of a comma separated list.
+func splitList(s string) []string {
+	var entries []string
+	for _, entry := range strings.Split(s, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// violations returns a description of every forbidden rule granted by the
+// ClusterRoles of the ScopeTemplate.
+func (p RulePolicy) violations(st *operatorsv1.ScopeTemplate) []string {
+	var violations []string
+	for _, cr := range st.Spec.ClusterRoles {
+		violations = append(violations, p.ruleViolations(cr.GenerateName, cr.Rules)...)
+	}
+	return violations
+}
+
+// ruleViolations returns a description of every forbidden rule granted by
+// the rules of the named ClusterRole.
+func (p RulePolicy) ruleViolations(name string, rules []rbacv1.PolicyRule) []string {
+	var violations []string
+	for _, forbidden := range p {
+		for _, rule := range rules {
+			if grants(rule, forbidden) {
+				violations = append(violations, fmt.Sprintf("ClusterRole %s grants %s", name, forbidden))
+				break
+			}
+		}
+	}
+	return violations
+}
+
+// check returns an error wrapping errPolicyViolation if the ClusterRoles of
+// the ScopeTemplate grant a forbidden rule.
+func (p RulePolicy) check(st *operatorsv1.ScopeTemplate) error {
+	if violations := p.violations(st); len(violations) > 0 {
+		return fmt.Errorf("%w: %s", errPolicyViolation, strings.Join(violations, ", "))
+	}
+	return nil
+}
+
+// checkBound returns an error wrapping errPolicyViolation if the ScopeInstance
+// binds a ClusterRole granting a forbidden rule. Besides the ClusterRoles of
+// the ScopeTemplate, checked as declared, this covers every ClusterRole bound
+// in their place by an override of the ScopeInstance, by the Bindings of the
+// ScopeTemplate or by its clusterRoleSelector. A ClusterRole that does not
+// exist yet is checked once it does.
+func (p RulePolicy) checkBound(ctx context.Context, c client.Reader, in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate) error {
+	if len(p) == 0 {
+		return nil
+	}
+
+	violations := p.violations(st)
+	declared := sets.NewString()
+	for _, cr := range st.Spec.ClusterRoles {
+		declared.Insert(cr.GenerateName)
+	}
+	for _, cr := range st.Spec.BindingTemplates() {
+		name := roleRef(&cr, in).Name
+		if bindsRole(&cr) || declared.Has(name) {
+			continue
+		}
+		clusterRole := &rbacv1.ClusterRole{}
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, clusterRole); err != nil {
+			if k8sapierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		violations = append(violations, p.ruleViolations(name, clusterRole.Rules)...)
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%w: %s", errPolicyViolation, strings.Join(violations, ", "))
+	}
+	return nil
+}
+
+// grants returns true if the rule grants the forbidden verb on the forbidden
+// resource.
+func grants(rule rbacv1.PolicyRule, forbidden ForbiddenRule) bool {
+	return matchesAny(rule.Verbs, forbidden.Verb) &&
+		matchesAny(rule.APIGroups, forbidden.APIGroup) &&
+		matchesAny(rule.Resources, forbidden.Resource)
+}
+
+// matchesAny returns true if any of the values of a rule match the forbidden
+// value, either of which may be a wildcard.
+func matchesAny(values []string, forbidden string) bool {
+	for _, value := range values {
+		if value == forbidden || value == rbacv1.VerbAll || forbidden == rbacv1.VerbAll {
+			return true
+		}
+	}
+	return false
+}
+
+// ClusterWidePolicy limits the verbs of the ClusterRoles bound cluster-wide,
+// by ScopeInstances selecting no namespace or entries scoped to the cluster,
+// so that write access to every namespace is only granted on purpose. The
+// zero ClusterWidePolicy allows everything.
+type ClusterWidePolicy struct {
+	// Verbs lists the verbs a ClusterRole bound cluster-wide may grant, such
+	// as get, list and watch. Every verb is allowed if it is empty.
+	Verbs sets.String
+
+	// ClusterRoles lists the ClusterRoles that may be bound cluster-wide
+	// whatever they grant.
+	ClusterRoles sets.String
+}
+
+// ParseClusterWidePolicy parses the comma separated lists of the verbs and of
+// the ClusterRoles allowed cluster-wide.
+func ParseClusterWidePolicy(verbs, clusterRoles string) ClusterWidePolicy {
+	return ClusterWidePolicy{
+		Verbs:        sets.NewString(splitList(verbs)...),
+		ClusterRoles: sets.NewString(splitList(clusterRoles)...),
+	}
+}
+
+// check returns an error wrapping errClusterWideViolation if the ScopeInstance
+// binds cluster-wide a ClusterRole that is not allowlisted and grants a verb
+// that is not allowed. The PendingClusterRoles of the ScopeInstance are not
+// bound yet, they are checked once they exist.
+func (p ClusterWidePolicy) check(ctx context.Context, c client.Reader, in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate) error {
+	if p.Verbs.Len() == 0 {
+		return nil
+	}
+
+	pending := sets.NewString(in.Status.PendingClusterRoles...)
+	var violations []string
+	for _, cr := range st.Spec.BindingTemplates() {
+		if bindsRole(&cr) || !(isClusterScoped(in) || isClusterBound(&cr)) {
+			continue
+		}
+		name := roleRef(&cr, in).Name
+		if p.ClusterRoles.Has(name) || pending.Has(name) {
+			continue
+		}
+		clusterRole := &rbacv1.ClusterRole{}
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, clusterRole); err != nil {
+			return err
+		}
+		if verbs := p.disallowedVerbs(clusterRole); len(verbs) > 0 {
+			violations = append(violations, fmt.Sprintf("ClusterRole %s grants %s", name, strings.Join(verbs, ", ")))
+		}
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%w: %s", errClusterWideViolation, strings.Join(violations, ", "))
+	}
+	return nil
+}
+
+// disallowedVerbs returns the sorted verbs granted by the ClusterRole that
+// are not allowed cluster-wide.
+func (p ClusterWidePolicy) disallowedVerbs(clusterRole *rbacv1.ClusterRole) []string {
+	disallowed := sets.NewString()
+	for _, rule := range clusterRole.Rules {
+		for _, verb := range rule.Verbs {
+			if !p.Verbs.Has(verb) && !p.Verbs.Has(rbacv1.VerbAll) {
+				disallowed.Insert(verb)
+			}
+		}
+	}
+	return disallowed.List()
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+var _ = Describe("RulePolicy", func() {
+	It("should parse forbidden rules", func() {
+		policy, err := ParseRulePolicy("*:secrets, escalate:clusterroles.rbac.authorization.k8s.io,")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policy).To(Equal(RulePolicy{
+			{Verb: "*", Resource: "secrets"},
+			{Verb: "escalate", APIGroup: "rbac.authorization.k8s.io", Resource: "clusterroles"},
+		}))
+		Expect(policy[1].String()).To(Equal("escalate:clusterroles.rbac.authorization.k8s.io"))
+
+		_, err = ParseRulePolicy("secrets")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should match wildcards on either side", func() {
+		rule := rbacv1.PolicyRule{APIGroups: []string{""}, Verbs: []string{"get"}, Resources: []string{"secrets"}}
+		Expect(grants(rule, ForbiddenRule{Verb: "*", Resource: "secrets"})).To(BeTrue())
+		Expect(grants(rule, ForbiddenRule{Verb: "get", APIGroup: "*", Resource: "*"})).To(BeTrue())
+		Expect(grants(rule, ForbiddenRule{Verb: "delete", Resource: "secrets"})).To(BeFalse())
+		Expect(grants(rule, ForbiddenRule{Verb: "get", APIGroup: "apps", Resource: "secrets"})).To(BeFalse())
+
+		rule.Verbs = []string{"*"}
+		Expect(grants(rule, ForbiddenRule{Verb: "delete", Resource: "secrets"})).To(BeTrue())
+	})
+
+	When("the controllers enforce the policy", func() {
+		var (
+			st *operatorsv1.ScopeTemplate
+			si *operatorsv1.ScopeInstance
+			c  client.Client
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-rule-policy")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-rule-policy", UID: "scopeinstance-rule-policy-uid"},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1"},
+				},
+			}
+			c = newFakeClient(st, si)
+		})
+		reconcileBoth := func(policy string) {
+			rulePolicy, err := ParseRulePolicy(policy)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = (&ScopeTemplateReconciler{Client: c, Scheme: scheme.Scheme, RulePolicy: rulePolicy}).
+				Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = (&ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme, RulePolicy: rulePolicy}).
+				Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(st), st)).To(Succeed())
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+		}
+
+		It("should create the RBAC of a compliant ScopeTemplate", func() {
+			reconcileBoth("delete:secrets,*:clusterroles.rbac.authorization.k8s.io")
+
+			Expect(c.Get(ctx, client.ObjectKey{Name: "test"}, &rbacv1.ClusterRole{})).To(Succeed())
+			Expect(listFakeRoleBindings(c, "ns-1", si)).To(HaveLen(1))
+			Expect(meta.IsStatusConditionTrue(st.Status.Conditions, operatorsv1.TypeTemplated)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(si.Status.Conditions, operatorsv1.TypeScoped)).To(BeTrue())
+		})
+
+		It("should not create the RBAC of a violating ScopeTemplate", func() {
+			reconcileBoth("*:secrets")
+
+			err := c.Get(ctx, client.ObjectKey{Name: "test"}, &rbacv1.ClusterRole{})
+			Expect(k8sapierrors.IsNotFound(err)).To(BeTrue())
+			Expect(listFakeRoleBindings(c, "ns-1", si)).To(BeEmpty())
+
+			cond := meta.FindStatusCondition(st.Status.Conditions, operatorsv1.TypeTemplated)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonPolicyViolation))
+			Expect(cond.Message).To(ContainSubstring("ClusterRole test grants *:secrets"))
+
+			cond = meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonPolicyViolation))
+		})
+
+		It("should not bind a violating ClusterRole in place of a compliant one", func() {
+			Expect(c.Create(ctx, &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{Name: "secret-admin"},
+				Rules:      []rbacv1.PolicyRule{{APIGroups: []string{""}, Verbs: []string{"*"}, Resources: []string{"secrets"}}},
+			})).To(Succeed())
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.ClusterRoleNameOverrides = map[string]string{"test": "secret-admin"}
+			Expect(c.Update(ctx, si)).To(Succeed())
+
+			reconcileBoth("delete:secrets")
+
+			Expect(listFakeRoleBindings(c, "ns-1", si)).To(BeEmpty())
+			Expect(meta.IsStatusConditionTrue(st.Status.Conditions, operatorsv1.TypeTemplated)).To(BeTrue())
+			cond := meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonPolicyViolation))
+			Expect(cond.Message).To(ContainSubstring("ClusterRole secret-admin grants delete:secrets"))
+		})
+	})
+
+	When("the ScopeInstance controller enforces the cluster-wide policy", func() {
+		var (
+			st *operatorsv1.ScopeTemplate
+			si *operatorsv1.ScopeInstance
+			c  client.Client
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-cluster-wide-policy")
+			// A ScopeInstance selecting no namespace binds cluster-wide
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-cluster-wide-policy", UID: "scopeinstance-cluster-wide-policy-uid"},
+				Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName()},
+			}
+		})
+		reconcile := func(clusterRole *rbacv1.ClusterRole, clusterRoles string) {
+			c = newFakeClient(st, si, clusterRole)
+			r := &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme,
+				ClusterWidePolicy: ParseClusterWidePolicy("get, list, watch", clusterRoles)}
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+		}
+
+		It("should bind a read-only ClusterRole cluster-wide", func() {
+			reconcile(newTestClusterRole("test"), "")
+
+			Expect(listFakeClusterRoleBindings(c, si)).To(HaveLen(1))
+			Expect(meta.IsStatusConditionTrue(si.Status.Conditions, operatorsv1.TypeScoped)).To(BeTrue())
+		})
+
+		It("should not bind a write-capable ClusterRole cluster-wide", func() {
+			clusterRole := newTestClusterRole("test")
+			clusterRole.Rules[0].Verbs = append(clusterRole.Rules[0].Verbs, "delete", "create")
+			reconcile(clusterRole, "")
+
+			Expect(listFakeClusterRoleBindings(c, si)).To(BeEmpty())
+			cond := meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonClusterWideViolation))
+			Expect(cond.Message).To(ContainSubstring("ClusterRole test grants create, delete"))
+		})
+
+		It("should bind an allowlisted write-capable ClusterRole cluster-wide", func() {
+			clusterRole := newTestClusterRole("test")
+			clusterRole.Rules[0].Verbs = []string{"*"}
+			reconcile(clusterRole, "other, test")
+
+			Expect(listFakeClusterRoleBindings(c, si)).To(HaveLen(1))
+			Expect(meta.IsStatusConditionTrue(si.Status.Conditions, operatorsv1.TypeScoped)).To(BeTrue())
+		})
+
+		It("should bind a write-capable ClusterRole in namespaces", func() {
+			si.Spec.Namespaces = []string{"ns-1"}
+			clusterRole := newTestClusterRole("test")
+			clusterRole.Rules[0].Verbs = []string{"*"}
+			reconcile(clusterRole, "")
+
+			Expect(listFakeRoleBindings(c, "ns-1", si)).To(HaveLen(1))
+		})
+
+		It("should wait for a ClusterRole that does not exist yet", func() {
+			reconcile(newTestClusterRole("unrelated"), "")
+
+			Expect(si.Status.PendingClusterRoles).To(ConsistOf("test"))
+			cond := meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonWaitingForClusterRole))
+		})
+	})
+})
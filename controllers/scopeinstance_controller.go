@@ -18,12 +18,18 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
 	"operator-framework/oria-operator/util"
 
+	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -32,13 +38,19 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
 	apimacherrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -47,24 +59,274 @@ import (
 type ScopeInstanceReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Reader, if set, serves the lists of ScopeInstances, ServiceAccounts
+	// and bindings made to map events and to create or update bindings, such
+	// as from a cache filled from an API server read replica. Every write,
+	// and every other read, goes to Client.
+	Reader client.Reader
+
+	// ScopeTemplateGracePeriod is how long a referenced ScopeTemplate may be
+	// missing before the bindings of a ScopeInstance are deleted. This avoids
+	// a permission outage when a ScopeTemplate is deleted and recreated.
+	ScopeTemplateGracePeriod time.Duration
+
+	// AtomicBindingSwap creates new bindings alongside the existing ones when
+	// the ScopeInstance or ScopeTemplate changes, instead of updating them in
+	// place. Old bindings are only deleted once every new binding has been
+	// created, so subjects never hold a mix of old and new permissions.
+	AtomicBindingSwap bool
+
+	// MaxDeletesPerReconcile is the most bindings a single reconcile may
+	// delete without confirmation. Zero means no limit.
+	MaxDeletesPerReconcile int
+
+	// MaxConcurrentBindingWrites is how many of the RoleBindings of a
+	// ClusterRole a single reconcile creates or updates at once. Zero or one
+	// writes them one at a time.
+	MaxConcurrentBindingWrites int
+
+	// MaxSubjectsPerBinding is the most subjects a single binding may have.
+	// A binding with more is not written, leaving any existing one in place,
+	// and the ScopeInstance is marked TooManySubjects. Zero means no limit.
+	MaxSubjectsPerBinding int
+
+	// NamespaceCreateQPS is how many RoleBindings per second all reconciles
+	// together create in a single namespace, with bursts of up to
+	// NamespaceCreateBurst. Zero does not throttle creates.
+	NamespaceCreateQPS   float32
+	NamespaceCreateBurst int
+
+	// SkipForbiddenNamespaces skips the namespaces in which the operator is
+	// not allowed to write a RoleBinding, recording them in the status,
+	// instead of failing the reconcile.
+	SkipForbiddenNamespaces bool
+
+	// DeletionSafeMode defers the deletion of each binding until two
+	// consecutive reconciles of its ScopeInstance intend it, so that a spec
+	// misread by a single reconcile, such as during an upgrade, deletes
+	// nothing.
+	DeletionSafeMode bool
+
+	// ConsolidateClusterRoleBindings binds every ScopeInstance bound
+	// cluster-wide to the same ClusterRole through a single shared
+	// ClusterRoleBinding holding the subjects of all of them, instead of one
+	// ClusterRoleBinding each. The shared ClusterRoleBinding is deleted once
+	// the last of them is gone.
+	ConsolidateClusterRoleBindings bool
+
+	// CircuitBreakerThreshold is how many consecutive reconciles of a
+	// ScopeInstance may fail before it is only retried every
+	// CircuitBreakerInterval, instead of with the backoff of the workqueue,
+	// until a reconcile succeeds. Zero disables the circuit breaker.
+	CircuitBreakerThreshold int
+	CircuitBreakerInterval  time.Duration
+
+	// DeletePropagation is the propagation policy of every binding deleted
+	// for a ScopeInstance, such as metav1.DeletePropagationOrphan to leave
+	// the dependents of the bindings in place while migrating them. The API
+	// server default is used if it is empty.
+	DeletePropagation metav1.DeletionPropagation
+
+	// StatusDebounce coalesces the status writes of each ScopeInstance: a
+	// status change within StatusDebounce of its last status write is held
+	// back, and the ScopeInstance is requeued to write its then current
+	// status once the window passes. Failed reconciles are always written.
+	// Zero writes every status change right away.
+	StatusDebounce time.Duration
+
+	// SubjectChangeDebounce delays the reconciles caused by ScopeTemplate
+	// updates that only change subjects, so that the subject changes made
+	// within it are written to the bindings in a single update. Zero
+	// reconciles every change right away.
+	SubjectChangeDebounce time.Duration
+
+	// Recorder emits events for the ScopeInstance
+	Recorder record.EventRecorder
+
+	// Heartbeat, if set, is renewed as reconciles complete.
+	Heartbeat *Heartbeat
+
+	// FieldManager is the field manager used for every write, defaulting to
+	// DefaultFieldManager.
+	FieldManager string
+
+	// KeyPrefix is the prefix of the labels and annotations the bookkeeping
+	// of the bindings is kept in, defaulting to DefaultKeyPrefix. It must
+	// pass ValidateKeyPrefix, and MigrateKeys must have moved the keys made
+	// under another prefix before the reconciler starts.
+	KeyPrefix string
+
+	// DefaultScopeTemplate is the name of the ScopeTemplate used by
+	// ScopeInstances that reference none. Such ScopeInstances are inert if it
+	// is empty.
+	DefaultScopeTemplate string
+
+	// RenewalWindow is how long after the time in the renewKey annotation
+	// the bindings of a ScopeInstance expire.
+	RenewalWindow time.Duration
+
+	// ShadowPrefix, when set, prefixes the names of the bindings, the names
+	// of their User and Group subjects and the namespaces of their
+	// ServiceAccount subjects, so that the output of the operator can be
+	// inspected without granting anything to the real subjects. The bindings
+	// are created in the real namespaces, alongside the real ones, and are
+	// told apart by the prefixed UID in their scopeInstanceUIDKey label. It
+	// must pass ValidateShadowPrefix.
+	ShadowPrefix string
+
+	// OperatorServiceAccount is the ServiceAccount the operator runs as.
+	// Bindings whose subjects include it are handled according to
+	// OperatorSubjectPolicy, defaulting to OperatorSubjectReject. The check
+	// is disabled if the name is empty.
+	OperatorServiceAccount types.NamespacedName
+	OperatorSubjectPolicy  OperatorSubjectPolicy
+
+	// GroupSetNamespace is the namespace of the ConfigMaps holding the group
+	// sets referenced by ScopeTemplates. Referencing a group set fails the
+	// reconcile if it is empty.
+	GroupSetNamespace string
+
+	// RulePolicy lists the rules the ClusterRoles of a ScopeTemplate may not
+	// grant. No binding is created or updated for a ScopeTemplate violating
+	// it.
+	RulePolicy RulePolicy
+
+	// ClusterWidePolicy limits the verbs of the ClusterRoles bound
+	// cluster-wide. No binding is created or updated for a ScopeInstance
+	// violating it.
+	ClusterWidePolicy ClusterWidePolicy
+
+	// templateMissingSince records when each ScopeInstance first observed
+	// that its ScopeTemplate was missing.
+	mu                   sync.Mutex
+	templateMissingSince map[string]time.Time
+
+	// selfDeleted records the bindings being deleted by the reconciler, so
+	// that their delete events do not requeue the ScopeInstance. Guarded by mu.
+	selfDeleted sets.String
+
+	// unobservedDeletes records when the reconciler deleted each binding,
+	// until the cache observes the deletion, so that a cache still listing
+	// the binding is not mistaken for the current state. Guarded by mu.
+	unobservedDeletes map[string]time.Time
+
+	// deleteIntents records the deletions deferred by DeletionSafeMode for
+	// each ScopeInstance. Guarded by mu.
+	deleteIntents map[string]*deletionIntents
+
+	// failures counts the consecutive failed reconciles of each
+	// ScopeInstance for the circuit breaker. Guarded by mu.
+	failures map[string]int
+
+	// statusWrittenAt records the last status write of each ScopeInstance
+	// for StatusDebounce. Guarded by mu.
+	statusWrittenAt map[string]time.Time
+
+	// keys maps the bookkeeping keys under KeyPrefix
+	keys *keyMapping
+
+	// bindings caches the bindings found for each ScopeInstance
+	bindings *bindingIndex
+
+	// dirty records the namespaces whose events enqueued each ScopeInstance
+	dirty *dirtyNamespaces
+
+	// priorities releases enqueued ScopeInstances by Spec.Priority
+	priorities *priorityGate
+
+	// subjects delays the reconciles caused by subject changes
+	subjects *subjectDebounce
+
+	// creates throttles the creation of RoleBindings per namespace
+	creates *namespaceRateLimiter
+
+	// scopeTemplatesIndexed is set once the ScopeInstances listed by
+	// mapToScopeInstance are indexed by ScopeTemplate
+	scopeTemplatesIndexed bool
 }
 
+// The keys of the labels and annotations the reconciler keeps its bookkeeping
+// in, under the DefaultKeyPrefix. Its keyMapping moves them under KeyPrefix.
 const (
 	// scopeInstanceUIDKey is used to track "owners" of bindings we create.
 	scopeInstanceUIDKey = "operators.coreos.io/scopeInstanceUID"
 
-	// referenceHashKey is used to track "abandoned" bindings we created.
-	referenceHashKey = "operators.coreos.io/scopeInstanceAndTemplateHash"
+	// referenceHashKey is an annotation used to track "abandoned" bindings we created.
+	referenceHashKey = "operators.coreos.io/scopeInstanceHash"
+
+	// scopeTemplateNameKey is a label on each ScopeInstance naming the ScopeTemplate it references,
+	// so that ScopeInstances can be selected by ScopeTemplate.
+	scopeTemplateNameKey = "operators.coreos.io/scopeTemplate"
+
+	// audienceKey is an annotation on each binding holding the Spec.Audience of its ScopeInstance.
+	audienceKey = "operators.coreos.io/audience"
+
+	// provenanceScopeInstanceKey and provenanceScopeTemplateKey are annotations on each binding
+	// recording, for auditors, the names of the ScopeInstance and ScopeTemplate it was created for.
+	// The hash of their specs it was last written with is the one under referenceHashKey.
+	provenanceScopeInstanceKey = "operators.coreos.io/scopeInstanceName"
+	provenanceScopeTemplateKey = "operators.coreos.io/scopeTemplateName"
 
 	// generateNames are used to track each binding we create for a single scopeTemplate
 	clusterRoleBindingGenerateKey = "operators.coreos.io/generateName"
-	siCtrlFieldOwner              = "scopeinstance-controller"
 )
 
+const (
+	// legacyReferenceHashKey is the label the reference hash was kept in before it moved to the
+	// referenceHashKey annotation. It is removed from each binding by its next reconcile.
+	legacyReferenceHashKey = "operators.coreos.io/scopeInstanceAndTemplateHash"
+
+	// allowBulkDeleteKey is an annotation confirming a deletion that exceeds MaxDeletesPerReconcile.
+	allowBulkDeleteKey = "operators.coreos.io/allowBulkDelete"
+
+	// renewKey is an annotation holding an RFC 3339 timestamp from which ExpiresAt is extended.
+	renewKey = "operators.coreos.io/renew"
+
+	// forceSyncKey is an annotation holding a nonce; changing it rewrites every binding.
+	forceSyncKey = "operators.coreos.io/force-sync"
+
+	// dryRunKey is an annotation previewing the bindings of a ScopeInstance with a server-side dry
+	// run, instead of writing them, when set to "true".
+	dryRunKey = "operators.coreos.io/dry-run"
+
+	// noScopeKey is an annotation excluding a namespace from the RoleBindings of every ScopeInstance
+	// when set to "true". ClusterRoleBindings still grant access in it.
+	noScopeKey = "operators.coreos.io/no-scope"
+
+	// groupSetGroupsKey is the key of the ConfigMap of a group set listing its groups, one per line.
+	groupSetGroupsKey = "groups"
+
+	// clusterRoleRequeueDelay is how long to wait before checking again for
+	// ClusterRoles that have not been created by the ScopeTemplate controller yet.
+	clusterRoleRequeueDelay = 5 * time.Second
+
+	// deletionConfirmationDelay is how long to wait before confirming the
+	// deletions deferred by DeletionSafeMode.
+	deletionConfirmationDelay = 5 * time.Second
+
+	// deletionObservedDelay is how long to wait before checking again that
+	// the cache observed the deletions of the reconciler.
+	deletionObservedDelay = time.Second
+
+	// deletionObservedTimeout is how long a binding deleted by the reconciler
+	// is ignored by its lists if the cache never observes the deletion.
+	deletionObservedTimeout = time.Minute
+)
+
+// DefaultFieldManager is the field manager used when none is configured.
+const DefaultFieldManager = "oria-operator"
+
 //+kubebuilder:rbac:groups=operators.io.operator-framework,resources=scopeinstances,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=operators.io.operator-framework,resources=scopeinstances/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=operators.io.operator-framework,resources=scopeinstances/finalizers,verbs=update
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings;rolebindings,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,verbs=get;list;watch
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles,verbs=bind
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -76,17 +338,58 @@ const (
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.12.1/pkg/reconcile
 func (r *ScopeInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	_ = log.FromContext(ctx)
+	logger := log.FromContext(ctx)
+
+	// The workqueue just handed out this request, let the next one in
+	r.priorities.next()
+	changed := r.dirty.take(req.Name)
+	r.startDeleteIntents(req.Name)
+	defer r.Heartbeat.reconciling()(ctx)
 
 	log.Log.V(2).Info("Reconciling ScopeInstance", "namespaceName", req.NamespacedName)
 
 	existingIn := &operatorsv1.ScopeInstance{}
 	if err := r.Client.Get(ctx, req.NamespacedName, existingIn); err != nil {
+		if k8sapierrors.IsNotFound(err) {
+			r.clearScopeTemplateMissing(req.Name)
+			resolvedNamespaces.DeleteLabelValues(req.Name)
+			r.resetCircuit(req.Name)
+			r.forgetStatusWrite(req.Name)
+			if r.ConsolidateClusterRoleBindings {
+				if err := r.pruneSharedClusterRoleBindings(ctx, req.Name); err != nil {
+					return ctrl.Result{}, err
+				}
+				// A shared ClusterRoleBinding left without owners is only
+				// deleted once the next reconcile confirms it
+				if r.deletionsDeferred(req.Name) {
+					return ctrl.Result{RequeueAfter: deletionConfirmationDelay}, nil
+				}
+			}
+			r.forgetDeleteIntents(req.Name)
+		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	ctx, summary := withReconcileSummary(ctx)
 	reconciledIn := existingIn.DeepCopy()
-	res, reconcileErr := r.reconcile(ctx, reconciledIn)
+	res, reconcileErr := r.reconcile(ctx, reconciledIn, changed)
+	res, reconcileErr = r.circuitBreaker(reconciledIn, res, reconcileErr)
+	updateStatusProgressing(reconciledIn, summary, reconcileErr)
+	if reconcileErr == nil && summary.waitingForCache() {
+		// Only converge once the cache no longer lists deleted bindings
+		res = requeueWithin(res, deletionObservedDelay)
+	}
+
+	// Log a single summary per reconcile, the writes to each binding are
+	// only logged at higher verbosity.
+	keysAndValues := append([]interface{}{"scopeInstance", req.Name}, summary.keysAndValues()...)
+	if cond := meta.FindStatusCondition(reconciledIn.Status.Conditions, operatorsv1.TypeScoped); cond != nil {
+		keysAndValues = append(keysAndValues, "reason", cond.Reason)
+	}
+	if reconcileErr != nil {
+		keysAndValues = append(keysAndValues, "error", reconcileErr.Error())
+	}
+	logger.Info("reconciled ScopeInstance", keysAndValues...)
 
 	// Update the status subresource before updating the main object. This is
 	// necessary because, in many cases, the main object update will remove the
@@ -95,346 +398,2324 @@ func (r *ScopeInstanceReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	// object update to ensure that the status update can be processed before
 	// a potential deletion.
 	if !equality.Semantic.DeepEqual(existingIn.Status, reconciledIn.Status) {
-		if updateErr := r.Client.Status().Update(ctx, reconciledIn); updateErr != nil {
-			return res, apimacherrors.NewAggregate([]error{reconcileErr, updateErr})
+		if wait := r.statusDebounceRemaining(req.Name); wait > 0 && reconcileErr == nil {
+			// The reconcile at the end of the window writes the status
+			// current by then
+			res = requeueWithin(res, wait)
+		} else {
+			if updateErr := r.updateStatus(ctx, reconciledIn); updateErr != nil {
+				return res, apimacherrors.NewAggregate([]error{reconcileErr, updateErr})
+			}
+			r.statusWritten(req.Name)
+			// The status update bumps the resourceVersion, which should not be
+			// mistaken for a change to the main object.
+			existingIn.SetResourceVersion(reconciledIn.GetResourceVersion())
 		}
 	}
-	existingIn.Status, reconciledIn.Status = operatorsv1.ScopeInstanceStatus{}, operatorsv1.ScopeInstanceStatus{}
-	if !equality.Semantic.DeepEqual(existingIn, reconciledIn) {
-		if updateErr := r.Client.Update(ctx, reconciledIn); updateErr != nil {
+	// Compare everything but the status, which was written above.
+	if !equality.Semantic.DeepEqual(existingIn.ObjectMeta, reconciledIn.ObjectMeta) ||
+		!equality.Semantic.DeepEqual(existingIn.Spec, reconciledIn.Spec) {
+		if updateErr := r.Client.Update(ctx, reconciledIn, r.fieldOwner()); updateErr != nil {
 			return res, apimacherrors.NewAggregate([]error{reconcileErr, updateErr})
 		}
 	}
 	return res, reconcileErr
 }
 
-func (r *ScopeInstanceReconciler) reconcile(ctx context.Context, in *operatorsv1.ScopeInstance) (ctrl.Result, error) {
-	// Get the ScopeTemplate referenced by the ScopeInstance
-	st := &operatorsv1.ScopeTemplate{}
-	if err := r.Client.Get(ctx, client.ObjectKey{Name: in.Spec.ScopeTemplateName}, st); err != nil {
-		if !k8sapierrors.IsNotFound(err) {
-			return ctrl.Result{}, err
-		}
-
-		updateStatusScopeTemplateNotFound(in, err)
-
-		// Delete anything owned by the scopeInstance if the scopeTemplate is gone.
-		listOption := client.MatchingLabels{
-			scopeInstanceUIDKey: string(in.GetUID()),
-		}
-
-		if err := r.deleteBindings(ctx, listOption); err != nil {
-			log.Log.V(2).Error(err, "in deleting (Cluster)RoleBindings")
-			updateStatusScopingFailed(in, err)
-			return ctrl.Result{}, err
-		}
+// statusDebounceRemaining returns how much of the StatusDebounce window that
+// started with the last status write of the named ScopeInstance is left.
+func (r *ScopeInstanceReconciler) statusDebounceRemaining(name string) time.Duration {
+	if r.StatusDebounce <= 0 {
+		return 0
+	}
 
-		return ctrl.Result{}, nil
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	writtenAt, ok := r.statusWrittenAt[name]
+	if !ok {
+		return 0
 	}
+	return r.StatusDebounce - time.Since(writtenAt)
+}
 
-	// create required roleBindings and clusterRoleBindings.
-	if err := r.ensureBindings(ctx, in, st); err != nil {
-		log.Log.V(2).Error(err, "in creating (Cluster)RoleBindings")
-		updateStatusScopingFailed(in, err)
-		return ctrl.Result{}, err
+// statusWritten starts the StatusDebounce window of the named ScopeInstance.
+func (r *ScopeInstanceReconciler) statusWritten(name string) {
+	if r.StatusDebounce <= 0 {
+		return
 	}
 
-	// delete out of date (Cluster)RoleBindings
-	if err := r.deleteOldBindings(ctx, in, st); err != nil {
-		log.Log.V(2).Error(err, "in deleting (Cluster)RoleBindings")
-		updateStatusScopingFailed(in, err)
-		return ctrl.Result{}, err
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.statusWrittenAt == nil {
+		r.statusWrittenAt = map[string]time.Time{}
 	}
+	r.statusWrittenAt[name] = time.Now()
+}
 
-	updateStatusScopingSuccessful(in, fmt.Sprintf("ScopeInstance %q reconciled successfully", in.Name))
-	return ctrl.Result{}, nil
+// forgetStatusWrite forgets the last status write of a ScopeInstance that no
+// longer exists.
+func (r *ScopeInstanceReconciler) forgetStatusWrite(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.statusWrittenAt, name)
 }
 
-// ensureBindings will ensure that the proper bindings are created for a
-// given ScopeInstance and ScopeTemplate. If the ScopeInstance.Spec.Namespaces
-// is empty it will create a ClusterRoleBinding. If the
-// ScopeInstance.Spec.Namespaces is not empty it will create a RoleBinding
-// in each provided namespace. A separate (Cluster)RoleBinding will be created
-// for each ClusterRole specified in the ScopeTemplate
-func (r *ScopeInstanceReconciler) ensureBindings(ctx context.Context, in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate) error {
-	for _, cr := range st.Spec.ClusterRoles {
-		if len(in.Spec.Namespaces) == 0 {
-			err := r.createOrUpdateClusterRoleBinding(ctx, &cr, in, st)
-			if err != nil {
-				return err
-			}
-		} else {
-			for _, ns := range in.Spec.Namespaces {
-				err := r.createOrUpdateRoleBinding(ctx, &cr, in, st, ns)
-				if err != nil {
-					return err
-				}
-			}
-		}
+// requeueWithin returns res, requeued after at most wait.
+func requeueWithin(res ctrl.Result, wait time.Duration) ctrl.Result {
+	if res.RequeueAfter == 0 || res.RequeueAfter > wait {
+		res.RequeueAfter = wait
 	}
-
-	return nil
+	return res
 }
 
-func (r *ScopeInstanceReconciler) createOrUpdateClusterRoleBinding(ctx context.Context, cr *operatorsv1.ClusterRoleTemplate, in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate) error {
-	crb := r.clusterRoleBindingManifest(cr, in, st)
-	crbList := &rbacv1.ClusterRoleBindingList{}
-	if err := r.Client.List(ctx, crbList, client.MatchingLabels{
-		scopeInstanceUIDKey:           string(in.GetUID()),
-		clusterRoleBindingGenerateKey: cr.GenerateName,
-	}); err != nil {
-		return err
+// reader returns the Reader, defaulting to the Client.
+func (r *ScopeInstanceReconciler) reader() client.Reader {
+	if r.Reader == nil {
+		return r.Client
 	}
+	return r.Reader
+}
 
-	if len(crbList.Items) > 1 {
-		return fmt.Errorf("more than one ClusterRoleBinding found for ClusterRole %s", cr.GenerateName)
+// fieldOwner returns the field manager used for writes.
+func (r *ScopeInstanceReconciler) fieldOwner() client.FieldOwner {
+	if r.FieldManager == "" {
+		return client.FieldOwner(DefaultFieldManager)
 	}
+	return client.FieldOwner(r.FieldManager)
+}
 
-	// Create the ClusterRoleBinding if one doesn't already exist
-	if len(crbList.Items) == 0 {
-		return r.Client.Create(ctx, crb)
+// scopeTemplateGraceRemaining returns how much of the grace period is left
+// before the bindings of the named ScopeInstance are deleted because its
+// ScopeTemplate is missing. The grace period starts on the first call.
+func (r *ScopeInstanceReconciler) scopeTemplateGraceRemaining(name string) time.Duration {
+	if r.ScopeTemplateGracePeriod <= 0 {
+		return 0
 	}
 
-	existingCRB := &crbList.Items[0]
-	if util.IsOwnedByLabel(existingCRB.DeepCopy(), in) &&
-		reflect.DeepEqual(existingCRB.Subjects, crb.Subjects) &&
-		reflect.DeepEqual(existingCRB.Labels, crb.Labels) {
-		log.Log.V(2).Info("existing ClusterRoleBinding does not need to be updated", "UID", existingCRB.GetUID())
-		return nil
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.templateMissingSince == nil {
+		r.templateMissingSince = map[string]time.Time{}
 	}
-
-	patchObj := r.clusterRoleBindingPatchObj(existingCRB, crb)
-
-	// server-side apply patch
-	if err := r.patchBinding(ctx, patchObj); err != nil {
-		return err
+	since, ok := r.templateMissingSince[name]
+	if !ok {
+		since = time.Now()
+		r.templateMissingSince[name] = since
 	}
-
-	return nil
+	return r.ScopeTemplateGracePeriod - time.Since(since)
 }
 
-func (r *ScopeInstanceReconciler) clusterRoleBindingPatchObj(oldCrb *rbacv1.ClusterRoleBinding, crb *rbacv1.ClusterRoleBinding) *unstructured.Unstructured {
-	return &unstructured.Unstructured{
-		Object: map[string]interface{}{
-			"apiVersion": rbacv1.SchemeGroupVersion.String(),
-			"kind":       "ClusterRoleBinding",
-			"metadata": map[string]interface{}{
-				"name":            oldCrb.Name,
-				"ownerReferences": crb.OwnerReferences,
-				"labels":          crb.Labels,
-			},
-			"subjects": crb.Subjects,
-		},
-	}
+// clearScopeTemplateMissing resets the grace period of the named ScopeInstance.
+func (r *ScopeInstanceReconciler) clearScopeTemplateMissing(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.templateMissingSince, name)
 }
 
-func (r *ScopeInstanceReconciler) createOrUpdateRoleBinding(ctx context.Context, cr *operatorsv1.ClusterRoleTemplate, in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate, namespace string) error {
-	rb := r.roleBindingManifest(cr, in, st, namespace)
-	rbList := &rbacv1.RoleBindingList{}
-	if err := r.Client.List(ctx, rbList, &client.ListOptions{
-		Namespace: namespace,
-	}, client.MatchingLabels{
-		scopeInstanceUIDKey:           string(in.GetUID()),
-		clusterRoleBindingGenerateKey: cr.GenerateName,
-	}); err != nil {
-		return err
+// circuitBreaker counts the consecutive failed reconciles of the
+// ScopeInstance. Once CircuitBreakerThreshold is reached, the error is
+// swallowed and the ScopeInstance is requeued after CircuitBreakerInterval,
+// so that persistent API errors do not keep adding load to the API server.
+// A successful reconcile closes the circuit again.
+func (r *ScopeInstanceReconciler) circuitBreaker(in *operatorsv1.ScopeInstance, res ctrl.Result, err error) (ctrl.Result, error) {
+	if r.CircuitBreakerThreshold <= 0 {
+		return res, err
 	}
-
-	if len(rbList.Items) > 1 {
-		return fmt.Errorf("more than one RoleBinding found for ClusterRole %s", cr.GenerateName)
+	if err == nil {
+		r.resetCircuit(in.GetName())
+		return res, nil
 	}
 
-	// Create the RoleBinding if one doesn't already exist
-	if len(rbList.Items) == 0 {
-		return r.Client.Create(ctx, rb)
+	r.mu.Lock()
+	if r.failures == nil {
+		r.failures = map[string]int{}
+	}
+	r.failures[in.GetName()]++
+	failures := r.failures[in.GetName()]
+	r.mu.Unlock()
+	if failures < r.CircuitBreakerThreshold {
+		return res, err
 	}
 
-	log.Log.V(2).Info("Updating existing rb", "namespaced", rbList.Items[0].GetNamespace(), "name", rbList.Items[0].GetName())
-
-	existingRB := &rbList.Items[0]
+	log.Log.Info("warning: circuit open, retrying less often", "scopeInstance", in.GetName(), "failures", failures, "interval", r.CircuitBreakerInterval, "error", err.Error())
+	updateStatusCircuitOpen(in, failures, r.CircuitBreakerInterval, err)
+	return ctrl.Result{RequeueAfter: r.CircuitBreakerInterval}, nil
+}
 
-	if util.IsOwnedByLabel(existingRB.DeepCopy(), in) &&
-		reflect.DeepEqual(existingRB.Subjects, rb.Subjects) &&
-		reflect.DeepEqual(existingRB.Labels, rb.Labels) {
-		log.Log.V(2).Info("existing RoleBinding does not need to be updated", "UID", existingRB.GetUID())
-		return nil
-	}
+// resetCircuit forgets the failed reconciles of the named ScopeInstance.
+func (r *ScopeInstanceReconciler) resetCircuit(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.failures, name)
+}
 
-	patchObj := r.roleBindingPatchObj(existingRB, rb)
+// updateStatus writes the status of the given ScopeInstance. Since the
+// ScopeInstance may be enqueued by several watches at once, the latest
+// version of the object is fetched before each attempt and the update is
+// retried if it conflicts with another write.
+func (r *ScopeInstanceReconciler) updateStatus(ctx context.Context, in *operatorsv1.ScopeInstance) error {
+	status := in.Status.DeepCopy()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &operatorsv1.ScopeInstance{}
+		if err := r.Client.Get(ctx, client.ObjectKeyFromObject(in), latest); err != nil {
+			return err
+		}
 
-	// server-side apply patch
-	if err := r.patchBinding(ctx, patchObj); err != nil {
-		return err
-	}
+		// Only patch the conditions set by the reconciler onto the latest
+		// ones, so that conditions set by other writers are kept. A merge
+		// patch replaces the whole list, so the patch is only applied to the
+		// version it was computed from.
+		patched := latest.DeepCopy()
+		for _, condition := range status.Conditions {
+			meta.SetStatusCondition(&patched.Status.Conditions, condition)
+		}
+		patched.Status.TerminatingNamespaces = status.TerminatingNamespaces
+		patched.Status.ForbiddenNamespaces = status.ForbiddenNamespaces
+		patched.Status.ObservedForceSync = status.ObservedForceSync
+		patched.Status.GeneratedBindings = status.GeneratedBindings
+		patched.Status.Namespaces = status.Namespaces
+		patched.Status.DryRunDiscrepancies = status.DryRunDiscrepancies
+		patched.Status.PendingClusterRoles = status.PendingClusterRoles
+		patch := client.MergeFromWithOptions(latest, client.MergeFromWithOptimisticLock{})
+		if err := r.Client.Status().Patch(ctx, patched, patch, r.fieldOwner()); err != nil {
+			return err
+		}
 
-	return nil
+		// Keep the resourceVersion current so subsequent updates to the
+		// ScopeInstance do not conflict with the status write.
+		in.SetResourceVersion(patched.GetResourceVersion())
+		return nil
+	})
 }
 
-func (r *ScopeInstanceReconciler) roleBindingPatchObj(oldRb *rbacv1.RoleBinding, rb *rbacv1.RoleBinding) *unstructured.Unstructured {
-	return &unstructured.Unstructured{
-		Object: map[string]interface{}{
-			"apiVersion": rbacv1.SchemeGroupVersion.String(),
-			"kind":       "RoleBinding",
-			"metadata": map[string]interface{}{
-				"name":            oldRb.Name,
-				"namespace":       oldRb.Namespace,
-				"ownerReferences": rb.OwnerReferences,
-				"labels":          rb.Labels,
-			},
-			"subjects": rb.Subjects,
-		},
+// reconcile reconciles every binding of the ScopeInstance or, if it was only
+// enqueued by the events of the changed namespaces, the RoleBindings of those
+// namespaces.
+func (r *ScopeInstanceReconciler) reconcile(ctx context.Context, in *operatorsv1.ScopeInstance, changed sets.String) (ctrl.Result, error) {
+	setScopeTemplateLabel(r.keys, in, r.DefaultScopeTemplate)
+	// Objects admitted without the defaulting webhook, or before its
+	// defaults changed, are defaulted here so that they converge to the
+	// current defaults.
+	in.Default()
+	r.renew(in)
+
+	// The labels made under the DefaultKeyPrefix are moved under KeyPrefix,
+	// those of the bindings were by MigrateKeys.
+	inLabels, _ := r.keys.migratedKeys(in.GetLabels())
+	in.SetLabels(inLabels)
+
+	// Delete anything owned by the scopeInstance once it has expired. The
+	// Expired condition is replaced if the deletion fails or is guarded.
+	if in.Spec.ExpiresAt != nil && !time.Now().Before(in.Spec.ExpiresAt.Time) {
+		updateStatusExpired(in)
+		return r.deleteAllBindings(ctx, in)
 	}
-}
 
-func (r *ScopeInstanceReconciler) patchBinding(ctx context.Context, binding client.Object) error {
-	return r.Client.Patch(ctx,
-		binding,
-		client.Apply,
-		client.FieldOwner(siCtrlFieldOwner),
-		client.ForceOwnership)
-}
+	// Get the ScopeTemplate referenced by the ScopeInstance, or the one it
+	// pins by UID if it still exists
+	st, err := scopeTemplateByUID(ctx, r.Client, in)
+	if err != nil {
+		updateStatusScopingFailed(in, err)
+		return ctrl.Result{}, err
+	}
+	if st == nil {
+		st = &operatorsv1.ScopeTemplate{}
+		err = r.Client.Get(ctx, scopeTemplateKey(in, r.DefaultScopeTemplate), st)
+	}
+	if err != nil {
+		if !k8sapierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
 
-// TODO: use a client.DeleteAllOf instead of a client.List -> delete
-func (r *ScopeInstanceReconciler) deleteBindings(ctx context.Context, listOptions ...client.ListOption) error {
-	clusterRoleBindings := &rbacv1.ClusterRoleBindingList{}
-	if err := r.Client.List(ctx, clusterRoleBindings, listOptions...); err != nil {
-		// TODO: Aggregate errors
-		return err
+		updateStatusScopeTemplateNotFound(in, scopeTemplateKey(in, r.DefaultScopeTemplate), err)
+
+		// Wait for the grace period to pass in case the ScopeTemplate is recreated.
+		if remaining := r.scopeTemplateGraceRemaining(in.GetName()); remaining > 0 {
+			log.Log.V(2).Info("ScopeTemplate not found, delaying (Cluster)RoleBinding deletion", "scopeTemplate", scopeTemplateKey(in, r.DefaultScopeTemplate), "remaining", remaining)
+			return ctrl.Result{RequeueAfter: remaining}, nil
+		}
+
+		// Delete anything owned by the scopeInstance if the scopeTemplate is gone.
+		return r.deleteAllBindings(ctx, in)
+	}
+	r.clearScopeTemplateMissing(in.GetName())
+	updateStatusTemplateHealth(in, st)
+
+	// The ClusterRoles selected by label are bound as if they were listed in
+	// the ScopeTemplate.
+	st, err = r.withSelectedClusterRoles(ctx, st)
+	if errors.Is(err, errInvalidClusterRoleSelector) {
+		updateStatusInvalidClusterRoleSelector(in, err)
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		log.Log.V(2).Error(err, "in listing selected ClusterRoles")
+		updateStatusScopingFailed(in, err)
+		return ctrl.Result{}, err
 	}
 
-	for _, crb := range clusterRoleBindings.Items {
-		// TODO: Aggregate errors
-		if err := r.Client.Delete(ctx, &crb); err != nil && !k8sapierrors.IsNotFound(err) {
-			return err
+	// The ClusterRoles of a ScopeTemplate violating the policy are not
+	// created, and fixing the ScopeTemplate requeues the ScopeInstance. The
+	// rules of the other bound ClusterRoles are not watched, a ScopeInstance
+	// binding one that violates the policy is checked again by its next
+	// reconcile.
+	if err := r.RulePolicy.checkBound(ctx, r.Client, in, st); err != nil {
+		if !errors.Is(err, errPolicyViolation) {
+			log.Log.V(2).Error(err, "in getting ClusterRoles")
+			updateStatusScopingFailed(in, err)
+			return ctrl.Result{}, err
 		}
+		updateStatusPolicyViolation(in, err)
+		return ctrl.Result{}, nil
 	}
 
-	roleBindings := &rbacv1.RoleBindingList{}
-	if err := r.Client.List(ctx, roleBindings, listOptions...); err != nil {
-		// TODO: Aggregate errors
-		return err
+	// Avoid creating bindings that reference ClusterRoles the ScopeTemplate
+	// controller has not created yet. They are withheld by ensureBindings
+	// while the bindings of the other ClusterRoles are written.
+	missing, err := r.missingClusterRoles(ctx, in, st)
+	if err != nil {
+		log.Log.V(2).Error(err, "in getting ClusterRoles")
+		updateStatusScopingFailed(in, err)
+		return ctrl.Result{}, err
 	}
+	in.Status.PendingClusterRoles = missing
 
-	for _, rb := range roleBindings.Items {
-		// TODO: Aggregate errors
-		if err := r.Client.Delete(ctx, &rb); err != nil && !k8sapierrors.IsNotFound(err) {
-			return err
+	// The rules of ClusterRoles are not watched, a ScopeInstance violating
+	// the policy is checked again by its next reconcile.
+	if err := r.ClusterWidePolicy.check(ctx, r.Client, in, st); err != nil {
+		if !errors.Is(err, errClusterWideViolation) {
+			log.Log.V(2).Error(err, "in getting ClusterRoles")
+			updateStatusScopingFailed(in, err)
+			return ctrl.Result{}, err
 		}
+		updateStatusClusterWideViolation(in, err)
+		return ctrl.Result{}, nil
 	}
 
-	return nil
-}
-
-// deleteOldBindings will delete any (Cluster)RoleBindings that are owned by
-// the given ScopeInstance and are no longer up to date.Being out of date
-// means the combined hash of ScopeInstance.Spec and ScopeTemplate.Spec is different
-func (r *ScopeInstanceReconciler) deleteOldBindings(ctx context.Context, in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate) error {
-	combinedHash := hashScopeInstanceAndTemplate(in, st)
-	hashReq, err := labels.NewRequirement(referenceHashKey, selection.NotEquals, []string{combinedHash})
+	namespaces, err := r.resolveNamespaces(ctx, in)
 	if err != nil {
-		return err
+		log.Log.V(2).Error(err, "in resolving namespaces")
+		updateStatusScopingFailed(in, err)
+		return ctrl.Result{}, err
 	}
-
-	siUIDReq, err := labels.NewRequirement(scopeInstanceUIDKey, selection.Equals, []string{string(in.GetUID())})
+	terminating, excluded, err := r.namespaceStates(ctx)
 	if err != nil {
-		return err
+		log.Log.V(2).Error(err, "in listing terminating namespaces")
+		updateStatusScopingFailed(in, err)
+		return ctrl.Result{}, err
 	}
-
-	listOptions := &client.ListOptions{
-		LabelSelector: labels.NewSelector().Add(*hashReq, *siUIDReq),
+	// The RoleBindings in excluded namespaces are deleted below like those in
+	// namespaces that are no longer selected
+	namespaces = withoutNamespaces(namespaces, excluded)
+	resolvedNamespaces.WithLabelValues(in.GetName()).Set(float64(sets.NewString(namespaces...).Len()))
+
+	// A ScopeInstance annotated for a dry run only previews its bindings
+	if isDryRun(in) {
+		if err := r.dryRunBindings(ctx, in, st, namespaces, excluded); err != nil {
+			r.reportBindingError(in, "in dry running (Cluster)RoleBindings", err)
+			updateStatusScopingFailed(in, err)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
 	}
+	in.Status.DryRunDiscrepancies = nil
 
-	if err := r.deleteBindings(ctx, listOptions); err != nil {
-		return err
+	if changed != nil && r.canReconcileNamespaces(in) {
+		return r.reconcileNamespaces(ctx, in, st, namespaces, terminating, changed)
 	}
 
-	return nil
-}
-
-// SetupWithManager sets up the controller with the Manager.
-func (r *ScopeInstanceReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&operatorsv1.ScopeInstance{}).
-		Watches(&source.Kind{Type: &operatorsv1.ScopeTemplate{}}, handler.EnqueueRequestsFromMapFunc(r.mapToScopeInstance)).
-		Owns(&rbacv1.ClusterRoleBinding{}).
-		Owns(&rbacv1.RoleBinding{}).
-		Complete(r)
-}
-
-func (r *ScopeInstanceReconciler) mapToScopeInstance(obj client.Object) (requests []reconcile.Request) {
-	if obj == nil || obj.GetName() == "" {
-		return nil
+	// create required roleBindings and clusterRoleBindings. Nothing is
+	// deleted below unless every binding was created or updated, so a
+	// partial failure leaves the old bindings in place.
+	if err := r.ensureBindings(ctx, in, st, namespaces, terminating, excluded); err != nil {
+		return r.ensureBindingsFailed(in, err)
 	}
 
-	// Requeue all Scope Instance in the resource namespace
-	ctx := context.TODO()
-	scopeInstanceList := &operatorsv1.ScopeInstanceList{}
-
-	if err := r.Client.List(ctx, scopeInstanceList); err != nil {
-		log.Log.Error(err, "error listing scopeinstances")
-		return nil
+	// delete out of date (Cluster)RoleBindings, including RoleBindings in
+	// namespaces that are no longer selected. The RoleBindings in forbidden
+	// namespaces could not be deleted either, so they are left like those in
+	// terminating namespaces.
+	skipped := terminating.Union(sets.NewString(in.Status.ForbiddenNamespaces...))
+	oldBindings, err := r.oldBindings(ctx, in, st, namespaces, skipped, excluded)
+	if err != nil {
+		log.Log.V(2).Error(err, "in listing (Cluster)RoleBindings")
+		updateStatusScopingFailed(in, err)
+		return ctrl.Result{}, err
 	}
-
-	for _, si := range scopeInstanceList.Items {
-		if si.Spec.ScopeTemplateName != obj.GetName() {
-			continue
-		}
-
-		request := reconcile.Request{
-			NamespacedName: types.NamespacedName{Namespace: si.GetNamespace(), Name: si.GetName()},
+	if r.deletionGuardTripped(in, len(oldBindings)) {
+		return ctrl.Result{}, nil
+	}
+	oldBindings, _ = r.confirmedDeletes(in.GetName(), oldBindings)
+	if err := r.deleteBindings(ctx, oldBindings); err != nil {
+		r.reportBindingError(in, "in deleting (Cluster)RoleBindings", err)
+		updateStatusScopingFailed(in, err)
+		return ctrl.Result{}, err
+	}
+	if r.ConsolidateClusterRoleBindings {
+		if err := r.leaveSharedClusterRoleBindings(ctx, in, r.sharedClusterRoleBindingNames(in, st)); err != nil {
+			r.reportBindingError(in, "in leaving shared ClusterRoleBindings", err)
+			updateStatusScopingFailed(in, err)
+			return ctrl.Result{}, err
 		}
-		requests = append(requests, request)
 	}
 
-	return
-}
+	in.Status.ObservedForceSync = in.GetAnnotations()[forceSyncKey]
+	in.Status.GeneratedBindings = generatedBindings(in, st)
+	updateStatusScopingSuccessful(in, fmt.Sprintf("ScopeInstance %q reconciled successfully", in.Name))
 
-// clusterRoleBindingManifest will create a ClusterRoleBinding from a
-// ClusterRoleTemplate, ScopeInstance, and ScopeTemplate
-func (r *ScopeInstanceReconciler) clusterRoleBindingManifest(cr *operatorsv1.ClusterRoleTemplate, in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate) *rbacv1.ClusterRoleBinding {
-	crb := &rbacv1.ClusterRoleBinding{
-		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: cr.GenerateName + "-",
-			Labels: map[string]string{
-				scopeInstanceUIDKey:           string(in.GetUID()),
-				referenceHashKey:              hashScopeInstanceAndTemplate(in, st),
-				clusterRoleBindingGenerateKey: cr.GenerateName,
-			},
-		},
-		Subjects: cr.Subjects,
-		RoleRef: rbacv1.RoleRef{
-			Kind:     "ClusterRole",
-			Name:     cr.GenerateName,
-			APIGroup: rbacv1.GroupName,
-		},
+	// Replaced bindings and shared ClusterRoleBindings left without owners
+	// may have been deferred as well as the old ones
+	if r.deletionsDeferred(in.GetName()) {
+		return ctrl.Result{RequeueAfter: deletionConfirmationDelay}, nil
 	}
 
-	err := ctrl.SetControllerReference(in, crb, r.Scheme)
-	if err != nil {
-		log.Log.Error(err, "setting controller reference for ClusterRoleBinding")
+	// Come back to delete the bindings once they expire
+	if in.Spec.ExpiresAt != nil {
+		return ctrl.Result{RequeueAfter: time.Until(in.Spec.ExpiresAt.Time)}, nil
 	}
-	return crb
+	return ctrl.Result{}, nil
 }
 
-// roleBindingManifest will create a RoleBinding from a
-// ClusterRoleTemplate, ScopeInstance, ScopeTemplate, and namespace
-func (r *ScopeInstanceReconciler) roleBindingManifest(cr *operatorsv1.ClusterRoleTemplate, in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate, namespace string) *rbacv1.RoleBinding {
-	rb := &rbacv1.RoleBinding{
-		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: cr.GenerateName + "-",
-			Namespace:    namespace,
+// deleteAllBindings deletes every binding of the ScopeInstance and leaves the
+// shared ClusterRoleBindings it joined, through deletionGuardTripped and
+// confirmedDeletes, clearing its GeneratedBindings and Namespaces once done.
+func (r *ScopeInstanceReconciler) deleteAllBindings(ctx context.Context, in *operatorsv1.ScopeInstance) (ctrl.Result, error) {
+	bindings, err := r.listBindingsToDelete(ctx, func(client.Object) bool { return true }, client.MatchingLabels{
+		r.keys.key(scopeInstanceUIDKey): r.bindingOwner(in),
+	})
+	if err != nil {
+		log.Log.V(2).Error(err, "in listing (Cluster)RoleBindings")
+		updateStatusScopingFailed(in, err)
+		return ctrl.Result{}, err
+	}
+	if r.deletionGuardTripped(in, len(bindings)) {
+		return ctrl.Result{}, nil
+	}
+	bindings, _ = r.confirmedDeletes(in.GetName(), bindings)
+	if err := r.deleteBindings(ctx, bindings); err != nil {
+		r.reportBindingError(in, "in deleting (Cluster)RoleBindings", err)
+		updateStatusScopingFailed(in, err)
+		return ctrl.Result{}, err
+	}
+	if err := r.leaveAllSharedClusterRoleBindings(ctx, in); err != nil {
+		r.reportBindingError(in, "in leaving shared ClusterRoleBindings", err)
+		updateStatusScopingFailed(in, err)
+		return ctrl.Result{}, err
+	}
+	// The shared ClusterRoleBindings left without owners may be deferred too
+	if r.deletionsDeferred(in.GetName()) {
+		return ctrl.Result{RequeueAfter: deletionConfirmationDelay}, nil
+	}
+
+	in.Status.GeneratedBindings = nil
+	in.Status.Namespaces = nil
+	return ctrl.Result{}, nil
+}
+
+// ensureBindingsFailed reports an error creating or updating the bindings of
+// the ScopeInstance in its status.
+func (r *ScopeInstanceReconciler) ensureBindingsFailed(in *operatorsv1.ScopeInstance, err error) (ctrl.Result, error) {
+	// An invalid expression needs the ScopeTemplate to be fixed, retrying will not help
+	if errors.Is(err, errInvalidSubjectExpression) {
+		updateStatusInvalidSubjectExpression(in, err)
+		return ctrl.Result{}, nil
+	}
+	// Granting to the operator itself needs the subjects to be changed
+	if errors.Is(err, errOperatorSubject) {
+		updateStatusOperatorSubject(in, err)
+		return ctrl.Result{}, nil
+	}
+	// The withheld bindings are written once their ClusterRoles exist
+	if errors.Is(err, errClusterRolesPending) {
+		updateStatusWaitingForClusterRole(in, in.Status.PendingClusterRoles)
+		return ctrl.Result{RequeueAfter: clusterRoleRequeueDelay}, nil
+	}
+	// Too many subjects need the ScopeTemplate to be fixed, retrying will
+	// only help the bindings withheld for pending ClusterRoles
+	if errors.Is(err, errTooManySubjects) {
+		updateStatusTooManySubjects(in, err)
+		if len(in.Status.PendingClusterRoles) > 0 {
+			return ctrl.Result{RequeueAfter: clusterRoleRequeueDelay}, nil
+		}
+		return ctrl.Result{}, nil
+	}
+	// The guard is applied to the largest deletion once the concurrent writes
+	// are done, consuming a confirmation at most once
+	extra := 0
+	for _, dup := range duplicateBindingsErrors(err) {
+		if dup.extra > extra {
+			extra = dup.extra
+		}
+	}
+	if r.deletionGuardTripped(in, extra) {
+		return ctrl.Result{}, nil
+	}
+	if errors.Is(err, errDuplicateBindings) {
+		updateStatusDuplicateBindings(in, err)
+		return ctrl.Result{Requeue: true}, nil
+	}
+	// An invalid template needs the ScopeInstance to be fixed
+	if errors.Is(err, errInvalidBindingNameTemplate) {
+		updateStatusInvalidBindingNameTemplate(in, err)
+		return ctrl.Result{}, nil
+	}
+	// A name taken by another RoleBinding may be released, keep retrying
+	if errors.Is(err, errBindingNameCollision) {
+		r.reportBindingError(in, "in creating (Cluster)RoleBindings", err)
+		updateStatusBindingNameCollision(in, err)
+		return ctrl.Result{}, err
+	}
+	r.reportBindingError(in, "in creating (Cluster)RoleBindings", err)
+	updateStatusScopingFailed(in, err)
+	return ctrl.Result{}, err
+}
+
+// canReconcileNamespaces returns true if the RoleBindings of the ScopeInstance
+// in a single namespace can be reconciled on their own. This requires the
+// last reconcile to have succeeded, and rules out ScopeInstances whose
+// bindings do not depend on the selected namespaces alone.
+func (r *ScopeInstanceReconciler) canReconcileNamespaces(in *operatorsv1.ScopeInstance) bool {
+	return meta.IsStatusConditionTrue(in.Status.Conditions, operatorsv1.TypeScoped) &&
+		!forceSync(in) &&
+		!isClusterScoped(in) &&
+		!in.Spec.BindInSubjectNamespaces &&
+		!r.AtomicBindingSwap
+}
+
+// reconcileNamespaces creates, updates or deletes the RoleBindings of the
+// ScopeInstance in the changed namespaces only, depending on whether they
+// are still selected. Every other binding is left as the last full reconcile
+// left it.
+func (r *ScopeInstanceReconciler) reconcileNamespaces(ctx context.Context, in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate, namespaces []string, terminating, changed sets.String) (ctrl.Result, error) {
+	selected := sets.NewString(namespaces...)
+	reconcileSummaryFrom(ctx).setNamespaces(selected.Len())
+	in.Status.Namespaces = nil
+	if selected.Len() > 0 {
+		in.Status.Namespaces = selected.List()
+	}
+	forbidden := sets.NewString(in.Status.ForbiddenNamespaces...)
+	defer func() {
+		in.Status.ForbiddenNamespaces = nil
+		if forbidden.Len() > 0 {
+			in.Status.ForbiddenNamespaces = forbidden.List()
+		}
+	}()
+
+	unselected := sets.NewString()
+	var bound []string
+	for _, ns := range changed.List() {
+		if terminating.Has(ns) {
+			continue
+		}
+		forbidden.Delete(ns)
+		if !selected.Has(ns) {
+			unselected.Insert(ns)
+			continue
+		}
+		bound = append(bound, ns)
+	}
+
+	if len(bound) > 0 {
+		desired, err := r.desiredBindings(ctx, in, st, bound, nil)
+		if err != nil {
+			return r.ensureBindingsFailed(in, err)
+		}
+		desired, tooManySubjects := r.withinSubjectLimit(desired)
+		desired, pending := withoutPendingClusterRoles(r.keys, in, desired)
+		for _, binding := range desired {
+			// The ClusterRoleBindings do not depend on the namespaces
+			rb, ok := binding.(*rbacv1.RoleBinding)
+			if !ok || forbidden.Has(rb.Namespace) {
+				continue
+			}
+			err := r.createOrUpdateRoleBinding(ctx, rb, in)
+			if r.SkipForbiddenNamespaces && k8sapierrors.IsForbidden(err) {
+				log.Log.V(1).Info("warning: skipping namespace, writing the RoleBinding is forbidden", "namespace", rb.Namespace, "error", err.Error())
+				forbidden.Insert(rb.Namespace)
+				continue
+			}
+			if err != nil {
+				return r.ensureBindingsFailed(in, err)
+			}
+		}
+		if err := withheldBindingsError(pending, tooManySubjects); err != nil {
+			return r.ensureBindingsFailed(in, err)
+		}
+	}
+
+	oldBindings, err := r.listBindingsToDelete(ctx, func(binding client.Object) bool {
+		_, isRoleBinding := binding.(*rbacv1.RoleBinding)
+		return isRoleBinding && unselected.Has(binding.GetNamespace())
+	}, client.MatchingLabels{
+		r.keys.key(scopeInstanceUIDKey): r.bindingOwner(in),
+	})
+	if err != nil {
+		log.Log.V(2).Error(err, "in listing (Cluster)RoleBindings")
+		updateStatusScopingFailed(in, err)
+		return ctrl.Result{}, err
+	}
+	if r.deletionGuardTripped(in, len(oldBindings)) {
+		return ctrl.Result{}, nil
+	}
+	oldBindings, _ = r.confirmedDeletes(in.GetName(), oldBindings)
+	if err := r.deleteBindings(ctx, oldBindings); err != nil {
+		r.reportBindingError(in, "in deleting (Cluster)RoleBindings", err)
+		updateStatusScopingFailed(in, err)
+		return ctrl.Result{}, err
+	}
+
+	updateStatusScopingSuccessful(in, fmt.Sprintf("ScopeInstance %q reconciled successfully", in.Name))
+	if r.deletionsDeferred(in.GetName()) {
+		return ctrl.Result{RequeueAfter: deletionConfirmationDelay}, nil
+	}
+	if in.Spec.ExpiresAt != nil {
+		return ctrl.Result{RequeueAfter: time.Until(in.Spec.ExpiresAt.Time)}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// forceSync returns true if the forceSyncKey annotation of the ScopeInstance
+// changed since its bindings were last reconciled successfully. Every binding
+// is then listed and written again, even if it looks up to date, to recover
+// from drift the watches missed.
+func forceSync(in *operatorsv1.ScopeInstance) bool {
+	return in.GetAnnotations()[forceSyncKey] != in.Status.ObservedForceSync
+}
+
+// renew consumes the renewKey annotation, extending ExpiresAt to the
+// RenewalWindow after the time it holds. Renewals dated in the future count
+// from now, and a renewal never brings ExpiresAt forward.
+func (r *ScopeInstanceReconciler) renew(in *operatorsv1.ScopeInstance) {
+	value, ok := in.GetAnnotations()[renewKey]
+	if !ok {
+		return
+	}
+	delete(in.Annotations, renewKey)
+
+	renewedAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.Log.Info("warning: ignoring invalid renewal", "scopeInstance", in.GetName(), "renew", value, "error", err.Error())
+		return
+	}
+	if now := time.Now(); renewedAt.After(now) {
+		renewedAt = now
+	}
+
+	expiresAt := metav1.NewTime(renewedAt.Add(r.RenewalWindow))
+	if in.Spec.ExpiresAt == nil || in.Spec.ExpiresAt.Before(&expiresAt) {
+		in.Spec.ExpiresAt = &expiresAt
+	}
+}
+
+// ensureBindings will ensure that the proper bindings are created for a
+// given ScopeInstance and ScopeTemplate, creating or updating each binding
+// returned by desiredBindings once the ServiceAccountSelectors are resolved.
+// Terminating namespaces are skipped and recorded in the status, as creating
+// bindings in them fails. So are namespaces in which writing a RoleBinding
+// is forbidden if SkipForbiddenNamespaces is set.
+func (r *ScopeInstanceReconciler) ensureBindings(ctx context.Context, in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate, namespaces []string, terminating, excluded sets.String) error {
+	// A namespace listed twice would otherwise be bound twice
+	namespaces = sets.NewString(namespaces...).List()
+	reconcileSummaryFrom(ctx).setNamespaces(len(namespaces))
+	in.Status.Namespaces = nil
+	if len(namespaces) > 0 {
+		in.Status.Namespaces = namespaces
+	}
+
+	skipped := sets.NewString()
+	var forbiddenMu sync.Mutex
+	forbidden := sets.NewString()
+	defer func() {
+		in.Status.TerminatingNamespaces = nil
+		if skipped.Len() > 0 {
+			in.Status.TerminatingNamespaces = skipped.List()
+		}
+		in.Status.ForbiddenNamespaces = nil
+		if forbidden.Len() > 0 {
+			in.Status.ForbiddenNamespaces = forbidden.List()
+		}
+	}()
+
+	desired, err := r.desiredBindings(ctx, in, st, namespaces, excluded)
+	if err != nil {
+		return err
+	}
+	// The other bindings are still written, but nothing is deleted
+	desired, tooManySubjects := r.withinSubjectLimit(desired)
+	desired, pending := withoutPendingClusterRoles(r.keys, in, desired)
+	r.logReconcilePlan(ctx, in, desired)
+
+	for i := 0; i < len(desired); {
+		if crb, ok := desired[i].(*rbacv1.ClusterRoleBinding); ok {
+			i++
+			if r.ConsolidateClusterRoleBindings {
+				if err := r.joinSharedClusterRoleBinding(ctx, crb, in); err != nil {
+					return err
+				}
+			} else if err := r.createOrUpdateClusterRoleBinding(ctx, crb, in); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Write the RoleBindings of a single entry of the ScopeTemplate
+		// concurrently
+		var rbs []*rbacv1.RoleBinding
+		generateName := desired[i].GetLabels()[r.keys.key(clusterRoleBindingGenerateKey)]
+		for ; i < len(desired); i++ {
+			rb, ok := desired[i].(*rbacv1.RoleBinding)
+			if !ok || rb.Labels[r.keys.key(clusterRoleBindingGenerateKey)] != generateName {
+				break
+			}
+			if terminating.Has(rb.Namespace) {
+				skipped.Insert(rb.Namespace)
+				continue
+			}
+			rbs = append(rbs, rb)
+		}
+		if err := forEachBounded(len(rbs), r.MaxConcurrentBindingWrites, func(i int) error {
+			err := r.createOrUpdateRoleBinding(ctx, rbs[i], in)
+			if r.SkipForbiddenNamespaces && k8sapierrors.IsForbidden(err) {
+				log.Log.V(1).Info("warning: skipping namespace, writing the RoleBinding is forbidden", "namespace", rbs[i].Namespace, "error", err.Error())
+				forbiddenMu.Lock()
+				forbidden.Insert(rbs[i].Namespace)
+				forbiddenMu.Unlock()
+				return nil
+			}
+			return err
+		}); err != nil {
+			return err
+		}
+	}
+
+	return withheldBindingsError(pending, tooManySubjects)
+}
+
+// createOrUpdateClusterRoleBinding creates the desired ClusterRoleBinding, or
+// updates the one found for its entry of the ScopeTemplate.
+func (r *ScopeInstanceReconciler) createOrUpdateClusterRoleBinding(ctx context.Context, crb *rbacv1.ClusterRoleBinding, in *operatorsv1.ScopeInstance) error {
+	if err := r.checkOperatorSubjects(in, crb.Subjects); err != nil {
+		return err
+	}
+	crbList := &rbacv1.ClusterRoleBindingList{}
+	key := bindingIndexKey{
+		kind:             "ClusterRoleBinding",
+		scopeInstanceUID: r.bindingOwner(in),
+		generateName:     crb.Labels[r.keys.key(clusterRoleBindingGenerateKey)],
+	}
+	if cached, ok := r.bindings.get(key); ok && !forceSync(in) {
+		crbList.Items = []rbacv1.ClusterRoleBinding{*cached.(*rbacv1.ClusterRoleBinding)}
+	} else {
+		if err := r.reader().List(ctx, crbList, client.MatchingLabels{
+			r.keys.key(scopeInstanceUIDKey):           r.bindingOwner(in),
+			r.keys.key(clusterRoleBindingGenerateKey): crb.Labels[r.keys.key(clusterRoleBindingGenerateKey)],
+		}); err != nil {
+			return newBindingError("list", crb, err)
+		}
+		crbList.Items = r.observedClusterRoleBindings(ctx, crbList.Items)
+		if len(crbList.Items) == 1 {
+			r.bindings.set(&crbList.Items[0])
+		}
+	}
+
+	if r.AtomicBindingSwap {
+		crbList.Items = currentClusterRoleBindings(r.keys, crbList.Items, crb.Annotations[r.keys.key(referenceHashKey)])
+	}
+
+	if len(crbList.Items) > 1 {
+		duplicates := make([]client.Object, 0, len(crbList.Items))
+		for i := range crbList.Items {
+			duplicates = append(duplicates, &crbList.Items[i])
+		}
+		return r.removeDuplicateBindings(ctx, in, crb, duplicates)
+	}
+
+	// Create the ClusterRoleBinding if one doesn't already exist
+	if len(crbList.Items) == 0 {
+		err := r.Client.Create(ctx, crb, r.fieldOwner())
+		countBindingOperation("ClusterRoleBinding", opCreate, err)
+		if err != nil {
+			return newBindingError("create", crb, err)
+		}
+		r.bindings.invalidate(crb)
+		bindingsCreated.WithLabelValues("ClusterRoleBinding").Inc()
+		reconcileSummaryFrom(ctx).bindingCreated()
+		return nil
+	}
+
+	existingCRB := &crbList.Items[0]
+	if existingCRB.RoleRef != crb.RoleRef {
+		return r.replaceBinding(ctx, in, existingCRB, crb)
+	}
+	if err := r.removeLegacyHashLabel(ctx, existingCRB); err != nil {
+		return newBindingError("update", existingCRB, err)
+	}
+	if !forceSync(in) &&
+		util.IsOwnedByLabel(existingCRB.DeepCopy(), in, r.keys.key(scopeInstanceUIDKey)) &&
+		equalSubjects(existingCRB.Subjects, crb.Subjects) &&
+		hasLabels(existingCRB, crb.Labels) &&
+		hasAnnotations(existingCRB, crb.Annotations) {
+		log.Log.V(2).Info("existing ClusterRoleBinding does not need to be updated", "UID", existingCRB.GetUID())
+		return nil
+	}
+
+	patchObj := r.clusterRoleBindingPatchObj(existingCRB, crb)
+	r.bindings.invalidate(existingCRB)
+
+	// server-side apply patch
+	if err := r.patchBinding(ctx, patchObj); err != nil {
+		return newBindingError("update", patchObj, err)
+	}
+
+	return nil
+}
+
+// equalSubjects returns true if both lists hold the same subjects, in any
+// order.
+func equalSubjects(a, b []rbacv1.Subject) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	counts := map[rbacv1.Subject]int{}
+	for _, subject := range a {
+		counts[subject]++
+	}
+	for _, subject := range b {
+		if counts[subject] == 0 {
+			return false
+		}
+		counts[subject]--
+	}
+	return true
+}
+
+// hasLabels returns true if obj has every one of the given labels. Labels
+// added by others are ignored, as the apply patch leaves them in place.
+func hasLabels(obj client.Object, want map[string]string) bool {
+	return labels.SelectorFromSet(want).Matches(labels.Set(obj.GetLabels()))
+}
+
+// hasAnnotations returns true if obj has every one of the given annotations,
+// such as those recording its provenance, which bindings created before they
+// were added lack.
+func hasAnnotations(obj client.Object, want map[string]string) bool {
+	annotations := obj.GetAnnotations()
+	for key, value := range want {
+		if current, ok := annotations[key]; !ok || current != value {
+			return false
+		}
+	}
+	return true
+}
+
+// currentClusterRoleBindings returns the ClusterRoleBindings with the given
+// reference hash, leaving out old bindings that are awaiting deletion.
+func currentClusterRoleBindings(keys *keyMapping, crbs []rbacv1.ClusterRoleBinding, hash string) []rbacv1.ClusterRoleBinding {
+	var current []rbacv1.ClusterRoleBinding
+	for _, crb := range crbs {
+		if crb.Annotations[keys.key(referenceHashKey)] == hash {
+			current = append(current, crb)
+		}
+	}
+	return current
+}
+
+// observedClusterRoleBindings returns the listed ClusterRoleBindings, leaving
+// out those the cache still lists after the reconciler deleted them.
+func (r *ScopeInstanceReconciler) observedClusterRoleBindings(ctx context.Context, crbs []rbacv1.ClusterRoleBinding) []rbacv1.ClusterRoleBinding {
+	var observed []rbacv1.ClusterRoleBinding
+	for i := range crbs {
+		if !r.listedAfterDelete(ctx, &crbs[i]) {
+			observed = append(observed, crbs[i])
+		}
+	}
+	return observed
+}
+
+func (r *ScopeInstanceReconciler) clusterRoleBindingPatchObj(oldCrb *rbacv1.ClusterRoleBinding, crb *rbacv1.ClusterRoleBinding) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": rbacv1.SchemeGroupVersion.String(),
+			"kind":       "ClusterRoleBinding",
+			"metadata": map[string]interface{}{
+				"name":            oldCrb.Name,
+				"ownerReferences": crb.OwnerReferences,
+				"labels":          crb.Labels,
+				"annotations":     crb.Annotations,
+			},
+			"subjects": crb.Subjects,
+		},
+	}
+}
+
+// createOrUpdateRoleBinding creates the desired RoleBinding, or updates the
+// one found for its entry of the ScopeTemplate in its namespace.
+func (r *ScopeInstanceReconciler) createOrUpdateRoleBinding(ctx context.Context, rb *rbacv1.RoleBinding, in *operatorsv1.ScopeInstance) error {
+	if err := r.checkOperatorSubjects(in, rb.Subjects); err != nil {
+		return err
+	}
+	rbList := &rbacv1.RoleBindingList{}
+	key := bindingIndexKey{
+		kind:             "RoleBinding",
+		scopeInstanceUID: r.bindingOwner(in),
+		generateName:     rb.Labels[r.keys.key(clusterRoleBindingGenerateKey)],
+		namespace:        rb.Namespace,
+	}
+	if cached, ok := r.bindings.get(key); ok && !forceSync(in) {
+		rbList.Items = []rbacv1.RoleBinding{*cached.(*rbacv1.RoleBinding)}
+	} else {
+		if err := r.reader().List(ctx, rbList, &client.ListOptions{
+			Namespace: rb.Namespace,
+		}, client.MatchingLabels{
+			r.keys.key(scopeInstanceUIDKey):           r.bindingOwner(in),
+			r.keys.key(clusterRoleBindingGenerateKey): rb.Labels[r.keys.key(clusterRoleBindingGenerateKey)],
+		}); err != nil {
+			return newBindingError("list", rb, err)
+		}
+		rbList.Items = r.observedRoleBindings(ctx, rbList.Items)
+		if len(rbList.Items) == 1 {
+			r.bindings.set(&rbList.Items[0])
+		}
+	}
+
+	if r.AtomicBindingSwap {
+		rbList.Items = currentRoleBindings(r.keys, rbList.Items, rb.Annotations[r.keys.key(referenceHashKey)], rb.Name)
+	}
+
+	if len(rbList.Items) > 1 {
+		duplicates := make([]client.Object, 0, len(rbList.Items))
+		for i := range rbList.Items {
+			duplicates = append(duplicates, &rbList.Items[i])
+		}
+		return r.removeDuplicateBindings(ctx, in, rb, duplicates)
+	}
+
+	// Create the RoleBinding if one doesn't already exist
+	if len(rbList.Items) == 0 {
+		if err := r.creates.wait(ctx, rb.GetNamespace()); err != nil {
+			return newBindingError("create", rb, err)
+		}
+		err := r.Client.Create(ctx, rb, r.fieldOwner())
+		countBindingOperation("RoleBinding", opCreate, err)
+		if err != nil {
+			if rb.Name != "" && k8sapierrors.IsAlreadyExists(err) {
+				err = fmt.Errorf("%w: the name is taken by a RoleBinding not managed by ScopeInstance %s", errBindingNameCollision, in.GetName())
+			}
+			return newBindingError("create", rb, err)
+		}
+		r.bindings.invalidate(rb)
+		bindingsCreated.WithLabelValues("RoleBinding").Inc()
+		reconcileSummaryFrom(ctx).bindingCreated()
+		r.recordNamespaceEvent(ctx, rb, operatorsv1.ReasonRoleBindingCreated, "Created")
+		return nil
+	}
+
+	log.Log.V(2).Info("Updating existing rb", "namespaced", rbList.Items[0].GetNamespace(), "name", rbList.Items[0].GetName())
+
+	existingRB := &rbList.Items[0]
+	// Binding another role under the same name takes deleting the RoleBinding
+	// before creating it again, revoking access in between
+	if rb.Name != "" && existingRB.Name == rb.Name && existingRB.RoleRef != rb.RoleRef {
+		return newBindingError("update", existingRB, fmt.Errorf("%w: RoleBinding %s/%s binds %s %s and would be deleted to bind %s %s, delete it or render another name",
+			errBindingNameCollision, existingRB.Namespace, existingRB.Name, existingRB.RoleRef.Kind, existingRB.RoleRef.Name, rb.RoleRef.Kind, rb.RoleRef.Name))
+	}
+	// Neither the roleRef nor the name of a binding can be changed
+	if existingRB.RoleRef != rb.RoleRef || rb.Name != "" && existingRB.Name != rb.Name {
+		return r.replaceBinding(ctx, in, existingRB, rb)
+	}
+	if err := r.removeLegacyHashLabel(ctx, existingRB); err != nil {
+		return newBindingError("update", existingRB, err)
+	}
+
+	if !forceSync(in) &&
+		util.IsOwnedByLabel(existingRB.DeepCopy(), in, r.keys.key(scopeInstanceUIDKey)) &&
+		equalSubjects(existingRB.Subjects, rb.Subjects) &&
+		hasLabels(existingRB, rb.Labels) &&
+		hasAnnotations(existingRB, rb.Annotations) {
+		log.Log.V(2).Info("existing RoleBinding does not need to be updated", "UID", existingRB.GetUID())
+		return nil
+	}
+
+	patchObj := r.roleBindingPatchObj(existingRB, rb)
+	r.bindings.invalidate(existingRB)
+
+	// server-side apply patch
+	if err := r.patchBinding(ctx, patchObj); err != nil {
+		return newBindingError("update", patchObj, err)
+	}
+
+	return nil
+}
+
+// currentRoleBindings returns the RoleBindings with the given reference
+// hash, leaving out old bindings that are awaiting deletion. A RoleBinding
+// with the given name, if any, is kept as the new one cannot be created
+// alongside it.
+func currentRoleBindings(keys *keyMapping, rbs []rbacv1.RoleBinding, hash, name string) []rbacv1.RoleBinding {
+	var current []rbacv1.RoleBinding
+	for _, rb := range rbs {
+		if rb.Annotations[keys.key(referenceHashKey)] == hash || name != "" && rb.Name == name {
+			current = append(current, rb)
+		}
+	}
+	return current
+}
+
+// observedRoleBindings returns the listed RoleBindings, leaving out those the
+// cache still lists after the reconciler deleted them.
+func (r *ScopeInstanceReconciler) observedRoleBindings(ctx context.Context, rbs []rbacv1.RoleBinding) []rbacv1.RoleBinding {
+	var observed []rbacv1.RoleBinding
+	for i := range rbs {
+		if !r.listedAfterDelete(ctx, &rbs[i]) {
+			observed = append(observed, rbs[i])
+		}
+	}
+	return observed
+}
+
+func (r *ScopeInstanceReconciler) roleBindingPatchObj(oldRb *rbacv1.RoleBinding, rb *rbacv1.RoleBinding) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": rbacv1.SchemeGroupVersion.String(),
+			"kind":       "RoleBinding",
+			"metadata": map[string]interface{}{
+				"name":            oldRb.Name,
+				"namespace":       oldRb.Namespace,
+				"ownerReferences": rb.OwnerReferences,
+				"labels":          rb.Labels,
+				"annotations":     rb.Annotations,
+			},
+			"subjects": rb.Subjects,
+		},
+	}
+}
+
+// removeLegacyHashLabel removes the legacyReferenceHashKey label from the
+// binding, which the apply patch leaves in place, if it has it.
+func (r *ScopeInstanceReconciler) removeLegacyHashLabel(ctx context.Context, binding client.Object) error {
+	patched, err := removeLabel(ctx, r.Client, binding, legacyReferenceHashKey)
+	if patched || err != nil {
+		countBindingOperation(bindingKind(binding), opUpdate, err)
+		r.bindings.invalidate(binding)
+	}
+	return err
+}
+
+// removeLabel removes the label from obj with a merge patch, if it has it,
+// and returns whether obj was patched.
+func removeLabel(ctx context.Context, c client.Client, obj client.Object, key string) (bool, error) {
+	if _, ok := obj.GetLabels()[key]; !ok {
+		return false, nil
+	}
+	original := obj.DeepCopyObject().(client.Object)
+	delete(obj.GetLabels(), key)
+	if err := c.Patch(ctx, obj, client.MergeFrom(original)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// replaceBinding replaces the existing binding with the desired one, which
+// binds a different role or has another name. The RoleRef of a binding is
+// immutable, so the desired binding is created under a new name and the
+// existing one is only deleted once the create of the new one succeeded, so
+// that the subjects never lose access in between. Like every deletion, that of
+// the existing binding goes through confirmedDeletes. A desired binding with
+// the name of the existing one is refused by createOrUpdateRoleBinding
+// instead.
+func (r *ScopeInstanceReconciler) replaceBinding(ctx context.Context, in *operatorsv1.ScopeInstance, existing, desired client.Object) error {
+	log.Log.V(2).Info("replacing binding with a different roleRef or name", "kind", bindingKind(existing), "namespace", existing.GetNamespace(), "name", existing.GetName())
+
+	if err := r.creates.wait(ctx, desired.GetNamespace()); err != nil {
+		return newBindingError("create", desired, err)
+	}
+	err := r.Client.Create(ctx, desired, r.fieldOwner())
+	countBindingOperation(bindingKind(desired), opCreate, err)
+	if err != nil {
+		return newBindingError("create", desired, err)
+	}
+	r.bindings.invalidate(desired)
+	bindingsCreated.WithLabelValues(bindingKind(desired)).Inc()
+	reconcileSummaryFrom(ctx).bindingCreated()
+	r.recordNamespaceEvent(ctx, desired, operatorsv1.ReasonRoleBindingCreated, "Created")
+
+	// The Create response confirms the new binding exists, the cache
+	// may not list it yet
+	confirmed, _ := r.confirmedDeletes(in.GetName(), []client.Object{existing})
+	return r.deleteBindings(ctx, confirmed)
+}
+
+func (r *ScopeInstanceReconciler) patchBinding(ctx context.Context, binding client.Object) error {
+	err := r.Client.Patch(ctx,
+		binding,
+		client.Apply,
+		r.fieldOwner(),
+		client.ForceOwnership)
+	// The apply patch of a binding is unstructured
+	countBindingOperation(binding.GetObjectKind().GroupVersionKind().Kind, opUpdate, err)
+	if err != nil {
+		return err
+	}
+	reconcileSummaryFrom(ctx).bindingUpdated()
+	return nil
+}
+
+// errDuplicateBindings is returned once the extra bindings found for a single
+// ClusterRole have been deleted, so the remaining one is updated on the next
+// reconcile.
+var errDuplicateBindings = errors.New("duplicate (Cluster)RoleBindings")
+
+// errDeletionGuard is returned when the extra bindings found for a single
+// ClusterRole are more than MaxDeletesPerReconcile and their deletion has not
+// been confirmed.
+var errDeletionGuard = errors.New("refusing to delete the duplicate (Cluster)RoleBindings")
+
+// duplicateBindingsError is returned by removeDuplicateBindings with the
+// number of extra bindings found, so that ensureBindingsFailed applies the
+// deletion guard to the ScopeInstance once the concurrent writes are done.
+type duplicateBindingsError struct {
+	extra int
+	err   error
+}
+
+func (e *duplicateBindingsError) Error() string {
+	return e.err.Error()
+}
+
+func (e *duplicateBindingsError) Unwrap() error {
+	return e.err
+}
+
+// duplicateBindingsErrors returns the duplicateBindingsErrors in err,
+// including those aggregated by forEachBounded.
+func duplicateBindingsErrors(err error) []*duplicateBindingsError {
+	var agg apimacherrors.Aggregate
+	if errors.As(err, &agg) {
+		var dups []*duplicateBindingsError
+		for _, err := range agg.Errors() {
+			dups = append(dups, duplicateBindingsErrors(err)...)
+		}
+		return dups
+	}
+	var dup *duplicateBindingsError
+	if errors.As(err, &dup) {
+		return []*duplicateBindingsError{dup}
+	}
+	return nil
+}
+
+// removeDuplicateBindings keeps one of the given bindings, which were all
+// created for the same ClusterRole, and deletes the others. The binding kept
+// is the oldest of those already matching the roleRef and reference hash of
+// the desired binding, or the oldest of all if none does. The others are
+// deleted through confirmedDeletes, unless they exceed MaxDeletesPerReconcile
+// without a confirmation. The ScopeInstance is only read, as the RoleBindings
+// are written concurrently.
+func (r *ScopeInstanceReconciler) removeDuplicateBindings(ctx context.Context, in *operatorsv1.ScopeInstance, desired client.Object, bindings []client.Object) error {
+	matches := func(binding client.Object) bool {
+		return bindingRoleRef(binding) == bindingRoleRef(desired) &&
+			binding.GetAnnotations()[r.keys.key(referenceHashKey)] == desired.GetAnnotations()[r.keys.key(referenceHashKey)]
+	}
+	sort.Slice(bindings, func(i, j int) bool {
+		if matches(bindings[i]) != matches(bindings[j]) {
+			return matches(bindings[i])
+		}
+		a, b := bindings[i].GetCreationTimestamp(), bindings[j].GetCreationTimestamp()
+		if !a.Equal(&b) {
+			return a.Before(&b)
+		}
+		return bindings[i].GetName() < bindings[j].GetName()
+	})
+
+	extra := len(bindings) - 1
+	if r.exceedsDeleteLimit(extra) && in.GetAnnotations()[allowBulkDeleteKey] != "true" {
+		return &duplicateBindingsError{extra: extra, err: fmt.Errorf("%w: %d extra %ss for ClusterRole %s",
+			errDeletionGuard, extra, bindingKind(bindings[0]), bindings[0].GetLabels()[r.keys.key(clusterRoleBindingGenerateKey)])}
+	}
+
+	confirmed, _ := r.confirmedDeletes(in.GetName(), bindings[1:])
+	if err := r.deleteBindings(ctx, confirmed); err != nil {
+		return err
+	}
+	return &duplicateBindingsError{extra: extra, err: fmt.Errorf("%w: deleted %d of %d extra %ss for ClusterRole %s, keeping %s",
+		errDuplicateBindings, len(confirmed), extra, bindingKind(bindings[0]), bindings[0].GetLabels()[r.keys.key(clusterRoleBindingGenerateKey)], bindings[0].GetName())}
+}
+
+// errUnscopedDelete is returned when deleting bindings that are not scoped
+// to a single ScopeInstance, which could otherwise delete unrelated RBAC.
+var errUnscopedDelete = errors.New("refusing to delete (Cluster)RoleBindings without a ScopeInstance UID label selector")
+
+// listBindingsToDelete lists the (Cluster)RoleBindings matching the list
+// options for which matches returns true. The list options must select a
+// single ScopeInstance UID.
+func (r *ScopeInstanceReconciler) listBindingsToDelete(ctx context.Context, matches func(binding client.Object) bool, listOptions ...client.ListOption) ([]client.Object, error) {
+	opts := (&client.ListOptions{}).ApplyOptions(listOptions)
+	if opts.LabelSelector == nil {
+		return nil, errUnscopedDelete
+	}
+	if uid, ok := opts.LabelSelector.RequiresExactMatch(r.keys.key(scopeInstanceUIDKey)); !ok || uid == "" {
+		return nil, errUnscopedDelete
+	}
+
+	var bindings []client.Object
+
+	clusterRoleBindings := &rbacv1.ClusterRoleBindingList{}
+	if err := r.Client.List(ctx, clusterRoleBindings, listOptions...); err != nil {
+		return nil, err
+	}
+	for i := range clusterRoleBindings.Items {
+		if matches(&clusterRoleBindings.Items[i]) && !r.listedAfterDelete(ctx, &clusterRoleBindings.Items[i]) {
+			bindings = append(bindings, &clusterRoleBindings.Items[i])
+		}
+	}
+
+	roleBindings := &rbacv1.RoleBindingList{}
+	if err := r.Client.List(ctx, roleBindings, listOptions...); err != nil {
+		return nil, err
+	}
+	for i := range roleBindings.Items {
+		if matches(&roleBindings.Items[i]) && !r.listedAfterDelete(ctx, &roleBindings.Items[i]) {
+			bindings = append(bindings, &roleBindings.Items[i])
+		}
+	}
+
+	return bindings, nil
+}
+
+// deleteBindings will delete the given (Cluster)RoleBindings. Every binding
+// must carry the scopeInstanceUIDKey label.
+func (r *ScopeInstanceReconciler) deleteBindings(ctx context.Context, bindings []client.Object) error {
+	for _, binding := range bindings {
+		if binding.GetLabels()[r.keys.key(scopeInstanceUIDKey)] == "" {
+			return newBindingError("delete", binding, errUnscopedDelete)
+		}
+	}
+
+	for _, binding := range bindings {
+		r.bindings.invalidate(binding)
+		r.expectDelete(binding)
+		// TODO: Aggregate errors
+		err := r.Client.Delete(ctx, binding, r.deleteOptions()...)
+		if k8sapierrors.IsNotFound(err) {
+			r.forgetDelete(binding)
+			continue
+		}
+		countBindingOperation(bindingKind(binding), opDelete, err)
+		if err != nil {
+			r.forgetDelete(binding)
+			return newBindingError("delete", binding, err)
+		}
+		r.deleted(binding)
+		bindingsDeleted.WithLabelValues(bindingKind(binding)).Inc()
+		reconcileSummaryFrom(ctx).bindingDeleted()
+		r.recordNamespaceEvent(ctx, binding, operatorsv1.ReasonRoleBindingDeleted, "Deleted")
+	}
+
+	return nil
+}
+
+// deleteOptions returns the options of every binding deletion.
+func (r *ScopeInstanceReconciler) deleteOptions() []client.DeleteOption {
+	if r.DeletePropagation == "" {
+		return nil
+	}
+	return []client.DeleteOption{client.PropagationPolicy(r.DeletePropagation)}
+}
+
+// recordNamespaceEvent records an event on the namespace of a RoleBinding
+// created or deleted for a ScopeInstance, so that the owners of the namespace
+// see the RBAC changes made in it. The event is recorded in the namespace
+// itself rather than in the default namespace.
+func (r *ScopeInstanceReconciler) recordNamespaceEvent(ctx context.Context, binding client.Object, reason, verb string) {
+	rb, ok := binding.(*rbacv1.RoleBinding)
+	if !ok || r.Recorder == nil {
+		return
+	}
+
+	ns := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: rb.GetNamespace()}, ns); err != nil {
+		log.Log.V(2).Error(err, "getting the namespace of a RoleBinding to record an event", "namespace", rb.GetNamespace())
+		return
+	}
+	involved := &corev1.Namespace{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+		ObjectMeta: metav1.ObjectMeta{Name: ns.GetName(), Namespace: ns.GetName(), UID: ns.GetUID()},
+	}
+
+	scopeInstance := ""
+	if owner := metav1.GetControllerOf(rb); owner != nil {
+		scopeInstance = owner.Name
+	}
+	r.Recorder.Eventf(involved, corev1.EventTypeNormal, reason, "%s RoleBinding %s of ClusterRole %s for ScopeInstance %s",
+		verb, rb.GetName(), rb.RoleRef.Name, scopeInstance)
+}
+
+// selfDeletedKey identifies a binding deleted by the reconciler.
+func selfDeletedKey(binding client.Object) string {
+	return bindingKind(binding) + "/" + client.ObjectKeyFromObject(binding).String()
+}
+
+// expectDelete records that the reconciler is deleting the binding.
+func (r *ScopeInstanceReconciler) expectDelete(binding client.Object) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.selfDeleted == nil {
+		r.selfDeleted = sets.NewString()
+	}
+	r.selfDeleted.Insert(selfDeletedKey(binding))
+}
+
+// forgetDelete removes the record of a delete that did not happen.
+func (r *ScopeInstanceReconciler) forgetDelete(binding client.Object) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.selfDeleted.Delete(selfDeletedKey(binding))
+}
+
+// notSelfDeleted filters out the delete events of bindings deleted by the
+// reconciler, which already knows that they are gone. The event also means
+// that the cache observed the deletion.
+func (r *ScopeInstanceReconciler) notSelfDeleted(e event.DeleteEvent) bool {
+	if e.Object == nil {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.unobservedDeletes, deleteIntentKey(e.Object))
+	key := selfDeletedKey(e.Object)
+	if !r.selfDeleted.Has(key) {
+		return true
+	}
+	r.selfDeleted.Delete(key)
+	return false
+}
+
+// deleted records that the reconciler deleted the binding, forgetting the
+// deletions that were never observed within deletionObservedTimeout.
+func (r *ScopeInstanceReconciler) deleted(binding client.Object) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.unobservedDeletes == nil {
+		r.unobservedDeletes = map[string]time.Time{}
+	}
+	now := time.Now()
+	for key, deletedAt := range r.unobservedDeletes {
+		if now.Sub(deletedAt) > deletionObservedTimeout {
+			delete(r.unobservedDeletes, key)
+		}
+	}
+	r.unobservedDeletes[deleteIntentKey(binding)] = now
+}
+
+// listedAfterDelete returns true if the listed binding is one the reconciler
+// deleted before the cache observed it, counting it in the summary of the
+// reconcile. Such a binding must be ignored, lest it be taken for a
+// duplicate of the binding replacing it.
+func (r *ScopeInstanceReconciler) listedAfterDelete(ctx context.Context, binding client.Object) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	deletedAt, ok := r.unobservedDeletes[deleteIntentKey(binding)]
+	if !ok {
+		return false
+	}
+	if time.Since(deletedAt) > deletionObservedTimeout {
+		delete(r.unobservedDeletes, deleteIntentKey(binding))
+		return false
+	}
+	reconcileSummaryFrom(ctx).deleteUnobserved()
+	return true
+}
+
+// oldBindings will return any (Cluster)RoleBindings that are owned by the
+// given ScopeInstance and are no longer up to date. Being out of date means
+// the combined hash of ScopeInstance.Spec and ScopeTemplate.Spec is
+// different, the binding is not of the kind its ClusterRole should be bound
+// with, or the RoleBinding lives in a namespace that is no longer selected by
+// the ScopeInstance. RoleBindings in terminating namespaces are left to the
+// deletion of the namespace.
+func (r *ScopeInstanceReconciler) oldBindings(ctx context.Context, in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate, namespaces []string, terminating, excluded sets.String) ([]client.Object, error) {
+	combinedHash := hashScopeInstanceAndTemplate(in, st)
+	templated := sets.NewString()
+	clusterBound := sets.NewString()
+	// selected holds the namespaces of the RoleBindings of each ClusterRole
+	selected := map[string]sets.String{}
+	for _, cr := range st.Spec.BindingTemplates() {
+		name := shortGenerateName(cr.GenerateName)
+		templated.Insert(name)
+		if isClusterScoped(in) || isClusterBound(&cr) {
+			clusterBound.Insert(name)
+		}
+
+		if in.Spec.BindInSubjectNamespaces {
+			var err error
+			if cr, err = r.resolveServiceAccountSubjects(ctx, cr); err != nil {
+				return nil, err
+			}
+		}
+		selected[name] = sets.NewString()
+		for _, ns := range bindingNamespaces(in, &cr, namespaces, excluded) {
+			selected[name].Insert(ns)
+		}
+	}
+	isOutOfDate := func(binding client.Object) bool {
+		// Leave RoleBindings in terminating namespaces to the namespace deletion
+		if terminating.Has(binding.GetNamespace()) {
+			return false
+		}
+		if binding.GetAnnotations()[r.keys.key(referenceHashKey)] != combinedHash {
+			return true
+		}
+		// Bindings for ClusterRoles removed from the ScopeTemplate
+		if !templated.Has(binding.GetLabels()[r.keys.key(clusterRoleBindingGenerateKey)]) {
+			return true
+		}
+		_, isRoleBinding := binding.(*rbacv1.RoleBinding)
+		// ClusterRoleBindings of the ScopeInstance alone are replaced by the
+		// shared ones
+		if !isRoleBinding && r.ConsolidateClusterRoleBindings {
+			return true
+		}
+		if isRoleBinding == clusterBound.Has(binding.GetLabels()[r.keys.key(clusterRoleBindingGenerateKey)]) {
+			return true
+		}
+		return isRoleBinding && !selected[binding.GetLabels()[r.keys.key(clusterRoleBindingGenerateKey)]].Has(binding.GetNamespace())
+	}
+
+	return r.listBindingsToDelete(ctx, isOutOfDate, client.MatchingLabels{
+		r.keys.key(scopeInstanceUIDKey): r.bindingOwner(in),
+	})
+}
+
+// deletionGuardTripped returns true if deleting the given number of bindings
+// exceeds MaxDeletesPerReconcile and the deletion has not been confirmed with
+// the allowBulkDeleteKey annotation. A confirmation is consumed by the
+// deletion it allows.
+func (r *ScopeInstanceReconciler) deletionGuardTripped(in *operatorsv1.ScopeInstance, deletes int) bool {
+	if !r.exceedsDeleteLimit(deletes) {
+		return false
+	}
+
+	if in.GetAnnotations()[allowBulkDeleteKey] == "true" {
+		delete(in.Annotations, allowBulkDeleteKey)
+		return false
+	}
+
+	updateStatusDeletionGuard(in, deletes, r.MaxDeletesPerReconcile)
+	if r.Recorder != nil {
+		r.Recorder.Eventf(in, corev1.EventTypeWarning, operatorsv1.ReasonDeletionGuard,
+			"Refusing to delete %d (Cluster)RoleBindings, more than the limit of %d. Annotate the ScopeInstance with %s=true to confirm.",
+			deletes, r.MaxDeletesPerReconcile, allowBulkDeleteKey)
+	}
+	return true
+}
+
+// exceedsDeleteLimit returns true if deleting the given number of bindings
+// at once exceeds MaxDeletesPerReconcile.
+func (r *ScopeInstanceReconciler) exceedsDeleteLimit(deletes int) bool {
+	return r.MaxDeletesPerReconcile > 0 && deletes > r.MaxDeletesPerReconcile
+}
+
+// deletionIntents are the deletions deferred by DeletionSafeMode for a
+// ScopeInstance: those recorded by its previous reconcile, which the current
+// one may confirm, and those recorded by the current one.
+type deletionIntents struct {
+	previous sets.String
+	recorded sets.String
+}
+
+// confirmedDeletes returns every binding if DeletionSafeMode is not set.
+// Otherwise it only returns the bindings the previous reconcile of the named
+// ScopeInstance also intended to delete, recording the others and returning
+// true if there are any, so that the next reconcile may confirm them. Every
+// deletion of a reconcile is recorded together, whichever step intends it.
+func (r *ScopeInstanceReconciler) confirmedDeletes(name string, bindings []client.Object) ([]client.Object, bool) {
+	if !r.DeletionSafeMode {
+		return bindings, false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	intents := r.deleteIntents[name]
+	if intents == nil {
+		intents = &deletionIntents{previous: sets.NewString(), recorded: sets.NewString()}
+	}
+	var confirmed []client.Object
+	deferred := false
+	for _, binding := range bindings {
+		key := deleteIntentKey(binding)
+		if intents.previous.Has(key) {
+			confirmed = append(confirmed, binding)
+			continue
+		}
+		log.Log.V(1).Info("deferring binding deletion until the next reconcile confirms it", "scopeInstance", name,
+			"kind", bindingKind(binding), "namespace", binding.GetNamespace(), "name", binding.GetName())
+		intents.recorded.Insert(key)
+		deferred = true
+	}
+
+	if deferred {
+		if r.deleteIntents == nil {
+			r.deleteIntents = map[string]*deletionIntents{}
+		}
+		r.deleteIntents[name] = intents
+	}
+	return confirmed, deferred
+}
+
+// startDeleteIntents starts a reconcile of the named ScopeInstance, which
+// may only confirm the deletions recorded by the previous one.
+func (r *ScopeInstanceReconciler) startDeleteIntents(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	intents := r.deleteIntents[name]
+	if intents == nil {
+		return
+	}
+	if intents.recorded.Len() == 0 {
+		delete(r.deleteIntents, name)
+		return
+	}
+	intents.previous, intents.recorded = intents.recorded, sets.NewString()
+}
+
+// deletionsDeferred returns true if the current reconcile of the named
+// ScopeInstance deferred a deletion.
+func (r *ScopeInstanceReconciler) deletionsDeferred(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	intents := r.deleteIntents[name]
+	return intents != nil && intents.recorded.Len() > 0
+}
+
+// deleteIntentKey identifies a binding whose deletion was deferred. The UID
+// keeps a recreated binding from being confirmed by an intent for the old one.
+func deleteIntentKey(binding client.Object) string {
+	return selfDeletedKey(binding) + "/" + string(binding.GetUID())
+}
+
+// forgetDeleteIntents removes the deferred deletions of a ScopeInstance that
+// no longer exists.
+func (r *ScopeInstanceReconciler) forgetDeleteIntents(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.deleteIntents, name)
+}
+
+// namespaceStates returns the namespaces that are being deleted and those
+// excluded from every ScopeInstance by the noScopeKey annotation.
+func (r *ScopeInstanceReconciler) namespaceStates(ctx context.Context) (terminating, excluded sets.String, err error) {
+	namespaceList := &corev1.NamespaceList{}
+	if err := r.Client.List(ctx, namespaceList); err != nil {
+		return nil, nil, err
+	}
+
+	terminating, excluded = sets.NewString(), sets.NewString()
+	for _, ns := range namespaceList.Items {
+		if ns.GetDeletionTimestamp() != nil || ns.Status.Phase == corev1.NamespaceTerminating {
+			terminating.Insert(ns.GetName())
+		}
+		if isExcluded(&ns) {
+			excluded.Insert(ns.GetName())
+		}
+	}
+	return terminating, excluded, nil
+}
+
+// isExcluded returns true if the namespace opted out of every ScopeInstance.
+// Only RoleBindings are kept out of it: a ClusterRoleBinding cannot spare a
+// namespace, so the cluster-wide ClusterRoles of a ScopeTemplate still grant
+// access in an excluded namespace.
+func isExcluded(ns client.Object) bool {
+	return ns.GetAnnotations()[noScopeKey] == "true"
+}
+
+// withoutNamespaces returns the namespaces that are not in excluded.
+func withoutNamespaces(namespaces []string, excluded sets.String) []string {
+	if excluded.Len() == 0 {
+		return namespaces
+	}
+	var kept []string
+	for _, ns := range namespaces {
+		if !excluded.Has(ns) {
+			kept = append(kept, ns)
+		}
+	}
+	return kept
+}
+
+// reportBindingError logs err, naming the binding involved if err is a
+// bindingError, in which case a warning event is also emitted for the
+// ScopeInstance. Each error of an aggregate is reported separately.
+func (r *ScopeInstanceReconciler) reportBindingError(in *operatorsv1.ScopeInstance, msg string, err error) {
+	var agg apimacherrors.Aggregate
+	if errors.As(err, &agg) {
+		for _, err := range agg.Errors() {
+			r.reportBindingError(in, msg, err)
+		}
+		return
+	}
+
+	var bindingErr *bindingError
+	if !errors.As(err, &bindingErr) {
+		log.Log.V(2).Error(err, msg)
+		return
+	}
+
+	log.Log.V(2).Error(err, msg, bindingErr.keysAndValues()...)
+	if r.Recorder != nil {
+		r.Recorder.Eventf(in, corev1.EventTypeWarning, operatorsv1.ReasonScopingFailed,
+			"Failed to %s %s: %v", bindingErr.Op, bindingErr.object(), bindingErr.Err)
+	}
+}
+
+// missingClusterRoles returns the names of the ClusterRoles bound for the
+// ScopeInstance and ScopeTemplate that do not exist yet. Roles are looked up
+// in the namespace of each RoleBinding and are not checked.
+func (r *ScopeInstanceReconciler) missingClusterRoles(ctx context.Context, in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate) ([]string, error) {
+	var missing []string
+	for _, cr := range st.Spec.BindingTemplates() {
+		if bindsRole(&cr) {
+			continue
+		}
+		name := roleRef(&cr, in).Name
+		if err := r.Client.Get(ctx, client.ObjectKey{Name: name}, &rbacv1.ClusterRole{}); err != nil {
+			if !k8sapierrors.IsNotFound(err) {
+				return nil, err
+			}
+			missing = append(missing, name)
+		}
+	}
+	return missing, nil
+}
+
+// roleRef returns the role bound for the entry of the ScopeTemplate. The
+// ScopeInstance may override the name of a bound ClusterRole.
+func roleRef(cr *operatorsv1.BindingTemplate, in *operatorsv1.ScopeInstance) rbacv1.RoleRef {
+	ref := cr.RoleRef
+	if ref.APIGroup == "" {
+		ref.APIGroup = rbacv1.GroupName
+	}
+	if name := in.Spec.ClusterRoleNameOverrides[cr.GenerateName]; name != "" && !bindsRole(cr) {
+		ref.Name = name
+	}
+	return ref
+}
+
+// bindsRole returns true if the entry of the ScopeTemplate binds a Role
+// rather than a ClusterRole. Such entries are only bound with RoleBindings.
+func bindsRole(cr *operatorsv1.BindingTemplate) bool {
+	return cr.RoleRef.Kind == "Role"
+}
+
+// generatedBindings returns the kind and RoleRef of the bindings created for
+// each entry of the ScopeTemplate.
+func generatedBindings(in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate) []operatorsv1.GeneratedBinding {
+	var generated []operatorsv1.GeneratedBinding
+	for _, cr := range st.Spec.BindingTemplates() {
+		kind := "RoleBinding"
+		if isClusterScoped(in) || isClusterBound(&cr) {
+			if bindsRole(&cr) {
+				continue
+			}
+			kind = "ClusterRoleBinding"
+		}
+		generated = append(generated, operatorsv1.GeneratedBinding{
+			GenerateName: cr.GenerateName,
+			Kind:         kind,
+			RoleRef:      roleRef(&cr, in),
+		})
+	}
+	return generated
+}
+
+// resolveNamespaces returns the namespaces that the given ScopeInstance
+// should create RoleBindings in. When a NamespaceAnnotationSelector is
+// provided the namespaces carrying all of the selected annotations are
+// returned, along with ScopeInstance.Spec.Namespaces in the Union match
+// mode, or limited to ScopeInstance.Spec.Namespaces if it is not empty in
+// the Intersection match mode.
+func (r *ScopeInstanceReconciler) resolveNamespaces(ctx context.Context, in *operatorsv1.ScopeInstance) ([]string, error) {
+	if len(in.Spec.NamespaceAnnotationSelector) == 0 {
+		return in.Spec.Namespaces, nil
+	}
+
+	namespaceList := &corev1.NamespaceList{}
+	if err := r.Client.List(ctx, namespaceList); err != nil {
+		return nil, err
+	}
+
+	listed := sets.NewString(in.Spec.Namespaces...)
+	intersect := in.Spec.NamespaceMatchMode == operatorsv1.NamespaceMatchModeIntersection
+	namespaces := []string{}
+	if !intersect {
+		namespaces = append(namespaces, listed.List()...)
+	}
+	for _, ns := range namespaceList.Items {
+		if listed.Len() > 0 && listed.Has(ns.GetName()) != intersect {
+			continue
+		}
+		if !matchesAnnotations(ns.GetAnnotations(), in.Spec.NamespaceAnnotationSelector) {
+			continue
+		}
+		namespaces = append(namespaces, ns.GetName())
+	}
+
+	return namespaces, nil
+}
+
+// matchesAnnotations returns true if every key/value pair in the selector
+// is present in the given annotations.
+func matchesAnnotations(annotations, selector map[string]string) bool {
+	for k, v := range selector {
+		if value, ok := annotations[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+// setScopeTemplateLabel labels the ScopeInstance with the name of the
+// ScopeTemplate it references, or of the default ScopeTemplate. The label is
+// removed if the name is not a valid label value.
+func setScopeTemplateLabel(keys *keyMapping, in *operatorsv1.ScopeInstance, defaultScopeTemplate string) {
+	name := scopeTemplateKey(in, defaultScopeTemplate).Name
+	if len(validation.IsValidLabelValue(name)) > 0 {
+		if _, ok := in.GetLabels()[keys.key(scopeTemplateNameKey)]; ok {
+			delete(in.Labels, keys.key(scopeTemplateNameKey))
+		}
+		return
+	}
+
+	if in.Labels == nil {
+		in.Labels = map[string]string{}
+	}
+	in.Labels[keys.key(scopeTemplateNameKey)] = name
+}
+
+// shortGenerateName returns the generateName of an entry of the ScopeTemplate
+// shortened to fit in a label value, for use in labels and in the names of
+// bindings and ClusterRoles.
+func shortGenerateName(generateName string) string {
+	return util.TruncateWithHash(generateName, validation.LabelValueMaxLength)
+}
+
+// scopeTemplateKey returns the key of the ScopeTemplate referenced by the
+// ScopeInstance, preferring ScopeTemplateRef over ScopeTemplateName, and
+// falling back to the named default ScopeTemplate if neither is set.
+func scopeTemplateKey(in *operatorsv1.ScopeInstance, defaultScopeTemplate string) client.ObjectKey {
+	if ref := in.Spec.ScopeTemplateRef; ref != nil {
+		return client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}
+	}
+	if in.Spec.ScopeTemplateName == "" {
+		return client.ObjectKey{Name: defaultScopeTemplate}
+	}
+	return client.ObjectKey{Name: in.Spec.ScopeTemplateName}
+}
+
+// scopeTemplateByUID returns the ScopeTemplate whose UID is the
+// ScopeTemplateUID of the ScopeInstance, or nil if it is not set or no
+// ScopeTemplate has it.
+func scopeTemplateByUID(ctx context.Context, c client.Reader, in *operatorsv1.ScopeInstance) (*operatorsv1.ScopeTemplate, error) {
+	if in.Spec.ScopeTemplateUID == "" {
+		return nil, nil
+	}
+	stList := &operatorsv1.ScopeTemplateList{}
+	if err := c.List(ctx, stList); err != nil {
+		return nil, err
+	}
+	for i := range stList.Items {
+		if stList.Items[i].GetUID() == in.Spec.ScopeTemplateUID {
+			return &stList.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// referencesScopeTemplate returns whether the ScopeInstance references the
+// ScopeTemplate by UID or by key. A ScopeInstance pinning another
+// ScopeTemplate by UID still references the one it falls back to.
+func referencesScopeTemplate(in *operatorsv1.ScopeInstance, st client.Object, defaultScopeTemplate string) bool {
+	if in.Spec.ScopeTemplateUID != "" && in.Spec.ScopeTemplateUID == st.GetUID() {
+		return true
+	}
+	return scopeTemplateKey(in, defaultScopeTemplate) == client.ObjectKeyFromObject(st)
+}
+
+// resolveServiceAccountSubjects returns a copy of the ClusterRoleTemplate
+// whose Subjects include every ServiceAccount matched by its
+// ServiceAccountSelector.
+func (r *ScopeInstanceReconciler) resolveServiceAccountSubjects(ctx context.Context, cr operatorsv1.BindingTemplate) (operatorsv1.BindingTemplate, error) {
+	if cr.ServiceAccountSelector == nil {
+		return cr, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(&cr.ServiceAccountSelector.Selector)
+	if err != nil {
+		return cr, fmt.Errorf("parsing ServiceAccountSelector for ClusterRole %s: %w", cr.GenerateName, err)
+	}
+
+	saList := &corev1.ServiceAccountList{}
+	if err := r.reader().List(ctx, saList, &client.ListOptions{
+		Namespace:     cr.ServiceAccountSelector.Namespace,
+		LabelSelector: selector,
+	}); err != nil {
+		return cr, err
+	}
+
+	// Sort the ServiceAccounts so the bindings do not change with list order
+	sort.Slice(saList.Items, func(i, j int) bool {
+		if saList.Items[i].Namespace != saList.Items[j].Namespace {
+			return saList.Items[i].Namespace < saList.Items[j].Namespace
+		}
+		return saList.Items[i].Name < saList.Items[j].Name
+	})
+
+	subjects := make([]rbacv1.Subject, 0, len(cr.Subjects)+len(saList.Items))
+	subjects = append(subjects, cr.Subjects...)
+	for _, sa := range saList.Items {
+		subjects = append(subjects, rbacv1.Subject{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      sa.Name,
+			Namespace: sa.Namespace,
+		})
+	}
+	cr.Subjects = subjects
+	return cr, nil
+}
+
+// resolveGroupSetSubjects returns a copy of the ClusterRoleTemplate with the
+// Groups listed by its GroupSets that it does not already list appended to
+// its Subjects.
+func (r *ScopeInstanceReconciler) resolveGroupSetSubjects(ctx context.Context, cr operatorsv1.BindingTemplate) (operatorsv1.BindingTemplate, error) {
+	if len(cr.GroupSets) == 0 {
+		return cr, nil
+	}
+	if r.GroupSetNamespace == "" {
+		return cr, fmt.Errorf("ClusterRole %s references group sets, but no group set namespace is set", cr.GenerateName)
+	}
+
+	listed := sets.NewString()
+	for _, subject := range cr.Subjects {
+		if subject.Kind == rbacv1.GroupKind {
+			listed.Insert(subject.Name)
+		}
+	}
+	groups := sets.NewString()
+	for _, name := range cr.GroupSets {
+		cm := &corev1.ConfigMap{}
+		if err := r.Client.Get(ctx, client.ObjectKey{Namespace: r.GroupSetNamespace, Name: name}, cm); err != nil {
+			return cr, fmt.Errorf("getting group set %s for ClusterRole %s: %w", name, cr.GenerateName, err)
+		}
+		groups.Insert(groupSetGroups(cm)...)
+	}
+
+	// Sort the groups so the bindings do not change with the order of the lines
+	subjects := make([]rbacv1.Subject, 0, len(cr.Subjects)+groups.Len())
+	subjects = append(subjects, cr.Subjects...)
+	for _, group := range groups.Difference(listed).List() {
+		subjects = append(subjects, rbacv1.Subject{
+			Kind:     rbacv1.GroupKind,
+			APIGroup: rbacv1.GroupName,
+			Name:     group,
+		})
+	}
+	cr.Subjects = subjects
+	return cr, nil
+}
+
+// groupSetGroups returns the groups listed by the ConfigMap of a group set,
+// one per line, ignoring blank lines.
+func groupSetGroups(cm *corev1.ConfigMap) []string {
+	var groups []string
+	for _, line := range strings.Split(cm.Data[groupSetGroupsKey], "\n") {
+		if group := strings.TrimSpace(line); group != "" {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// withExpressionSubjects returns a copy of the given ClusterRoleTemplate with
+// the subjects computed by its SubjectExpression for a binding in namespace
+// appended to its Subjects. The evaluation takes no I/O and is bounded by
+// subjectExpressionTimeout.
+func withExpressionSubjects(cr operatorsv1.BindingTemplate, in *operatorsv1.ScopeInstance, namespace string) (operatorsv1.BindingTemplate, error) {
+	if cr.SubjectExpression == "" {
+		return cr, nil
+	}
+
+	computed, err := evaluateSubjectExpression(context.Background(), cr.SubjectExpression, in, namespace)
+	if err != nil {
+		return cr, fmt.Errorf("ClusterRole %s: %w", cr.GenerateName, err)
+	}
+
+	subjects := make([]rbacv1.Subject, 0, len(cr.Subjects)+len(computed))
+	subjects = append(subjects, cr.Subjects...)
+	cr.Subjects = append(subjects, computed...)
+	return cr, nil
+}
+
+// withNamespaceSubjects returns a copy of the given ClusterRoleTemplate with
+// its NamespaceSubjects for namespace appended to its Subjects.
+func withNamespaceSubjects(cr operatorsv1.BindingTemplate, namespace string) operatorsv1.BindingTemplate {
+	extra := cr.NamespaceSubjects[namespace]
+	if len(extra) == 0 {
+		return cr
+	}
+
+	subjects := make([]rbacv1.Subject, 0, len(cr.Subjects)+len(extra))
+	subjects = append(subjects, cr.Subjects...)
+	cr.Subjects = append(subjects, extra...)
+	return cr
+}
+
+// isClusterScoped returns true if the ScopeInstance should be bound
+// cluster-wide using ClusterRoleBindings instead of RoleBindings.
+func isClusterScoped(in *operatorsv1.ScopeInstance) bool {
+	return len(in.Spec.Namespaces) == 0 && len(in.Spec.NamespaceAnnotationSelector) == 0 && !in.Spec.BindInSubjectNamespaces
+}
+
+// bindingNamespaces returns the namespaces the RoleBindings of the ClusterRole
+// are created in: the selected namespaces and, if BindInSubjectNamespaces is
+// set, the namespace of every ServiceAccount subject of the ClusterRole that
+// is not excluded.
+func bindingNamespaces(in *operatorsv1.ScopeInstance, cr *operatorsv1.BindingTemplate, namespaces []string, excluded sets.String) []string {
+	if !in.Spec.BindInSubjectNamespaces {
+		return namespaces
+	}
+	all := sets.NewString(namespaces...)
+	for _, subject := range cr.Subjects {
+		if subject.Kind == rbacv1.ServiceAccountKind && subject.Namespace != "" && !excluded.Has(subject.Namespace) {
+			all.Insert(subject.Namespace)
+		}
+	}
+	return all.List()
+}
+
+// isClusterBound returns true if the ClusterRole is always bound cluster-wide,
+// regardless of the namespaces selected by the ScopeInstance.
+func isClusterBound(cr *operatorsv1.BindingTemplate) bool {
+	return cr.Scope == operatorsv1.ClusterRoleScopeCluster
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ScopeInstanceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.keys == nil {
+		keys, err := newKeyMapping(r.KeyPrefix)
+		if err != nil {
+			return err
+		}
+		r.keys = keys
+	}
+
+	if r.bindings == nil {
+		r.bindings = newBindingIndex(r.keys)
+	}
+
+	if r.priorities == nil {
+		r.priorities = newPriorityGate(r.priorityOf)
+	}
+
+	if r.dirty == nil {
+		r.dirty = newDirtyNamespaces()
+	}
+
+	if r.subjects == nil {
+		r.subjects = newSubjectDebounce(r.SubjectChangeDebounce)
+	}
+
+	if r.creates == nil {
+		r.creates = newNamespaceRateLimiter(r.NamespaceCreateQPS, r.NamespaceCreateBurst)
+	}
+
+	// A Reader that is not a cache, such as the API reader, cannot be indexed
+	indexer := mgr.GetFieldIndexer()
+	if r.Reader != nil {
+		indexer, _ = r.Reader.(client.FieldIndexer)
+	}
+	if indexer != nil {
+		if err := r.indexScopeTemplates(context.Background(), indexer); err != nil {
+			return err
+		}
+	}
+
+	c, err := controller.New("scopeinstance", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	// The owner handler is wrapped by the priority gate below, which hides it
+	// from the injection done by Watch.
+	owner := &handler.EnqueueRequestForOwner{OwnerType: &operatorsv1.ScopeInstance{}, IsController: true}
+	if err := mgr.SetFields(owner); err != nil {
+		return err
+	}
+
+	watches := []struct {
+		obj        client.Object
+		handler    handler.EventHandler
+		predicates []predicate.Predicate
+	}{
+		{obj: &operatorsv1.ScopeInstance{}, handler: r.priorities.handler(r.dirty.handler(&handler.EnqueueRequestForObject{}))},
+		// Only spec changes of a ScopeTemplate affect its ScopeInstances. Requests
+		// for the same ScopeInstance are coalesced by the workqueue while they wait,
+		// so a ScopeTemplate that is repeatedly recreated does not cause a storm.
+		// Subject changes may be delayed further to be batched.
+		// Changes to its health are also surfaced on its ScopeInstances.
+		{obj: &operatorsv1.ScopeTemplate{}, handler: r.priorities.handler(r.dirty.handler(r.subjects.handler(handler.EnqueueRequestsFromMapFunc(r.mapToScopeInstance)))),
+			predicates: []predicate.Predicate{predicate.Or(predicate.GenerationChangedPredicate{}, predicate.Funcs{UpdateFunc: scopeTemplateHealthChanged})}},
+		// Set up a watch for Namespaces so annotation changes are reflected in the selected namespaces.
+		// Its requests only reconcile the changed namespace where possible.
+		{obj: &corev1.Namespace{}, handler: r.priorities.handler(handler.EnqueueRequestsFromMapFunc(r.mapNamespaceToScopeInstance)),
+			predicates: []predicate.Predicate{predicate.Funcs{UpdateFunc: r.namespaceSelectionChanged}}},
+		// Set up a watch for ServiceAccounts so selected ServiceAccounts are bound as they come and go
+		{obj: &corev1.ServiceAccount{}, handler: r.priorities.handler(r.dirty.handler(handler.EnqueueRequestsFromMapFunc(r.mapServiceAccountToScopeInstance)))},
+		{obj: &rbacv1.ClusterRoleBinding{}, handler: r.priorities.handler(r.dirty.handler(owner)),
+			predicates: []predicate.Predicate{predicate.Funcs{DeleteFunc: r.notSelfDeleted}}},
+		{obj: &rbacv1.RoleBinding{}, handler: r.priorities.handler(r.dirty.handler(owner)),
+			predicates: []predicate.Predicate{predicate.Funcs{DeleteFunc: r.notSelfDeleted}}},
+		// Set up a watch for ClusterRoles so those selected by label are bound as they come and go.
+		// The old and new labels of an update are both mapped.
+		{obj: &rbacv1.ClusterRole{}, handler: r.priorities.handler(r.dirty.handler(handler.EnqueueRequestsFromMapFunc(r.mapClusterRoleToScopeInstance))),
+			predicates: []predicate.Predicate{predicate.LabelChangedPredicate{}}},
+		// Keep the binding index in sync with the bindings in the cache
+		{obj: &rbacv1.ClusterRoleBinding{}, handler: r.bindings.eventHandler()},
+		{obj: &rbacv1.RoleBinding{}, handler: r.bindings.eventHandler()},
+	}
+	for _, w := range watches {
+		if err := c.Watch(&source.Kind{Type: w.obj}, w.handler, w.predicates...); err != nil {
+			return err
+		}
+	}
+
+	// Set up a watch for the ConfigMaps of group sets so the bindings follow their groups
+	if r.GroupSetNamespace != "" {
+		return c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, r.priorities.handler(r.dirty.handler(handler.EnqueueRequestsFromMapFunc(r.mapGroupSetToScopeInstance))))
+	}
+	return nil
+}
+
+// priorityOf returns the Spec.Priority of the requested ScopeInstance, or 0
+// if it cannot be found.
+func (r *ScopeInstanceReconciler) priorityOf(req reconcile.Request) int32 {
+	in := &operatorsv1.ScopeInstance{}
+	if err := r.Client.Get(context.TODO(), req.NamespacedName, in); err != nil {
+		return 0
+	}
+	return in.Spec.Priority
+}
+
+func (r *ScopeInstanceReconciler) mapToScopeInstance(obj client.Object) (requests []reconcile.Request) {
+	if obj == nil || obj.GetName() == "" {
+		return nil
+	}
+
+	// Requeue the ScopeInstances referencing the ScopeTemplate, by key or by
+	// UID. Without the index every ScopeInstance is listed and filtered below.
+	ctx := context.TODO()
+	var scopeInstances []operatorsv1.ScopeInstance
+	listOptions := [][]client.ListOption{nil}
+	if r.scopeTemplatesIndexed {
+		listOptions = [][]client.ListOption{
+			{client.MatchingFields{scopeTemplateIndexKey: client.ObjectKeyFromObject(obj).String()}},
+			{client.MatchingFields{scopeTemplateIndexKey: scopeTemplateUIDIndexValue(obj.GetUID())}},
+		}
+	}
+	for _, opts := range listOptions {
+		scopeInstanceList := &operatorsv1.ScopeInstanceList{}
+		if err := r.reader().List(ctx, scopeInstanceList, opts...); err != nil {
+			log.Log.Error(err, "error listing scopeinstances")
+			return nil
+		}
+		scopeInstances = append(scopeInstances, scopeInstanceList.Items...)
+	}
+
+	seen := sets.NewString()
+	for _, si := range scopeInstances {
+		if !referencesScopeTemplate(&si, obj, r.DefaultScopeTemplate) || seen.Has(si.GetName()) {
+			continue
+		}
+		seen.Insert(si.GetName())
+
+		request := reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: si.GetNamespace(), Name: si.GetName()},
+		}
+		requests = append(requests, request)
+	}
+
+	return
+}
+
+// mapNamespaceToScopeInstance enqueues every ScopeInstance that selects
+// namespaces by annotation, as a change to any namespace may add or remove
+// it from their selected set, and every ScopeInstance listing the namespace,
+// as the namespace may have been excluded. ScopeInstances referencing a
+// ScopeTemplate that binds a ServiceAccount in the namespace are enqueued as
+// well, so their bindings are refreshed once the namespace of the
+// ServiceAccount exists.
+func (r *ScopeInstanceReconciler) mapNamespaceToScopeInstance(obj client.Object) (requests []reconcile.Request) {
+	if obj == nil || obj.GetName() == "" {
+		return nil
+	}
+
+	ctx := context.TODO()
+	scopeInstanceList := &operatorsv1.ScopeInstanceList{}
+
+	if err := r.Client.List(ctx, scopeInstanceList); err != nil {
+		log.Log.Error(err, "error listing scopeinstances")
+		return nil
+	}
+
+	for _, si := range scopeInstanceList.Items {
+		if len(si.Spec.NamespaceAnnotationSelector) == 0 && !sets.NewString(si.Spec.Namespaces...).Has(obj.GetName()) {
+			continue
+		}
+
+		request := reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: si.GetNamespace(), Name: si.GetName()},
+		}
+		r.dirty.markNamespace(si.GetName(), obj.GetName())
+		requests = append(requests, request)
+	}
+
+	scopeTemplateList := &operatorsv1.ScopeTemplateList{}
+	if err := r.Client.List(ctx, scopeTemplateList); err != nil {
+		log.Log.Error(err, "error listing scopetemplates")
+		return
+	}
+
+	for _, st := range scopeTemplateList.Items {
+		if !bindsServiceAccountIn(&st, obj.GetName()) {
+			continue
+		}
+		// The ServiceAccounts of the namespace may be bound in any namespace
+		for _, request := range r.mapToScopeInstance(&st) {
+			r.dirty.markFull(request.Name)
+			requests = append(requests, request)
+		}
+	}
+
+	return
+}
+
+// namespaceSelectionChanged filters Namespace updates down to those that
+// change whether the namespace is excluded or an annotation used by the
+// NamespaceAnnotationSelector of any ScopeInstance, so that unrelated
+// namespace updates do not enqueue reconciles.
+func (r *ScopeInstanceReconciler) namespaceSelectionChanged(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil || e.ObjectNew == nil {
+		return true
+	}
+
+	oldAnnotations, newAnnotations := e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations()
+	if reflect.DeepEqual(oldAnnotations, newAnnotations) {
+		return false
+	}
+	if isExcluded(e.ObjectOld) != isExcluded(e.ObjectNew) {
+		return true
+	}
+
+	ctx := context.TODO()
+	scopeInstanceList := &operatorsv1.ScopeInstanceList{}
+
+	if err := r.Client.List(ctx, scopeInstanceList); err != nil {
+		log.Log.Error(err, "error listing scopeinstances")
+		return true
+	}
+
+	for _, si := range scopeInstanceList.Items {
+		for key := range si.Spec.NamespaceAnnotationSelector {
+			oldValue, oldOk := oldAnnotations[key]
+			newValue, newOk := newAnnotations[key]
+			if oldOk != newOk || oldValue != newValue {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// mapServiceAccountToScopeInstance enqueues every ScopeInstance referencing a
+// ScopeTemplate whose ServiceAccountSelector matches the ServiceAccount.
+func (r *ScopeInstanceReconciler) mapServiceAccountToScopeInstance(obj client.Object) (requests []reconcile.Request) {
+	if obj == nil || obj.GetName() == "" {
+		return nil
+	}
+
+	ctx := context.TODO()
+	scopeTemplateList := &operatorsv1.ScopeTemplateList{}
+
+	if err := r.Client.List(ctx, scopeTemplateList); err != nil {
+		log.Log.Error(err, "error listing scopetemplates")
+		return nil
+	}
+
+	for _, st := range scopeTemplateList.Items {
+		if !selectsServiceAccount(&st, obj) {
+			continue
+		}
+		requests = append(requests, r.mapToScopeInstance(&st)...)
+	}
+
+	return
+}
+
+// mapGroupSetToScopeInstance enqueues every ScopeInstance referencing a
+// ScopeTemplate that references the group set of the ConfigMap.
+func (r *ScopeInstanceReconciler) mapGroupSetToScopeInstance(obj client.Object) (requests []reconcile.Request) {
+	if obj == nil || obj.GetNamespace() != r.GroupSetNamespace {
+		return nil
+	}
+
+	scopeTemplateList := &operatorsv1.ScopeTemplateList{}
+	if err := r.Client.List(context.TODO(), scopeTemplateList); err != nil {
+		log.Log.Error(err, "error listing scopetemplates")
+		return nil
+	}
+
+	for _, st := range scopeTemplateList.Items {
+		if !referencesGroupSet(&st, obj.GetName()) {
+			continue
+		}
+		requests = append(requests, r.mapToScopeInstance(&st)...)
+	}
+	return
+}
+
+// mapClusterRoleToScopeInstance enqueues the ScopeInstances referencing the
+// ScopeTemplates whose Bindings select the ClusterRole, so that its bindings
+// follow it as it comes and goes or its labels change.
+func (r *ScopeInstanceReconciler) mapClusterRoleToScopeInstance(obj client.Object) (requests []reconcile.Request) {
+	if obj == nil {
+		return nil
+	}
+
+	scopeTemplateList := &operatorsv1.ScopeTemplateList{}
+	if err := r.Client.List(context.TODO(), scopeTemplateList); err != nil {
+		log.Log.Error(err, "error listing scopetemplates")
+		return nil
+	}
+
+	for _, st := range scopeTemplateList.Items {
+		if !selectsClusterRole(&st, obj) {
+			continue
+		}
+		requests = append(requests, r.mapToScopeInstance(&st)...)
+	}
+	return
+}
+
+// selectsClusterRole returns whether any Binding of the ScopeTemplate
+// selects the ClusterRole by label.
+func selectsClusterRole(st *operatorsv1.ScopeTemplate, cr client.Object) bool {
+	for _, b := range st.Spec.Bindings {
+		if b.ClusterRoleSelector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(b.ClusterRoleSelector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(cr.GetLabels())) {
+			return true
+		}
+	}
+	return false
+}
+
+// referencesGroupSet returns true if any entry of the ScopeTemplate
+// references the named group set.
+func referencesGroupSet(st *operatorsv1.ScopeTemplate, name string) bool {
+	for _, cr := range st.Spec.BindingTemplates() {
+		for _, groupSet := range cr.GroupSets {
+			if groupSet == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// selectsServiceAccount returns true if the ScopeTemplate lists the
+// ServiceAccount as a subject, or any ServiceAccountSelector in the
+// ScopeTemplate matches it.
+func selectsServiceAccount(st *operatorsv1.ScopeTemplate, sa client.Object) bool {
+	for _, cr := range st.Spec.BindingTemplates() {
+		for _, subject := range cr.Subjects {
+			if subject.Kind == rbacv1.ServiceAccountKind && subject.Name == sa.GetName() && subject.Namespace == sa.GetNamespace() {
+				return true
+			}
+		}
+		if cr.ServiceAccountSelector == nil {
+			continue
+		}
+		if ns := cr.ServiceAccountSelector.Namespace; ns != "" && ns != sa.GetNamespace() {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(&cr.ServiceAccountSelector.Selector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(sa.GetLabels())) {
+			return true
+		}
+	}
+	return false
+}
+
+// bindsServiceAccountIn returns true if the ScopeTemplate lists a
+// ServiceAccount in the given namespace as a subject.
+func bindsServiceAccountIn(st *operatorsv1.ScopeTemplate, namespace string) bool {
+	for _, cr := range st.Spec.BindingTemplates() {
+		for _, subject := range cr.Subjects {
+			if subject.Kind == rbacv1.ServiceAccountKind && subject.Namespace == namespace {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// clusterRoleBindingManifest will create a ClusterRoleBinding from a
+// ClusterRoleTemplate, ScopeInstance, and ScopeTemplate
+func (r *ScopeInstanceReconciler) clusterRoleBindingManifest(cr *operatorsv1.BindingTemplate, in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate) *rbacv1.ClusterRoleBinding {
+	crb := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: r.ShadowPrefix + shortGenerateName(cr.GenerateName) + "-",
 			Labels: map[string]string{
-				scopeInstanceUIDKey:           string(in.GetUID()),
-				referenceHashKey:              hashScopeInstanceAndTemplate(in, st),
-				clusterRoleBindingGenerateKey: cr.GenerateName,
+				r.keys.key(scopeInstanceUIDKey):           r.bindingOwner(in),
+				r.keys.key(clusterRoleBindingGenerateKey): shortGenerateName(cr.GenerateName),
 			},
+			Annotations: bindingAnnotations(r.keys, in, st),
 		},
-		Subjects: cr.Subjects,
-		RoleRef: rbacv1.RoleRef{
-			Kind:     "ClusterRole",
-			Name:     cr.GenerateName,
-			APIGroup: rbacv1.GroupName,
+		Subjects: r.shadowSubjects(subjectsForScopeInstance(cr.Subjects, in)),
+		RoleRef:  roleRef(cr, in),
+	}
+
+	err := ctrl.SetControllerReference(in, crb, r.Scheme)
+	if err != nil {
+		log.Log.Error(err, "setting controller reference for ClusterRoleBinding")
+	}
+	return crb
+}
+
+// roleBindingManifest will create a RoleBinding from a
+// ClusterRoleTemplate, ScopeInstance, ScopeTemplate, and namespace
+func (r *ScopeInstanceReconciler) roleBindingManifest(cr *operatorsv1.BindingTemplate, in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate, namespace string) *rbacv1.RoleBinding {
+	rb := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: r.ShadowPrefix + shortGenerateName(cr.GenerateName) + "-",
+			Namespace:    namespace,
+			Labels: map[string]string{
+				r.keys.key(scopeInstanceUIDKey):           r.bindingOwner(in),
+				r.keys.key(clusterRoleBindingGenerateKey): shortGenerateName(cr.GenerateName),
+			},
+			Annotations: bindingAnnotations(r.keys, in, st),
 		},
+		Subjects: r.shadowSubjects(subjectsForScopeInstance(cr.Subjects, in)),
+		RoleRef:  roleRef(cr, in),
 	}
 
 	err := ctrl.SetControllerReference(in, rb, r.Scheme)
@@ -444,6 +2725,79 @@ func (r *ScopeInstanceReconciler) roleBindingManifest(cr *operatorsv1.ClusterRol
 	return rb
 }
 
+// bindingAnnotations returns the annotations of the bindings created for the
+// ScopeInstance and ScopeTemplate.
+func bindingAnnotations(keys *keyMapping, in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate) map[string]string {
+	annotations := map[string]string{
+		keys.key(referenceHashKey):           hashScopeInstanceAndTemplate(in, st),
+		keys.key(provenanceScopeInstanceKey): in.GetName(),
+		keys.key(provenanceScopeTemplateKey): st.GetName(),
+	}
+	if in.Spec.Audience != "" {
+		annotations[keys.key(audienceKey)] = in.Spec.Audience
+	}
+	return annotations
+}
+
+// bindingOwner returns the value of the scopeInstanceUIDKey label of the
+// bindings created for the ScopeInstance. Shadow bindings use a prefixed
+// value, so they are never mistaken for real ones and vice versa.
+func (r *ScopeInstanceReconciler) bindingOwner(in *operatorsv1.ScopeInstance) string {
+	return r.ShadowPrefix + string(in.GetUID())
+}
+
+// ValidateShadowPrefix returns an error if the prefix cannot be used as the
+// ShadowPrefix, as it does not fit in a label value along with a UID or does
+// not make valid binding names.
+func ValidateShadowPrefix(prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+	// UIDs are 36 characters long
+	errs := validation.IsValidLabelValue(prefix + "00000000-0000-0000-0000-000000000000")
+	errs = append(errs, validation.IsDNS1123Subdomain(prefix+"0")...)
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid shadow prefix %q: %s", prefix, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// shadowSubjects prefixes the given subjects with the ShadowPrefix, so that
+// shadow bindings do not grant anything to the real subjects.
+func (r *ScopeInstanceReconciler) shadowSubjects(subjects []rbacv1.Subject) []rbacv1.Subject {
+	if r.ShadowPrefix == "" {
+		return subjects
+	}
+
+	shadowed := make([]rbacv1.Subject, 0, len(subjects))
+	for _, subject := range subjects {
+		if subject.Kind == rbacv1.ServiceAccountKind {
+			subject.Namespace = r.ShadowPrefix + subject.Namespace
+		} else {
+			subject.Name = r.ShadowPrefix + subject.Name
+		}
+		shadowed = append(shadowed, subject)
+	}
+	return shadowed
+}
+
+// subjectsForScopeInstance returns the subjects that should be bound for the
+// given ScopeInstance, applying its GroupPrefix to any Group subjects.
+func subjectsForScopeInstance(subjects []rbacv1.Subject, in *operatorsv1.ScopeInstance) []rbacv1.Subject {
+	if in.Spec.GroupPrefix == "" {
+		return subjects
+	}
+
+	prefixed := make([]rbacv1.Subject, 0, len(subjects))
+	for _, subject := range subjects {
+		if subject.Kind == rbacv1.GroupKind {
+			subject.Name = in.Spec.GroupPrefix + subject.Name
+		}
+		prefixed = append(prefixed, subject)
+	}
+	return prefixed
+}
+
 // referenceHash is used to store a ScopeInstance.Spec
 // and ScopeTemplate.Spec. This object is used for getting
 // the combined hash of both specs.
@@ -455,22 +2809,224 @@ type referenceHash struct {
 // hashScopeInstanceAndTemplate will take in a
 // ScopeInstance and ScopeTemplate and return
 // a combined hash of the ScopeInstance.Spec and
-// ScopeTemplate.Spec fields
+// ScopeTemplate.Spec fields. The fields selecting
+// namespaces, ExpiresAt and Priority are left
+// out, as they decide where, until when and how
+// soon bindings exist rather than their content,
+// so that selecting another namespace or renewing
+// does not update every existing binding.
 func hashScopeInstanceAndTemplate(si *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate) string {
+	siSpec := si.Spec.DeepCopy()
+	siSpec.Namespaces = nil
+	siSpec.NamespaceAnnotationSelector = nil
+	siSpec.NamespaceMatchMode = ""
+	siSpec.BindInSubjectNamespaces = false
+	siSpec.ExpiresAt = nil
+	siSpec.Priority = 0
+
+	// The order of the subjects does not matter to the bindings
 	hashObj := &referenceHash{
-		ScopeInstanceSpec: &si.Spec,
-		ScopeTemplateSpec: &st.Spec,
+		ScopeInstanceSpec: siSpec,
+		ScopeTemplateSpec: withSortedSubjects(&st.Spec),
 	}
 
 	return util.HashObject(hashObj)
 }
 
-func updateStatusScopeTemplateNotFound(in *operatorsv1.ScopeInstance, err error) {
+func updateStatusScopeTemplateNotFound(in *operatorsv1.ScopeInstance, key client.ObjectKey, err error) {
 	meta.SetStatusCondition(&in.Status.Conditions, metav1.Condition{
 		Type:    operatorsv1.TypeScoped,
 		Status:  metav1.ConditionFalse,
 		Reason:  operatorsv1.ReasonScopeTemplateNotFound,
-		Message: fmt.Sprintf("getting ScopeTemplate %q: %s", in.Spec.ScopeTemplateName, err),
+		Message: fmt.Sprintf("getting ScopeTemplate %q: %s", key.Name, err),
+	})
+}
+
+func updateStatusInvalidSubjectExpression(in *operatorsv1.ScopeInstance, err error) {
+	meta.SetStatusCondition(&in.Status.Conditions, metav1.Condition{
+		Type:    operatorsv1.TypeScoped,
+		Status:  metav1.ConditionFalse,
+		Reason:  operatorsv1.ReasonInvalidSubjectExpression,
+		Message: err.Error(),
+	})
+}
+
+// updateStatusTemplateHealth surfaces a failure reported by the Templated
+// condition of the ScopeTemplate on the ScopeInstance, so that the root cause
+// is seen where the ScopeInstance is managed. The condition is only added
+// once the ScopeTemplate fails, and set back to False once it recovers.
+func updateStatusTemplateHealth(in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate) {
+	templated := meta.FindStatusCondition(st.Status.Conditions, operatorsv1.TypeTemplated)
+	unhealthy := templated != nil && templated.Status == metav1.ConditionFalse
+	if !unhealthy && meta.FindStatusCondition(in.Status.Conditions, operatorsv1.TypeTemplateUnhealthy) == nil {
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:    operatorsv1.TypeTemplateUnhealthy,
+		Status:  metav1.ConditionFalse,
+		Reason:  operatorsv1.ReasonTemplateHealthy,
+		Message: fmt.Sprintf("ScopeTemplate %s reports no failure", st.GetName()),
+	}
+	if unhealthy {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = templated.Reason
+		condition.Message = fmt.Sprintf("ScopeTemplate %s: %s", st.GetName(), templated.Message)
+	}
+	meta.SetStatusCondition(&in.Status.Conditions, condition)
+}
+
+// scopeTemplateHealthChanged returns true if the update changes the Templated
+// condition of the ScopeTemplate, which is not a spec change.
+func scopeTemplateHealthChanged(e event.UpdateEvent) bool {
+	oldST, oldOk := e.ObjectOld.(*operatorsv1.ScopeTemplate)
+	newST, newOk := e.ObjectNew.(*operatorsv1.ScopeTemplate)
+	if !oldOk || !newOk {
+		return false
+	}
+	oldCond := meta.FindStatusCondition(oldST.Status.Conditions, operatorsv1.TypeTemplated)
+	newCond := meta.FindStatusCondition(newST.Status.Conditions, operatorsv1.TypeTemplated)
+	if oldCond == nil || newCond == nil {
+		return oldCond != newCond
+	}
+	return oldCond.Status != newCond.Status || oldCond.Reason != newCond.Reason || oldCond.Message != newCond.Message
+}
+
+// updateStatusProgressing sets the Progressing condition from the bindings
+// written by the reconcile. A failed reconcile may have left bindings to
+// write, and a cache still listing deleted bindings may hide some, so both
+// are still progressing. A ScopeInstance only converges once it is Scoped:
+// one refused without an error, such as for an invalid subject expression,
+// is not progressing either, and carries the reason and message of its
+// Scoped condition.
+func updateStatusProgressing(in *operatorsv1.ScopeInstance, summary *reconcileSummary, err error) {
+	condition := metav1.Condition{
+		Type:    operatorsv1.TypeProgressing,
+		Status:  metav1.ConditionFalse,
+		Reason:  operatorsv1.ReasonConverged,
+		Message: "Every binding is as desired",
+	}
+	switch {
+	case err != nil:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = operatorsv1.ReasonScopingFailed
+		condition.Message = err.Error()
+	case summary.wrote():
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = operatorsv1.ReasonBindingsChanging
+		condition.Message = summary.writes()
+	case summary.waitingForCache():
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = operatorsv1.ReasonDeletionsUnobserved
+		condition.Message = "Waiting for the cache to observe the deleted bindings"
+	case !meta.IsStatusConditionTrue(in.Status.Conditions, operatorsv1.TypeScoped):
+		if scoped := meta.FindStatusCondition(in.Status.Conditions, operatorsv1.TypeScoped); scoped != nil {
+			condition.Reason = scoped.Reason
+			condition.Message = scoped.Message
+		} else {
+			condition.Reason = operatorsv1.ReasonScopingFailed
+			condition.Message = "The ScopeInstance is not scoped"
+		}
+	}
+	meta.SetStatusCondition(&in.Status.Conditions, condition)
+}
+
+func updateStatusInvalidBindingNameTemplate(in *operatorsv1.ScopeInstance, err error) {
+	meta.SetStatusCondition(&in.Status.Conditions, metav1.Condition{
+		Type:    operatorsv1.TypeScoped,
+		Status:  metav1.ConditionFalse,
+		Reason:  operatorsv1.ReasonInvalidBindingNameTemplate,
+		Message: err.Error(),
+	})
+}
+
+func updateStatusBindingNameCollision(in *operatorsv1.ScopeInstance, err error) {
+	meta.SetStatusCondition(&in.Status.Conditions, metav1.Condition{
+		Type:    operatorsv1.TypeScoped,
+		Status:  metav1.ConditionFalse,
+		Reason:  operatorsv1.ReasonBindingNameCollision,
+		Message: err.Error(),
+	})
+}
+
+func updateStatusOperatorSubject(in *operatorsv1.ScopeInstance, err error) {
+	meta.SetStatusCondition(&in.Status.Conditions, metav1.Condition{
+		Type:    operatorsv1.TypeScoped,
+		Status:  metav1.ConditionFalse,
+		Reason:  operatorsv1.ReasonOperatorSubject,
+		Message: err.Error(),
+	})
+}
+
+func updateStatusPolicyViolation(in *operatorsv1.ScopeInstance, err error) {
+	meta.SetStatusCondition(&in.Status.Conditions, metav1.Condition{
+		Type:    operatorsv1.TypeScoped,
+		Status:  metav1.ConditionFalse,
+		Reason:  operatorsv1.ReasonPolicyViolation,
+		Message: err.Error(),
+	})
+}
+
+func updateStatusClusterWideViolation(in *operatorsv1.ScopeInstance, err error) {
+	meta.SetStatusCondition(&in.Status.Conditions, metav1.Condition{
+		Type:    operatorsv1.TypeScoped,
+		Status:  metav1.ConditionFalse,
+		Reason:  operatorsv1.ReasonClusterWideViolation,
+		Message: err.Error(),
+	})
+}
+
+func updateStatusInvalidClusterRoleSelector(in *operatorsv1.ScopeInstance, err error) {
+	meta.SetStatusCondition(&in.Status.Conditions, metav1.Condition{
+		Type:    operatorsv1.TypeScoped,
+		Status:  metav1.ConditionFalse,
+		Reason:  operatorsv1.ReasonInvalidClusterRoleSelector,
+		Message: err.Error(),
+	})
+}
+
+func updateStatusTooManySubjects(in *operatorsv1.ScopeInstance, err error) {
+	meta.SetStatusCondition(&in.Status.Conditions, metav1.Condition{
+		Type:    operatorsv1.TypeScoped,
+		Status:  metav1.ConditionFalse,
+		Reason:  operatorsv1.ReasonTooManySubjects,
+		Message: err.Error(),
+	})
+}
+
+func updateStatusDryRun(in *operatorsv1.ScopeInstance, bindings, discrepancies int) {
+	meta.SetStatusCondition(&in.Status.Conditions, metav1.Condition{
+		Type:    operatorsv1.TypeScoped,
+		Status:  metav1.ConditionFalse,
+		Reason:  operatorsv1.ReasonDryRun,
+		Message: fmt.Sprintf("dry run of %d (Cluster)RoleBindings found %d discrepancies, nothing was written", bindings, discrepancies),
+	})
+}
+
+func updateStatusCircuitOpen(in *operatorsv1.ScopeInstance, failures int, interval time.Duration, err error) {
+	meta.SetStatusCondition(&in.Status.Conditions, metav1.Condition{
+		Type:    operatorsv1.TypeScoped,
+		Status:  metav1.ConditionFalse,
+		Reason:  operatorsv1.ReasonCircuitOpen,
+		Message: fmt.Sprintf("%d consecutive reconciles failed, retrying every %s: %v", failures, interval, err),
+	})
+}
+
+func updateStatusDuplicateBindings(in *operatorsv1.ScopeInstance, err error) {
+	meta.SetStatusCondition(&in.Status.Conditions, metav1.Condition{
+		Type:    operatorsv1.TypeScoped,
+		Status:  metav1.ConditionFalse,
+		Reason:  operatorsv1.ReasonDuplicateBindings,
+		Message: err.Error(),
+	})
+}
+
+func updateStatusExpired(in *operatorsv1.ScopeInstance) {
+	meta.SetStatusCondition(&in.Status.Conditions, metav1.Condition{
+		Type:    operatorsv1.TypeScoped,
+		Status:  metav1.ConditionFalse,
+		Reason:  operatorsv1.ReasonExpired,
+		Message: fmt.Sprintf("ScopeInstance expired at %s", in.Spec.ExpiresAt.UTC().Format(time.RFC3339)),
 	})
 }
 
@@ -483,6 +3039,24 @@ func updateStatusScopingFailed(in *operatorsv1.ScopeInstance, err error) {
 	})
 }
 
+func updateStatusWaitingForClusterRole(in *operatorsv1.ScopeInstance, clusterRoles []string) {
+	meta.SetStatusCondition(&in.Status.Conditions, metav1.Condition{
+		Type:    operatorsv1.TypeScoped,
+		Status:  metav1.ConditionFalse,
+		Reason:  operatorsv1.ReasonWaitingForClusterRole,
+		Message: fmt.Sprintf("waiting for ClusterRoles to be created: %s", strings.Join(clusterRoles, ", ")),
+	})
+}
+
+func updateStatusDeletionGuard(in *operatorsv1.ScopeInstance, deletes, limit int) {
+	meta.SetStatusCondition(&in.Status.Conditions, metav1.Condition{
+		Type:    operatorsv1.TypeScoped,
+		Status:  metav1.ConditionFalse,
+		Reason:  operatorsv1.ReasonDeletionGuard,
+		Message: fmt.Sprintf("refusing to delete %d (Cluster)RoleBindings, more than the limit of %d; annotate with %s=true to confirm", deletes, limit, allowBulkDeleteKey),
+	})
+}
+
 func updateStatusScopingSuccessful(in *operatorsv1.ScopeInstance, msg string) {
 	meta.SetStatusCondition(&in.Status.Conditions, metav1.Condition{
 		Type:    operatorsv1.TypeScoped,
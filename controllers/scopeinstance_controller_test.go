@@ -17,17 +17,39 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
 	"operator-framework/oria-operator/util"
@@ -92,30 +114,4223 @@ var _ = Describe("ScopeInstanceReconciler", func() {
 			}
 		})
 		It("should return the hash from the two objects", func() {
+			siSpec := si.Spec.DeepCopy()
+			siSpec.Namespaces = nil
 			expected := util.HashObject(&referenceHash{
-				ScopeInstanceSpec: &si.Spec,
+				ScopeInstanceSpec: siSpec,
 				ScopeTemplateSpec: &st.Spec,
 			})
 			thehash := hashScopeInstanceAndTemplate(si, st)
 			Expect(expected).To(Equal(thehash))
 		})
-		It("should return a different hash if the scopeinstance changes", func() {
-			notexpected := util.HashObject(&referenceHash{
-				ScopeInstanceSpec: &si.Spec,
-				ScopeTemplateSpec: &st.Spec,
-			})
-			si.Spec = operatorsv1.ScopeInstanceSpec{}
-			thehash := hashScopeInstanceAndTemplate(si, st)
-			Expect(notexpected).ToNot(Equal(thehash))
+		It("should return a different hash if the scopeinstance changes", func() {
+			notexpected := util.HashObject(&referenceHash{
+				ScopeInstanceSpec: &si.Spec,
+				ScopeTemplateSpec: &st.Spec,
+			})
+			si.Spec = operatorsv1.ScopeInstanceSpec{}
+			thehash := hashScopeInstanceAndTemplate(si, st)
+			Expect(notexpected).ToNot(Equal(thehash))
+		})
+		It("should return the same hash if the selected namespaces change", func() {
+			expected := hashScopeInstanceAndTemplate(si, st)
+			si.Spec.Namespaces = append(si.Spec.Namespaces, "other-ns")
+			si.Spec.NamespaceAnnotationSelector = map[string]string{"team": "a"}
+			Expect(hashScopeInstanceAndTemplate(si, st)).To(Equal(expected))
+		})
+		It("should return a different hash if the scopetemplate changes", func() {
+			notexpected := util.HashObject(&referenceHash{
+				ScopeInstanceSpec: &si.Spec,
+				ScopeTemplateSpec: &st.Spec,
+			})
+			st.Spec = operatorsv1.ScopeTemplateSpec{}
+			thehash := hashScopeInstanceAndTemplate(si, st)
+			Expect(notexpected).ToNot(Equal(thehash))
+		})
+	})
+
+	Describe("resolveNamespaces", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+						Name:        "annotated",
+						Annotations: map[string]string{"team": "a"},
+					}},
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+						Name:        "annotated-other-team",
+						Annotations: map[string]string{"team": "b"},
+					}},
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+						Name: "unannotated",
+					}},
+				),
+				Scheme: scheme.Scheme,
+			}
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-annotations",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: "scopetemplate-annotations",
+				},
+			}
+		})
+		It("should return the listed namespaces if no annotation selector is set", func() {
+			si.Spec.Namespaces = []string{"annotated", "unannotated"}
+			Expect(isClusterScoped(si)).To(BeFalse())
+			Expect(r.resolveNamespaces(ctx, si)).To(Equal([]string{"annotated", "unannotated"}))
+		})
+		It("should return every annotated namespace if no namespaces are listed", func() {
+			si.Spec.NamespaceAnnotationSelector = map[string]string{"team": "a"}
+			Expect(isClusterScoped(si)).To(BeFalse())
+			Expect(r.resolveNamespaces(ctx, si)).To(Equal([]string{"annotated"}))
+		})
+		It("should return the listed namespaces along with the annotated ones", func() {
+			si.Spec.Namespaces = []string{"unannotated", "annotated"}
+			si.Spec.NamespaceAnnotationSelector = map[string]string{"team": "a"}
+			Expect(r.resolveNamespaces(ctx, si)).To(Equal([]string{"annotated", "unannotated"}))
+
+			si.Spec.Namespaces = []string{"unannotated"}
+			si.Spec.NamespaceAnnotationSelector = map[string]string{"team": "b"}
+			si.Spec.NamespaceMatchMode = operatorsv1.NamespaceMatchModeUnion
+			Expect(r.resolveNamespaces(ctx, si)).To(Equal([]string{"unannotated", "annotated-other-team"}))
+		})
+		It("should only return the listed namespaces that are annotated in the Intersection mode", func() {
+			si.Spec.Namespaces = []string{"annotated-other-team", "unannotated"}
+			si.Spec.NamespaceAnnotationSelector = map[string]string{"team": "b"}
+			si.Spec.NamespaceMatchMode = operatorsv1.NamespaceMatchModeIntersection
+			Expect(r.resolveNamespaces(ctx, si)).To(Equal([]string{"annotated-other-team"}))
+
+			si.Spec.Namespaces = []string{"unannotated"}
+			si.Spec.NamespaceAnnotationSelector = map[string]string{"team": "a"}
+			Expect(r.resolveNamespaces(ctx, si)).To(BeEmpty())
+		})
+		It("should return no namespaces if none are annotated", func() {
+			si.Spec.NamespaceAnnotationSelector = map[string]string{"team": "c"}
+			Expect(isClusterScoped(si)).To(BeFalse())
+			Expect(r.resolveNamespaces(ctx, si)).To(BeEmpty())
+		})
+	})
+
+	When("a ScopeInstance selects namespaces by annotation", func() {
+		var (
+			r           *ScopeInstanceReconciler
+			si          *operatorsv1.ScopeInstance
+			annotated   *corev1.Namespace
+			reconcileSI = func() {
+				_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+				Expect(err).NotTo(HaveOccurred())
+			}
+		)
+		BeforeEach(func() {
+			annotated = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "annotated",
+				Annotations: map[string]string{"team": "a"},
+			}}
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-annotations",
+					UID:  "scopeinstance-annotations-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName:           "scopetemplate-annotations",
+					NamespaceAnnotationSelector: map[string]string{"team": "a"},
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(
+					annotated,
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "unannotated"}},
+					si,
+					newTestScopeTemplate("scopetemplate-annotations"),
+					newTestClusterRole("test"),
+				),
+				Scheme: scheme.Scheme,
+			}
+		})
+
+		It("should only create RoleBindings in the annotated namespaces", func() {
+			reconcileSI()
+
+			Expect(listFakeRoleBindings(r.Client, "annotated", si)).To(HaveLen(1))
+			Expect(listFakeRoleBindings(r.Client, "unannotated", si)).To(BeEmpty())
+			Expect(listFakeClusterRoleBindings(r.Client, si)).To(BeEmpty())
+		})
+
+		It("should delete the RoleBinding once the namespace annotation is removed", func() {
+			reconcileSI()
+			Expect(listFakeRoleBindings(r.Client, "annotated", si)).To(HaveLen(1))
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(annotated), annotated)).To(Succeed())
+			annotated.SetAnnotations(nil)
+			Expect(r.Client.Update(ctx, annotated)).To(Succeed())
+
+			reconcileSI()
+			Expect(listFakeRoleBindings(r.Client, "annotated", si)).To(BeEmpty())
+		})
+
+		It("should report the number of resolved namespaces", func() {
+			reconcileSI()
+			Expect(testutil.ToFloat64(resolvedNamespaces.WithLabelValues(si.GetName()))).To(Equal(1.0))
+
+			Expect(r.Client.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+				Name:        "annotated-2",
+				Annotations: map[string]string{"team": "a"},
+			}})).To(Succeed())
+			reconcileSI()
+			Expect(testutil.ToFloat64(resolvedNamespaces.WithLabelValues(si.GetName()))).To(Equal(2.0))
+
+			By("forgetting the ScopeInstance once it is deleted")
+			Expect(r.Client.Delete(ctx, si)).To(Succeed())
+			reconcileSI()
+			Expect(resolvedNamespaces.DeleteLabelValues(si.GetName())).To(BeFalse())
+		})
+
+		It("should only requeue ScopeInstances that select namespaces by annotation", func() {
+			Expect(r.Client.Create(ctx, &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-no-annotations"},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: "scopetemplate-annotations",
+				},
+			})).To(Succeed())
+
+			Expect(r.mapNamespaceToScopeInstance(annotated)).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: si.GetName()},
+			}))
+		})
+
+		It("should only enqueue namespace updates that change a selected annotation", func() {
+			updated := annotated.DeepCopy()
+			updated.Annotations["unrelated"] = "true"
+			Expect(r.namespaceSelectionChanged(event.UpdateEvent{ObjectOld: annotated, ObjectNew: updated})).To(BeFalse())
+
+			updated = annotated.DeepCopy()
+			updated.Labels = map[string]string{"unrelated": "true"}
+			Expect(r.namespaceSelectionChanged(event.UpdateEvent{ObjectOld: annotated, ObjectNew: updated})).To(BeFalse())
+
+			updated = annotated.DeepCopy()
+			updated.Annotations["team"] = "b"
+			Expect(r.namespaceSelectionChanged(event.UpdateEvent{ObjectOld: annotated, ObjectNew: updated})).To(BeTrue())
+
+			updated = annotated.DeepCopy()
+			delete(updated.Annotations, "team")
+			Expect(r.namespaceSelectionChanged(event.UpdateEvent{ObjectOld: annotated, ObjectNew: updated})).To(BeTrue())
+		})
+	})
+
+	Describe("updateStatus", func() {
+		var (
+			c  *conflictingStatusClient
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-conflict",
+					UID:  "scopeinstance-conflict-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: "nonexistent-st",
+				},
+			}
+			c = &conflictingStatusClient{
+				Client:    newFakeClient(si),
+				conflicts: 1,
+			}
+			r = &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme}
+		})
+		It("should retry the status update if it conflicts", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.conflicts).To(Equal(0))
+			Expect(c.patches).To(Equal(2))
+
+			updated := &operatorsv1.ScopeInstance{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(si), updated)).To(Succeed())
+			cond := meta.FindStatusCondition(updated.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonScopeTemplateNotFound))
+		})
+		It("should keep the conditions of a concurrent writer", func() {
+			// Label and default the ScopeInstance up front, as the fake client
+			// would also write the stale status with the update that follows
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.SetLabels(map[string]string{scopeTemplateNameKey: si.Spec.ScopeTemplateName})
+			si.Default()
+			Expect(c.Update(ctx, si)).To(Succeed())
+
+			c.conflicts = 0
+			c.race = func() {
+				// Another writer sets its own condition after the reconciler read the ScopeInstance
+				latest := &operatorsv1.ScopeInstance{}
+				Expect(c.Client.Get(ctx, client.ObjectKeyFromObject(si), latest)).To(Succeed())
+				meta.SetStatusCondition(&latest.Status.Conditions, metav1.Condition{
+					Type:   "Audited",
+					Status: metav1.ConditionTrue,
+					Reason: "AuditPassed",
+				})
+				Expect(c.Client.Status().Update(ctx, latest)).To(Succeed())
+			}
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.patches).To(Equal(2))
+
+			updated := &operatorsv1.ScopeInstance{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(si), updated)).To(Succeed())
+			Expect(meta.FindStatusCondition(updated.Status.Conditions, "Audited")).NotTo(BeNil())
+			cond := meta.FindStatusCondition(updated.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonScopeTemplateNotFound))
+		})
+	})
+
+	When("a ScopeInstance sets a GroupPrefix", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+			st *operatorsv1.ScopeTemplate
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-groupprefix")
+			st.Spec.ClusterRoles[0].Subjects = append(st.Spec.ClusterRoles[0].Subjects, rbacv1.Subject{
+				Kind:      "ServiceAccount",
+				Name:      "default",
+				Namespace: "default",
+			})
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-groupprefix",
+					UID:  "scopeinstance-groupprefix-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					GroupPrefix:       "oidc:",
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test")),
+				Scheme: scheme.Scheme,
+			}
+		})
+
+		It("should prefix the Group subjects of the bindings it creates", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			crbs := listFakeClusterRoleBindings(r.Client, si)
+			Expect(crbs).To(HaveLen(1))
+			Expect(crbs[0].Subjects).To(Equal([]rbacv1.Subject{
+				{Kind: "Group", APIGroup: "rbac.authorization.k8s.io", Name: "oidc:manager"},
+				{Kind: "ServiceAccount", Name: "default", Namespace: "default"},
+			}))
+		})
+
+		It("should update the bindings if the GroupPrefix changes", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			oldHash := hashScopeInstanceAndTemplate(si, st)
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.GroupPrefix = "ldap:"
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+			Expect(hashScopeInstanceAndTemplate(si, st)).NotTo(Equal(oldHash))
+
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			crbs := listFakeClusterRoleBindings(r.Client, si)
+			Expect(crbs).To(HaveLen(1))
+			Expect(crbs[0].Subjects).To(ContainElement(rbacv1.Subject{
+				Kind: "Group", APIGroup: "rbac.authorization.k8s.io", Name: "ldap:manager",
+			}))
+			Expect(crbs[0].Annotations).To(HaveKeyWithValue(referenceHashKey, hashScopeInstanceAndTemplate(si, st)))
+		})
+	})
+
+	When("the ClusterRole referenced by the ScopeTemplate does not exist yet", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-waiting")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-waiting",
+					UID:  "scopeinstance-waiting-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st),
+				Scheme: scheme.Scheme,
+			}
+		})
+
+		It("should wait for the ClusterRole before creating bindings", func() {
+			res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(Equal(clusterRoleRequeueDelay))
+			Expect(listFakeClusterRoleBindings(r.Client, si)).To(BeEmpty())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			cond := meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonWaitingForClusterRole))
+
+			By("creating the ClusterRole")
+			Expect(r.Client.Create(ctx, newTestClusterRole("test"))).To(Succeed())
+
+			res, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(BeZero())
+			Expect(listFakeClusterRoleBindings(r.Client, si)).To(HaveLen(1))
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			cond = meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonScopingSuccessful))
+		})
+	})
+
+	When("bindings track the hash of the ScopeInstance and ScopeTemplate", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+			st *operatorsv1.ScopeTemplate
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-hash-annotation")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-hash-annotation",
+					UID:  "scopeinstance-hash-annotation-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"test-ns"},
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test")),
+				Scheme: scheme.Scheme,
+			}
+		})
+
+		It("should store the hash as an annotation and select bindings by UID label", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			rbs := listFakeRoleBindings(r.Client, "test-ns", si)
+			Expect(rbs).To(HaveLen(1))
+			Expect(rbs[0].Labels).To(Equal(map[string]string{
+				scopeInstanceUIDKey:           string(si.GetUID()),
+				clusterRoleBindingGenerateKey: "test",
+			}))
+			Expect(rbs[0].Annotations).To(HaveKeyWithValue(referenceHashKey, hashScopeInstanceAndTemplate(si, st)))
+		})
+
+		It("should remove the hash label of bindings created before it moved to an annotation", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			rbs := listFakeRoleBindings(r.Client, "test-ns", si)
+			Expect(rbs).To(HaveLen(1))
+			rbs[0].Labels[legacyReferenceHashKey] = hashScopeInstanceAndTemplate(si, st)
+			Expect(r.Client.Update(ctx, &rbs[0])).To(Succeed())
+
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			updated := listFakeRoleBindings(r.Client, "test-ns", si)
+			Expect(updated).To(HaveLen(1))
+			Expect(updated[0].GetName()).To(Equal(rbs[0].GetName()))
+			Expect(updated[0].Labels).NotTo(HaveKey(legacyReferenceHashKey))
+			expectIdempotentReconcile(r, si.GetName())
+		})
+
+		It("should delete bindings with an out of date hash annotation", func() {
+			stale := &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "stale",
+					Namespace: "other-ns",
+					Labels: map[string]string{
+						scopeInstanceUIDKey:           string(si.GetUID()),
+						clusterRoleBindingGenerateKey: "test",
+					},
+					Annotations: map[string]string{
+						referenceHashKey: "stale-hash",
+					},
+				},
+				RoleRef: rbacv1.RoleRef{Kind: "ClusterRole", Name: "test", APIGroup: rbacv1.GroupName},
+			}
+			Expect(r.Client.Create(ctx, stale)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(listFakeRoleBindings(r.Client, "other-ns", si)).To(BeEmpty())
+			Expect(listFakeRoleBindings(r.Client, "test-ns", si)).To(HaveLen(1))
+		})
+
+		It("should update bindings whose hash annotation differs", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(st), st)).To(Succeed())
+			st.Spec.ClusterRoles[0].Subjects[0].Name = "other-manager"
+			Expect(r.Client.Update(ctx, st)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			rbs := listFakeRoleBindings(r.Client, "test-ns", si)
+			Expect(rbs).To(HaveLen(1))
+			Expect(rbs[0].Annotations).To(HaveKeyWithValue(referenceHashKey, hashScopeInstanceAndTemplate(si, st)))
+			Expect(rbs[0].Subjects[0].Name).To(Equal("other-manager"))
+		})
+
+		It("should only create the binding of an added namespace", func() {
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.Namespaces = []string{"test-ns", "other-ns"}
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			resourceVersions := map[string]string{}
+			for _, ns := range si.Spec.Namespaces {
+				rbs := listFakeRoleBindings(r.Client, ns, si)
+				Expect(rbs).To(HaveLen(1))
+				resourceVersions[ns] = rbs[0].GetResourceVersion()
+			}
+
+			By("appending a third namespace")
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.Namespaces = append(si.Spec.Namespaces, "third-ns")
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(listFakeRoleBindings(r.Client, "third-ns", si)).To(HaveLen(1))
+			for ns, resourceVersion := range resourceVersions {
+				rbs := listFakeRoleBindings(r.Client, ns, si)
+				Expect(rbs).To(HaveLen(1))
+				Expect(rbs[0].GetResourceVersion()).To(Equal(resourceVersion), ns)
+			}
+		})
+
+		It("should not requeue on the delete events of bindings it deleted itself", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			deleted := listFakeRoleBindings(r.Client, "test-ns", si)
+			Expect(deleted).To(HaveLen(1))
+
+			By("deselecting the namespace of the RoleBinding")
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.Namespaces = []string{"other-ns"}
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listFakeRoleBindings(r.Client, "test-ns", si)).To(BeEmpty())
+
+			Expect(r.notSelfDeleted(event.DeleteEvent{Object: &deleted[0]})).To(BeFalse())
+
+			By("requeueing once the binding is deleted by someone else")
+			other := listFakeRoleBindings(r.Client, "other-ns", si)
+			Expect(other).To(HaveLen(1))
+			Expect(r.Client.Delete(ctx, &other[0])).To(Succeed())
+			Expect(r.notSelfDeleted(event.DeleteEvent{Object: &other[0]})).To(BeTrue())
+			Expect(r.notSelfDeleted(event.DeleteEvent{Object: &deleted[0]})).To(BeTrue())
+		})
+
+		It("should replace the RoleBindings once the ScopeInstance becomes cluster scoped", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.Namespaces = nil
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(listFakeRoleBindings(r.Client, "test-ns", si)).To(BeEmpty())
+			Expect(listFakeClusterRoleBindings(r.Client, si)).To(HaveLen(1))
+		})
+	})
+
+	When("the ScopeTemplate is missing for less than the grace period", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+			st *operatorsv1.ScopeTemplate
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-grace-period")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-grace-period",
+					UID:  "scopeinstance-grace-period-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"test-ns"},
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client:                   newFakeClient(si, st, newTestClusterRole("test")),
+				Scheme:                   scheme.Scheme,
+				ScopeTemplateGracePeriod: time.Minute,
+			}
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listFakeRoleBindings(r.Client, "test-ns", si)).To(HaveLen(1))
+		})
+
+		It("should not delete bindings when the ScopeTemplate is recreated", func() {
+			By("deleting the ScopeTemplate")
+			Expect(r.Client.Delete(ctx, st)).To(Succeed())
+
+			res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(res.RequeueAfter).To(BeNumerically("<=", time.Minute))
+			Expect(listFakeRoleBindings(r.Client, "test-ns", si)).To(HaveLen(1))
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			cond := meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonScopeTemplateNotFound))
+
+			By("recreating the ScopeTemplate")
+			Expect(r.Client.Create(ctx, newTestScopeTemplate("scopetemplate-grace-period"))).To(Succeed())
+
+			res, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(BeZero())
+			Expect(listFakeRoleBindings(r.Client, "test-ns", si)).To(HaveLen(1))
+			Expect(r.templateMissingSince).NotTo(HaveKey(si.GetName()))
+		})
+
+		It("should delete bindings once the grace period has passed", func() {
+			Expect(r.Client.Delete(ctx, st)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listFakeRoleBindings(r.Client, "test-ns", si)).To(HaveLen(1))
+
+			r.templateMissingSince[si.GetName()] = time.Now().Add(-2 * time.Minute)
+
+			res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(BeZero())
+			Expect(listFakeRoleBindings(r.Client, "test-ns", si)).To(BeEmpty())
+		})
+	})
+
+	When("a ScopeTemplate selects ServiceAccounts by label", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+			st *operatorsv1.ScopeTemplate
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-sa-selector")
+			st.Spec.ClusterRoles[0].ServiceAccountSelector = &operatorsv1.ServiceAccountSelector{
+				Namespace: "team-ns",
+				Selector: metav1.LabelSelector{
+					MatchLabels: map[string]string{"team": "x"},
+				},
+			}
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-sa-selector",
+					UID:  "scopeinstance-sa-selector-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test"),
+					newTestServiceAccount("team-ns", "matching", map[string]string{"team": "x"}),
+					newTestServiceAccount("team-ns", "other-team", map[string]string{"team": "y"}),
+					newTestServiceAccount("other-ns", "other-namespace", map[string]string{"team": "x"}),
+				),
+				Scheme: scheme.Scheme,
+			}
+		})
+
+		It("should bind the matching ServiceAccounts", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			crbs := listFakeClusterRoleBindings(r.Client, si)
+			Expect(crbs).To(HaveLen(1))
+			Expect(crbs[0].Subjects).To(Equal([]rbacv1.Subject{
+				st.Spec.ClusterRoles[0].Subjects[0],
+				{Kind: rbacv1.ServiceAccountKind, Name: "matching", Namespace: "team-ns"},
+			}))
+		})
+
+		It("should update the bindings when a matching ServiceAccount is added or removed", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("adding a matching ServiceAccount")
+			added := newTestServiceAccount("team-ns", "added", map[string]string{"team": "x"})
+			Expect(r.Client.Create(ctx, added)).To(Succeed())
+			Expect(r.mapServiceAccountToScopeInstance(added)).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}},
+			))
+
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			crbs := listFakeClusterRoleBindings(r.Client, si)
+			Expect(crbs).To(HaveLen(1))
+			Expect(crbs[0].Subjects).To(ContainElement(rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "added", Namespace: "team-ns"}))
+			Expect(crbs[0].Subjects).To(HaveLen(3))
+
+			By("removing a matching ServiceAccount")
+			Expect(r.Client.Delete(ctx, added)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			crbs = listFakeClusterRoleBindings(r.Client, si)
+			Expect(crbs).To(HaveLen(1))
+			Expect(crbs[0].Subjects).NotTo(ContainElement(rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "added", Namespace: "team-ns"}))
+			Expect(crbs[0].Subjects).To(HaveLen(2))
+		})
+
+		It("should not enqueue ScopeInstances for ServiceAccounts that do not match", func() {
+			Expect(r.mapServiceAccountToScopeInstance(newTestServiceAccount("team-ns", "unrelated", nil))).To(BeEmpty())
+			Expect(r.mapServiceAccountToScopeInstance(newTestServiceAccount("other-ns", "unrelated", map[string]string{"team": "x"}))).To(BeEmpty())
+		})
+	})
+
+	When("a ScopeInstance references a ScopeTemplate in another namespace", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+			st *operatorsv1.ScopeTemplate
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-ref")
+			st.SetNamespace("templates")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-ref",
+					UID:  "scopeinstance-ref-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateRef: &operatorsv1.ScopeTemplateReference{
+						Name:      st.GetName(),
+						Namespace: st.GetNamespace(),
+					},
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test")),
+				Scheme: scheme.Scheme,
+			}
+		})
+
+		It("should create bindings for the referenced ScopeTemplate", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listFakeClusterRoleBindings(r.Client, si)).To(HaveLen(1))
+		})
+
+		It("should only enqueue the ScopeInstance for the referenced ScopeTemplate", func() {
+			Expect(r.mapToScopeInstance(st)).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}},
+			))
+			Expect(r.mapToScopeInstance(newTestScopeTemplate(st.GetName()))).To(BeEmpty())
+		})
+
+		It("should enqueue the referenced ScopeTemplate", func() {
+			str := &ScopeTemplateReconciler{Client: r.Client, Scheme: scheme.Scheme}
+			Expect(str.mapToScopeTemplate(si)).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "templates", Name: st.GetName()}},
+			))
+		})
+
+		It("should prefer ScopeTemplateRef over ScopeTemplateName", func() {
+			si.Spec.ScopeTemplateName = "ignored"
+			Expect(scopeTemplateKey(si, "")).To(Equal(client.ObjectKey{Namespace: "templates", Name: st.GetName()}))
+
+			si.Spec.ScopeTemplateRef = nil
+			Expect(scopeTemplateKey(si, "")).To(Equal(client.ObjectKey{Name: "ignored"}))
+		})
+	})
+
+	When("a ScopeInstance is labeled with its ScopeTemplate", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-label")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-label",
+					UID:  "scopeinstance-label-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestScopeTemplate("scopetemplate-label-other"), newTestClusterRole("test")),
+				Scheme: scheme.Scheme,
+			}
+		})
+
+		It("should set and update the label as the ScopeTemplateName changes", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.Labels).To(HaveKeyWithValue(scopeTemplateNameKey, "scopetemplate-label"))
+			cond := meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonScopingSuccessful))
+
+			By("selecting the ScopeInstance by the label")
+			siList := &operatorsv1.ScopeInstanceList{}
+			Expect(r.Client.List(ctx, siList, client.MatchingLabels{scopeTemplateNameKey: "scopetemplate-label"})).To(Succeed())
+			Expect(siList.Items).To(HaveLen(1))
+
+			By("changing the ScopeTemplateName")
+			si.Spec.ScopeTemplateName = "scopetemplate-label-other"
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.Labels).To(HaveKeyWithValue(scopeTemplateNameKey, "scopetemplate-label-other"))
+		})
+	})
+
+	When("a ClusterRole has a very long generateName", func() {
+		var (
+			r            *ScopeInstanceReconciler
+			si           *operatorsv1.ScopeInstance
+			generateName string
+		)
+		BeforeEach(func() {
+			generateName = strings.Repeat("a", 250)
+			st := newTestScopeTemplate("scopetemplate-long-name")
+			st.Spec.ClusterRoles[0].GenerateName = generateName
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-long-name",
+					UID:  "scopeinstance-long-name-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"test-ns"},
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole(generateName)),
+				Scheme: scheme.Scheme,
+			}
+		})
+
+		It("should create a binding with a valid name and labels", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			rbs := listFakeRoleBindings(r.Client, "test-ns", si)
+			Expect(rbs).To(HaveLen(1))
+			Expect(len(rbs[0].GetGenerateName())).To(BeNumerically("<=", validation.LabelValueMaxLength+1))
+			Expect(validation.IsDNS1123Subdomain(rbs[0].GetName())).To(BeEmpty())
+			Expect(rbs[0].GetGenerateName()).To(HavePrefix(strings.Repeat("a", 40)))
+			for _, value := range rbs[0].GetLabels() {
+				Expect(validation.IsValidLabelValue(value)).To(BeEmpty())
+			}
+			Expect(rbs[0].RoleRef.Name).To(Equal(generateName))
+
+			By("finding the binding again on the next reconcile")
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listFakeRoleBindings(r.Client, "test-ns", si)).To(HaveLen(1))
+		})
+	})
+
+	When("bindings are created and deleted", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-metrics")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-metrics",
+					UID:  "scopeinstance-metrics-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1", "ns-2"},
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test")),
+				Scheme: scheme.Scheme,
+			}
+		})
+
+		It("should count the bindings created and deleted by kind", func() {
+			createdRBs := testutil.ToFloat64(bindingsCreated.WithLabelValues("RoleBinding"))
+			createdCRBs := testutil.ToFloat64(bindingsCreated.WithLabelValues("ClusterRoleBinding"))
+			deletedRBs := testutil.ToFloat64(bindingsDeleted.WithLabelValues("RoleBinding"))
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testutil.ToFloat64(bindingsCreated.WithLabelValues("RoleBinding"))).To(Equal(createdRBs + 2))
+
+			By("not counting anything on a steady state reconcile")
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testutil.ToFloat64(bindingsCreated.WithLabelValues("RoleBinding"))).To(Equal(createdRBs + 2))
+			Expect(testutil.ToFloat64(bindingsDeleted.WithLabelValues("RoleBinding"))).To(Equal(deletedRBs))
+
+			By("making the ScopeInstance cluster scoped")
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.Namespaces = nil
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(testutil.ToFloat64(bindingsCreated.WithLabelValues("ClusterRoleBinding"))).To(Equal(createdCRBs + 1))
+			Expect(testutil.ToFloat64(bindingsDeleted.WithLabelValues("RoleBinding"))).To(Equal(deletedRBs + 2))
+		})
+
+		It("should count the binding operations by kind and outcome", func() {
+			operations := func(op, outcome string) float64 {
+				return testutil.ToFloat64(bindingOperations.WithLabelValues("RoleBinding", op, outcome))
+			}
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("moving the ScopeInstance from ns-1 to ns-3")
+			created, updated, deleted := operations(opCreate, outcomeSuccess), operations(opUpdate, outcomeSuccess), operations(opDelete, outcomeSuccess)
+			failed := operations(opCreate, outcomeError)
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.Namespaces = []string{"ns-2", "ns-3"}
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(operations(opCreate, outcomeSuccess)).To(Equal(created + 1))
+			Expect(operations(opDelete, outcomeSuccess)).To(Equal(deleted + 1))
+			Expect(operations(opUpdate, outcomeSuccess)).To(Equal(updated))
+			Expect(operations(opCreate, outcomeError)).To(Equal(failed))
+
+			By("failing to create the RoleBinding in ns-4")
+			r.Client = &failingCreateClient{Client: r.Client, namespace: "ns-4"}
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.Namespaces = append(si.Spec.Namespaces, "ns-4")
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).To(HaveOccurred())
+			Expect(operations(opCreate, outcomeError)).To(Equal(failed + 1))
+		})
+	})
+
+	When("bindings are swapped atomically", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			c  *failingCreateClient
+			si *operatorsv1.ScopeInstance
+			st *operatorsv1.ScopeTemplate
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-atomic")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-atomic",
+					UID:  "scopeinstance-atomic-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1", "ns-2"},
+				},
+			}
+			c = &failingCreateClient{Client: newFakeClient(si, st, newTestClusterRole("test"))}
+			r = &ScopeInstanceReconciler{
+				Client:            c,
+				Scheme:            scheme.Scheme,
+				AtomicBindingSwap: true,
+			}
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should retain the old bindings if creating the new ones fails", func() {
+			oldHash := hashScopeInstanceAndTemplate(si, st)
+
+			By("changing the subjects of the ScopeTemplate")
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(st), st)).To(Succeed())
+			st.Spec.ClusterRoles[0].Subjects[0].Name = "other-manager"
+			Expect(r.Client.Update(ctx, st)).To(Succeed())
+			newHash := hashScopeInstanceAndTemplate(si, st)
+
+			By("failing to create the new RoleBinding in ns-2")
+			c.namespace = "ns-2"
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).To(HaveOccurred())
+
+			for _, ns := range si.Spec.Namespaces {
+				var hashes []string
+				for _, rb := range listFakeRoleBindings(r.Client, ns, si) {
+					hashes = append(hashes, rb.Annotations[referenceHashKey])
+					if rb.Annotations[referenceHashKey] == oldHash {
+						Expect(rb.Subjects[0].Name).To(Equal("manager"))
+					}
+				}
+				Expect(hashes).To(ContainElement(oldHash), "old RoleBinding in %s should be retained", ns)
+			}
+
+			By("creating the new RoleBindings once the failure is resolved")
+			c.namespace = ""
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, ns := range si.Spec.Namespaces {
+				rbs := listFakeRoleBindings(r.Client, ns, si)
+				Expect(rbs).To(HaveLen(1))
+				Expect(rbs[0].Annotations).To(HaveKeyWithValue(referenceHashKey, newHash))
+				Expect(rbs[0].Subjects[0].Name).To(Equal("other-manager"))
+			}
+		})
+	})
+
+	When("a reconcile would delete more bindings than allowed", func() {
+		var (
+			r        *ScopeInstanceReconciler
+			recorder *record.FakeRecorder
+			si       *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-deletion-guard")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-deletion-guard",
+					UID:  "scopeinstance-deletion-guard-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+				},
+			}
+			for i := 0; i < 10; i++ {
+				si.Spec.Namespaces = append(si.Spec.Namespaces, fmt.Sprintf("ns-%d", i))
+			}
+			recorder = record.NewFakeRecorder(10)
+			r = &ScopeInstanceReconciler{
+				Client:                 newFakeClient(si, st, newTestClusterRole("test")),
+				Scheme:                 scheme.Scheme,
+				MaxDeletesPerReconcile: 3,
+				Recorder:               recorder,
+			}
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		countRoleBindings := func() int {
+			rbList := &rbacv1.RoleBindingList{}
+			Expect(r.Client.List(ctx, rbList, client.MatchingLabels{scopeInstanceUIDKey: string(si.GetUID())})).To(Succeed())
+			return len(rbList.Items)
+		}
+
+		It("should refuse to delete the bindings until the deletion is confirmed", func() {
+			Expect(countRoleBindings()).To(Equal(10))
+
+			By("removing all namespaces from the ScopeInstance")
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.Namespaces = nil
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(countRoleBindings()).To(Equal(10))
+			Expect(recorder.Events).To(Receive(ContainSubstring(operatorsv1.ReasonDeletionGuard)))
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			cond := meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonDeletionGuard))
+
+			By("confirming the deletion with an annotation")
+			si.SetAnnotations(map[string]string{allowBulkDeleteKey: "true"})
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(countRoleBindings()).To(BeZero())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.GetAnnotations()).NotTo(HaveKey(allowBulkDeleteKey))
+			cond = meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonScopingSuccessful))
+		})
+	})
+
+	When("a ScopeTemplate computes subjects with an expression", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+			st *operatorsv1.ScopeTemplate
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-expression")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-expression",
+					UID:  "scopeinstance-expression-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1", "ns-2"},
+				},
+			}
+		})
+
+		It("should bind the subjects computed for each namespace", func() {
+			st.Spec.ClusterRoles[0].SubjectExpression = `[{"kind": "ServiceAccount", "name": namespaceName, "namespace": namespaceName}, ` +
+				`{"kind": "User", "name": scopeInstance.metadata.name}]`
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test")),
+				Scheme: scheme.Scheme,
+			}
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, ns := range si.Spec.Namespaces {
+				rbs := listFakeRoleBindings(r.Client, ns, si)
+				Expect(rbs).To(HaveLen(1))
+				Expect(rbs[0].Subjects).To(Equal([]rbacv1.Subject{
+					st.Spec.ClusterRoles[0].Subjects[0],
+					{Kind: rbacv1.ServiceAccountKind, Name: ns, Namespace: ns},
+					{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: si.GetName()},
+				}))
+			}
+		})
+
+		It("should report an invalid expression in the status", func() {
+			st.Spec.ClusterRoles[0].SubjectExpression = `[{"kind": "ServiceAccount", "name": unknown}]`
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test")),
+				Scheme: scheme.Scheme,
+			}
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si)).To(BeEmpty())
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			cond := meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonInvalidSubjectExpression))
+			Expect(cond.Message).To(ContainSubstring("undeclared reference to 'unknown'"))
+		})
+
+		It("should reject a result that is not a list of subjects", func() {
+			_, err := evaluateSubjectExpression(ctx, `"not a list"`, si, "ns-1")
+			Expect(errors.Is(err, errInvalidSubjectExpression)).To(BeTrue())
+
+			_, err = evaluateSubjectExpression(ctx, `[{"kind": "User"}]`, si, "ns-1")
+			Expect(errors.Is(err, errInvalidSubjectExpression)).To(BeTrue())
+		})
+	})
+
+	When("a ScopeTemplate ClusterRole is always bound cluster wide", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+			st *operatorsv1.ScopeTemplate
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-cluster-bound")
+			st.Spec.ClusterRoles = append(st.Spec.ClusterRoles, operatorsv1.ClusterRoleTemplate{
+				GenerateName: "cluster-view",
+				Rules: []rbacv1.PolicyRule{{
+					APIGroups: []string{""},
+					Resources: []string{"namespaces"},
+					Verbs:     []string{"get", "list", "watch"},
+				}},
+				Subjects: st.Spec.ClusterRoles[0].Subjects,
+				Scope:    operatorsv1.ClusterRoleScopeCluster,
+			})
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-cluster-bound",
+					UID:  "scopeinstance-cluster-bound-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1", "ns-2"},
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test"), newTestClusterRole("cluster-view")),
+				Scheme: scheme.Scheme,
+			}
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should create both a ClusterRoleBinding and RoleBindings", func() {
+			crbs := listFakeClusterRoleBindings(r.Client, si)
+			Expect(crbs).To(HaveLen(1))
+			Expect(crbs[0].RoleRef.Name).To(Equal("cluster-view"))
+
+			for _, ns := range si.Spec.Namespaces {
+				rbs := listFakeRoleBindings(r.Client, ns, si)
+				Expect(rbs).To(HaveLen(1))
+				Expect(rbs[0].RoleRef.Name).To(Equal("test"))
+			}
+
+			expectConverged(r, si.GetName())
+		})
+
+		It("should replace the ClusterRoleBinding once the ClusterRole is no longer cluster wide", func() {
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(st), st)).To(Succeed())
+			st.Spec.ClusterRoles[1].Scope = ""
+			Expect(r.Client.Update(ctx, st)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(listFakeClusterRoleBindings(r.Client, si)).To(BeEmpty())
+			for _, ns := range si.Spec.Namespaces {
+				Expect(listFakeRoleBindings(r.Client, ns, si)).To(HaveLen(2))
+			}
+		})
+	})
+
+	When("a ScopeTemplate binds a ServiceAccount whose namespace does not exist yet", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-late-namespace")
+			st.Spec.ClusterRoles[0].Subjects = append(st.Spec.ClusterRoles[0].Subjects, rbacv1.Subject{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      "deployer",
+				Namespace: "apps",
+			})
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-late-namespace",
+					UID:  "scopeinstance-late-namespace-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test")),
+				Scheme: scheme.Scheme,
+			}
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listFakeClusterRoleBindings(r.Client, si)).To(HaveLen(1))
+		})
+
+		It("should requeue the ScopeInstance once the namespace is created", func() {
+			apps := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "apps"}}
+			Expect(r.Client.Create(ctx, apps)).To(Succeed())
+
+			Expect(r.mapNamespaceToScopeInstance(apps)).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: si.GetName()},
+			}))
+			Expect(r.mapNamespaceToScopeInstance(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}})).To(BeEmpty())
+		})
+
+		It("should requeue the ScopeInstance once the ServiceAccount is created", func() {
+			sa := newTestServiceAccount("apps", "deployer", nil)
+			Expect(r.mapServiceAccountToScopeInstance(sa)).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: si.GetName()},
+			}))
+			Expect(r.mapServiceAccountToScopeInstance(newTestServiceAccount("apps", "other", nil))).To(BeEmpty())
+		})
+	})
+
+	When("a shadow prefix is configured", func() {
+		var (
+			c      client.Client
+			real   *ScopeInstanceReconciler
+			shadow *ScopeInstanceReconciler
+			si     *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-shadow")
+			st.Spec.ClusterRoles[0].Subjects = append(st.Spec.ClusterRoles[0].Subjects, rbacv1.Subject{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      "deployer",
+				Namespace: "apps",
+			})
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-shadow",
+					UID:  "scopeinstance-shadow-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1"},
+				},
+			}
+			c = newFakeClient(si, st, newTestClusterRole("test"))
+			real = &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme}
+			shadow = &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme, ShadowPrefix: "shadow-"}
+		})
+
+		It("should create prefixed bindings alongside the real ones", func() {
+			for _, r := range []*ScopeInstanceReconciler{shadow, real, shadow} {
+				_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			rbList := &rbacv1.RoleBindingList{}
+			Expect(c.List(ctx, rbList, client.InNamespace("ns-1"))).To(Succeed())
+			Expect(rbList.Items).To(HaveLen(2))
+			shadowed := &operatorsv1.ScopeInstance{ObjectMeta: metav1.ObjectMeta{UID: "shadow-" + si.GetUID()}}
+			shadowRBs := listFakeRoleBindings(c, "ns-1", shadowed)
+			Expect(shadowRBs).To(HaveLen(1))
+			shadowRB := shadowRBs[0]
+			Expect(shadowRB.GetName()).To(HavePrefix("shadow-test-"))
+			Expect(shadowRB.Subjects).To(ConsistOf(
+				rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "shadow-manager"},
+				rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "deployer", Namespace: "shadow-apps"},
+			))
+
+			// The real bindings are not touched by the shadow reconciles
+			realRBs := listFakeRoleBindings(c, "ns-1", si)
+			Expect(realRBs).To(HaveLen(1))
+			Expect(realRBs[0].GetName()).To(HavePrefix("test-"))
+			Expect(realRBs[0].Subjects).To(ConsistOf(
+				rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "manager"},
+				rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "deployer", Namespace: "apps"},
+			))
+		})
+
+		It("should only accept a prefix fitting in a label value with a UID", func() {
+			Expect(ValidateShadowPrefix("")).To(Succeed())
+			Expect(ValidateShadowPrefix("shadow-")).To(Succeed())
+			Expect(ValidateShadowPrefix(strings.Repeat("s", 28))).To(MatchError(ContainSubstring("must be no more than 63 characters")))
+			Expect(ValidateShadowPrefix("Shadow_")).To(HaveOccurred())
+		})
+	})
+
+	When("more than one binding exists for a ClusterRole", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-duplicates")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-duplicates",
+					UID:  "scopeinstance-duplicates-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1"},
+				},
+			}
+			duplicate := func(name string) *rbacv1.RoleBinding {
+				return &rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: "ns-1",
+						Labels: map[string]string{
+							scopeInstanceUIDKey:           string(si.GetUID()),
+							clusterRoleBindingGenerateKey: "test",
+						},
+					},
+					RoleRef: rbacv1.RoleRef{Kind: "ClusterRole", Name: "test", APIGroup: rbacv1.GroupName},
+				}
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test"),
+					duplicate("test-aaaaa"), duplicate("test-bbbbb"), duplicate("test-ccccc")),
+				Scheme: scheme.Scheme,
+			}
+		})
+
+		It("should delete the extra bindings and clear the condition once remediated", func() {
+			res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Requeue).To(BeTrue())
+
+			rbs := listFakeRoleBindings(r.Client, "ns-1", si)
+			Expect(rbs).To(HaveLen(1))
+			Expect(rbs[0].GetName()).To(Equal("test-aaaaa"))
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			cond := meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonDuplicateBindings))
+
+			By("updating the remaining binding on the next reconcile")
+			res, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Requeue).To(BeFalse())
+
+			rbs = listFakeRoleBindings(r.Client, "ns-1", si)
+			Expect(rbs).To(HaveLen(1))
+			Expect(rbs[0].Subjects).NotTo(BeEmpty())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			cond = meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonScopingSuccessful))
+		})
+
+		It("should keep the binding matching the desired one", func() {
+			st := &operatorsv1.ScopeTemplate{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Name: si.Spec.ScopeTemplateName}, st)).To(Succeed())
+			current := &rbacv1.RoleBinding{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Namespace: "ns-1", Name: "test-ccccc"}, current)).To(Succeed())
+			current.SetAnnotations(map[string]string{referenceHashKey: hashScopeInstanceAndTemplate(si, st)})
+			Expect(r.Client.Update(ctx, current)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			rbs := listFakeRoleBindings(r.Client, "ns-1", si)
+			Expect(rbs).To(HaveLen(1))
+			Expect(rbs[0].GetName()).To(Equal("test-ccccc"))
+		})
+
+		It("should not delete more extra bindings than allowed without a confirmation", func() {
+			r.MaxDeletesPerReconcile = 1
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si)).To(HaveLen(3))
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			cond := meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonDeletionGuard))
+
+			By("confirming the deletion")
+			si.SetAnnotations(map[string]string{allowBulkDeleteKey: "true"})
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			rbs := listFakeRoleBindings(r.Client, "ns-1", si)
+			Expect(rbs).To(HaveLen(1))
+			Expect(rbs[0].GetName()).To(Equal("test-aaaaa"))
+		})
+	})
+
+	When("a ScopeInstance is reconciled again", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+			st *operatorsv1.ScopeTemplate
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-idempotent")
+			st.Spec.ClusterRoles[0].ServiceAccountSelector = &operatorsv1.ServiceAccountSelector{
+				Selector: metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			}
+			st.Spec.ClusterRoles[0].SubjectExpression = `[{"kind": "User", "name": namespaceName}]`
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-idempotent",
+					UID:  "scopeinstance-idempotent-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+				},
+			}
+		})
+
+		It("should not write anything for a cluster scoped ScopeInstance", func() {
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test"),
+					newTestServiceAccount("ns-1", "sa-1", map[string]string{"team": "a"})),
+				Scheme: scheme.Scheme,
+			}
+			expectIdempotentReconcile(r, si.GetName())
+		})
+
+		It("should not write anything for a namespace scoped ScopeInstance", func() {
+			si.Spec.Namespaces = []string{"ns-1", "ns-2"}
+			si.Spec.GroupPrefix = "prefix:"
+			r = &ScopeInstanceReconciler{
+				Client:            newFakeClient(si, st, newTestClusterRole("test")),
+				Scheme:            scheme.Scheme,
+				AtomicBindingSwap: true,
+			}
+			expectIdempotentReconcile(r, si.GetName())
+		})
+
+		It("should not write anything when others add labels or reorder subjects", func() {
+			si.Spec.Namespaces = []string{"ns-1"}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test"),
+					newTestServiceAccount("ns-1", "sa-1", map[string]string{"team": "a"})),
+				Scheme: scheme.Scheme,
+			}
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			rbs := listFakeRoleBindings(r.Client, "ns-1", si)
+			Expect(rbs).To(HaveLen(1))
+			rb := &rbs[0]
+			Expect(rb.Subjects).To(HaveLen(3))
+			rb.Labels["example.com/audited"] = "true"
+			rb.Subjects[0], rb.Subjects[2] = rb.Subjects[2], rb.Subjects[0]
+			Expect(r.Client.Update(ctx, rb)).To(Succeed())
+
+			expectConverged(r, si.GetName())
+		})
+	})
+
+	When("a ScopeTemplate changes rapidly", func() {
+		var (
+			r     *ScopeInstanceReconciler
+			st    *operatorsv1.ScopeTemplate
+			queue workqueue.RateLimitingInterface
+			h     handler.EventHandler
+			pred  predicate.GenerationChangedPredicate
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-storm")
+			st.Generation = 1
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(st, &operatorsv1.ScopeInstance{
+					ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-storm"},
+					Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName()},
+				}),
+				Scheme: scheme.Scheme,
+			}
+			queue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			h = handler.EnqueueRequestsFromMapFunc(r.mapToScopeInstance)
+		})
+		AfterEach(func() {
+			queue.ShutDown()
+		})
+
+		It("should not enqueue status only updates", func() {
+			updated := st.DeepCopy()
+			updated.Status.BindingCount = 1
+			Expect(pred.Update(event.UpdateEvent{ObjectOld: st, ObjectNew: updated})).To(BeFalse())
+		})
+
+		It("should coalesce the requests into a single reconcile", func() {
+			for i := 0; i < 50; i++ {
+				h.Delete(event.DeleteEvent{Object: st}, queue)
+				h.Create(event.CreateEvent{Object: st}, queue)
+
+				updated := st.DeepCopy()
+				updated.Generation = st.Generation + 1
+				e := event.UpdateEvent{ObjectOld: st, ObjectNew: updated}
+				if pred.Update(e) {
+					h.Update(e, queue)
+				}
+			}
+
+			Expect(queue.Len()).To(Equal(1))
+		})
+	})
+
+	When("a field manager is configured", func() {
+		It("should use the field manager for every write", func() {
+			st := newTestScopeTemplate("scopetemplate-field-manager")
+			si := &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-field-manager",
+					UID:  "scopeinstance-field-manager-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1"},
+				},
+			}
+			c := &fieldManagerClient{Client: newFakeClient(si, st, newTestClusterRole("test"))}
+			r := &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme, FieldManager: "gitops"}
+
+			By("creating the bindings")
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("updating the bindings")
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.GroupPrefix = "prefix"
+			Expect(c.Client.Update(ctx, si)).To(Succeed())
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(c.fieldManagers).NotTo(BeEmpty())
+			Expect(c.fieldManagers).To(HaveEach("gitops"))
+		})
+
+		It("should default to the operator name", func() {
+			c := &fieldManagerClient{Client: newFakeClient()}
+			r := &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme}
+			Expect(r.Client.Create(ctx, &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "rb", Namespace: "ns-1"}}, r.fieldOwner())).To(Succeed())
+			Expect(c.fieldManagers).To(Equal([]string{DefaultFieldManager}))
+		})
+	})
+
+	When("deleting bindings without an owner scoping selector", func() {
+		var (
+			r         *ScopeInstanceReconciler
+			unrelated *rbacv1.RoleBinding
+		)
+		BeforeEach(func() {
+			unrelated = &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "ns-1"},
+				RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin", APIGroup: rbacv1.GroupName},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(unrelated),
+				Scheme: scheme.Scheme,
+			}
+		})
+
+		It("should reject listing bindings to delete without a ScopeInstance UID selector", func() {
+			all := func(client.Object) bool { return true }
+
+			_, err := r.listBindingsToDelete(ctx, all)
+			Expect(err).To(MatchError(errUnscopedDelete))
+
+			_, err = r.listBindingsToDelete(ctx, all, client.MatchingLabels{clusterRoleBindingGenerateKey: "test"})
+			Expect(err).To(MatchError(errUnscopedDelete))
+
+			_, err = r.listBindingsToDelete(ctx, all, client.MatchingLabels{scopeInstanceUIDKey: ""})
+			Expect(err).To(MatchError(errUnscopedDelete))
+
+			bindings, err := r.listBindingsToDelete(ctx, all, client.MatchingLabels{scopeInstanceUIDKey: "some-uid"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bindings).To(BeEmpty())
+		})
+
+		It("should reject deleting bindings that are not owned by a ScopeInstance", func() {
+			Expect(r.deleteBindings(ctx, []client.Object{unrelated})).To(MatchError(errUnscopedDelete))
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(unrelated), &rbacv1.RoleBinding{})).To(Succeed())
+		})
+	})
+
+	When("a ScopeInstance expires", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-expiry")
+			expiresAt := metav1.NewTime(time.Now().Add(time.Minute))
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-expiry",
+					UID:  "scopeinstance-expiry-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					ExpiresAt:         &expiresAt,
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client:        newFakeClient(si, st, newTestClusterRole("test")),
+				Scheme:        scheme.Scheme,
+				RenewalWindow: time.Hour,
+			}
+
+			res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(BeNumerically("~", time.Minute, 5*time.Second))
+			Expect(listFakeClusterRoleBindings(r.Client, si)).To(HaveLen(1))
+		})
+
+		// setExpiresAt replaces ExpiresAt and the annotations of the ScopeInstance.
+		setExpiresAt := func(expiresAt time.Time, annotations map[string]string) {
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.ExpiresAt = &metav1.Time{Time: expiresAt}
+			si.SetAnnotations(annotations)
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+		}
+
+		It("should delete the bindings once expired", func() {
+			setExpiresAt(time.Now().Add(-time.Second), nil)
+
+			res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).To(Equal(ctrl.Result{}))
+			Expect(listFakeClusterRoleBindings(r.Client, si)).To(BeEmpty())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped).Reason).To(Equal(operatorsv1.ReasonExpired))
+		})
+
+		It("should extend ExpiresAt and the requeue when renewed", func() {
+			setExpiresAt(time.Now().Add(time.Minute), map[string]string{renewKey: time.Now().UTC().Format(time.RFC3339)})
+
+			res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(BeNumerically("~", time.Hour, 5*time.Second))
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.GetAnnotations()).NotTo(HaveKey(renewKey))
+			Expect(si.Spec.ExpiresAt.Time).To(BeTemporally("~", time.Now().Add(time.Hour), 5*time.Second))
+		})
+
+		It("should keep the bindings when renewed before expiring", func() {
+			setExpiresAt(time.Now().Add(-time.Second), map[string]string{renewKey: time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)})
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listFakeClusterRoleBindings(r.Client, si)).To(HaveLen(1))
+		})
+
+		It("should not bring ExpiresAt forward", func() {
+			later := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+			setExpiresAt(later, map[string]string{renewKey: time.Now().UTC().Format(time.RFC3339)})
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.Spec.ExpiresAt.Time).To(BeTemporally("==", later))
+		})
+	})
+
+	When("one of several ClusterRoles is removed from a ScopeTemplate", func() {
+		var (
+			r          *ScopeInstanceReconciler
+			namespaced *operatorsv1.ScopeInstance
+			cluster    *operatorsv1.ScopeInstance
+			st         *operatorsv1.ScopeTemplate
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-remove-clusterrole")
+			st.Spec.ClusterRoles = append(st.Spec.ClusterRoles, operatorsv1.ClusterRoleTemplate{
+				GenerateName: "other",
+				Rules:        st.Spec.ClusterRoles[0].Rules,
+				Subjects:     st.Spec.ClusterRoles[0].Subjects,
+			})
+			namespaced = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-remove-clusterrole-ns",
+					UID:  "scopeinstance-remove-clusterrole-ns-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1", "ns-2"},
+				},
+			}
+			cluster = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-remove-clusterrole-cluster",
+					UID:  "scopeinstance-remove-clusterrole-cluster-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(namespaced, cluster, st, newTestClusterRole("test"), newTestClusterRole("other")),
+				Scheme: scheme.Scheme,
+			}
+
+			for _, si := range []*operatorsv1.ScopeInstance{namespaced, cluster} {
+				_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(listFakeClusterRoleBindings(r.Client, cluster)).To(HaveLen(2))
+			for _, ns := range namespaced.Spec.Namespaces {
+				Expect(listFakeRoleBindings(r.Client, ns, namespaced)).To(HaveLen(2))
+			}
+
+			By("removing the second ClusterRole")
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(st), st)).To(Succeed())
+			st.Spec.ClusterRoles = st.Spec.ClusterRoles[:1]
+			Expect(r.Client.Update(ctx, st)).To(Succeed())
+
+			for _, si := range []*operatorsv1.ScopeInstance{namespaced, cluster} {
+				_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		It("should only delete the RoleBindings of the removed ClusterRole", func() {
+			for _, ns := range namespaced.Spec.Namespaces {
+				rbs := listFakeRoleBindings(r.Client, ns, namespaced)
+				Expect(rbs).To(HaveLen(1))
+				Expect(rbs[0].RoleRef.Name).To(Equal("test"))
+				Expect(rbs[0].Labels).To(HaveKeyWithValue(clusterRoleBindingGenerateKey, "test"))
+			}
+		})
+
+		It("should only delete the ClusterRoleBinding of the removed ClusterRole", func() {
+			crbs := listFakeClusterRoleBindings(r.Client, cluster)
+			Expect(crbs).To(HaveLen(1))
+			Expect(crbs[0].RoleRef.Name).To(Equal("test"))
+			Expect(crbs[0].Labels).To(HaveKeyWithValue(clusterRoleBindingGenerateKey, "test"))
+		})
+	})
+
+	When("a ScopeInstance binds in the namespaces of its subjects", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+			st *operatorsv1.ScopeTemplate
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-subject-namespaces")
+			st.Spec.ClusterRoles[0].Subjects = append(st.Spec.ClusterRoles[0].Subjects,
+				rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "deployer", Namespace: "apps"},
+				rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "monitor", Namespace: "monitoring"},
+			)
+			st.Spec.ClusterRoles = append(st.Spec.ClusterRoles, operatorsv1.ClusterRoleTemplate{
+				GenerateName: "other",
+				Rules:        st.Spec.ClusterRoles[0].Rules,
+				Subjects: []rbacv1.Subject{
+					{Kind: rbacv1.ServiceAccountKind, Name: "builder", Namespace: "tools"},
+				},
+			})
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-subject-namespaces",
+					UID:  "scopeinstance-subject-namespaces-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName:       st.GetName(),
+					BindInSubjectNamespaces: true,
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test"), newTestClusterRole("other")),
+				Scheme: scheme.Scheme,
+			}
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		// roleRefsIn returns the ClusterRoles bound in the namespace.
+		roleRefsIn := func(ns string) []string {
+			var names []string
+			for _, rb := range listFakeRoleBindings(r.Client, ns, si) {
+				names = append(names, rb.RoleRef.Name)
+			}
+			return names
+		}
+
+		It("should only create RoleBindings in the namespaces of each ClusterRole's ServiceAccounts", func() {
+			Expect(listFakeClusterRoleBindings(r.Client, si)).To(BeEmpty())
+			Expect(roleRefsIn("apps")).To(ConsistOf("test"))
+			Expect(roleRefsIn("monitoring")).To(ConsistOf("test"))
+			Expect(roleRefsIn("tools")).To(ConsistOf("other"))
+		})
+
+		It("should also create RoleBindings in the selected namespaces", func() {
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.Namespaces = []string{"ns-1"}
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roleRefsIn("ns-1")).To(ConsistOf("test", "other"))
+			Expect(roleRefsIn("apps")).To(ConsistOf("test"))
+			Expect(roleRefsIn("tools")).To(ConsistOf("other"))
+		})
+
+		It("should delete the RoleBinding once the ServiceAccount is no longer a subject", func() {
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(st), st)).To(Succeed())
+			st.Spec.ClusterRoles[0].Subjects = st.Spec.ClusterRoles[0].Subjects[:2]
+			Expect(r.Client.Update(ctx, st)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(roleRefsIn("apps")).To(ConsistOf("test"))
+			Expect(roleRefsIn("monitoring")).To(BeEmpty())
+			Expect(roleRefsIn("tools")).To(ConsistOf("other"))
+		})
+	})
+
+	When("a selected namespace is stuck terminating", func() {
+		var (
+			r     *ScopeInstanceReconciler
+			si    *operatorsv1.ScopeInstance
+			stuck *corev1.Namespace
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-terminating")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-terminating",
+					UID:  "scopeinstance-terminating-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1", "ns-stuck"},
+				},
+			}
+			stuck = &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "ns-stuck",
+					Finalizers: []string{"example.com/never-done"},
+				},
+				Status: corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating},
+			}
+			r = &ScopeInstanceReconciler{
+				// Creating bindings in a terminating namespace is forbidden
+				Client: &failingCreateClient{
+					Client:    newFakeClient(si, st, newTestClusterRole("test"), stuck),
+					namespace: stuck.GetName(),
+				},
+				Scheme: scheme.Scheme,
+			}
+		})
+
+		It("should skip the namespace and record it in the status", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si)).To(HaveLen(1))
+			Expect(listFakeRoleBindings(r.Client, stuck.GetName(), si)).To(BeEmpty())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.Status.TerminatingNamespaces).To(Equal([]string{stuck.GetName()}))
+			cond := meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonScopingSuccessful))
+		})
+
+		It("should clear the status once the namespace is no longer selected", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.Namespaces = []string{"ns-1"}
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.Status.TerminatingNamespaces).To(BeEmpty())
+		})
+	})
+
+	When("a ScopeTemplate grants a ClusterRole to different subjects per namespace", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-namespace-subjects")
+			st.Spec.ClusterRoles[0].NamespaceSubjects = map[string][]rbacv1.Subject{
+				"ns-1":          {{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice"}},
+				"ns-2":          {{Kind: rbacv1.ServiceAccountKind, Namespace: "ci", Name: "ns-2"}},
+				"ns-unselected": {{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "mallory"}},
+			}
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-namespace-subjects",
+					UID:  "scopeinstance-namespace-subjects-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1", "ns-2"},
+				},
+			}
+			r = &ScopeInstanceReconciler{Client: newFakeClient(si, st, newTestClusterRole("test")), Scheme: scheme.Scheme}
+		})
+
+		It("should bind each namespace to its designated subjects", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			manager := rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "manager"}
+			rbs := listFakeRoleBindings(r.Client, "ns-1", si)
+			Expect(rbs).To(HaveLen(1))
+			Expect(rbs[0].Subjects).To(ConsistOf(manager, rbacv1.Subject{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice"}))
+
+			rbs = listFakeRoleBindings(r.Client, "ns-2", si)
+			Expect(rbs).To(HaveLen(1))
+			Expect(rbs[0].Subjects).To(ConsistOf(manager, rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: "ci", Name: "ns-2"}))
+
+			Expect(listFakeRoleBindings(r.Client, "ns-unselected", si)).To(BeEmpty())
+			expectIdempotentReconcile(r, si.GetName())
+		})
+	})
+
+	When("an existing RoleBinding binds a different ClusterRole", func() {
+		var (
+			r     *ScopeInstanceReconciler
+			c     *bindingSwapClient
+			si    *operatorsv1.ScopeInstance
+			stale *rbacv1.RoleBinding
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-roleref-swap")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-roleref-swap",
+					UID:  "scopeinstance-roleref-swap-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1"},
+				},
+			}
+			r = &ScopeInstanceReconciler{Scheme: scheme.Scheme}
+			cr := st.Spec.ClusterRoles[0].BindingTemplate()
+			stale = r.roleBindingManifest(&cr, si, st, "ns-1")
+			stale.SetName("test-stale")
+			stale.RoleRef.Name = "previous"
+			c = &bindingSwapClient{Client: newFakeClient(si, st, newTestClusterRole("test"), stale)}
+			r.Client = c
+		})
+
+		It("should create the new RoleBinding before deleting the old one", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(c.ops).To(Equal([]string{"create", "delete"}))
+			// Both bindings existed when the old one was deleted
+			Expect(c.deletedWith).To(ConsistOf("test-stale", HavePrefix("test-")))
+
+			rbs := listFakeRoleBindings(r.Client, "ns-1", si)
+			Expect(rbs).To(HaveLen(1))
+			Expect(rbs[0].GetName()).NotTo(Equal("test-stale"))
+			Expect(rbs[0].RoleRef.Name).To(Equal("test"))
+			expectIdempotentReconcile(r, si.GetName())
+		})
+	})
+
+	When("a ScopeInstance was admitted without the current defaults", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-defaults")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-defaults",
+					UID:  "scopeinstance-defaults-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+				},
+			}
+			r = &ScopeInstanceReconciler{Client: newFakeClient(si, st, newTestClusterRole("test")), Scheme: scheme.Scheme}
+		})
+
+		It("should default and persist it on reconcile", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.GetAnnotations()).To(HaveKeyWithValue(operatorsv1.ScopeAnnotation, operatorsv1.ScopeCluster))
+			expectIdempotentReconcile(r, si.GetName())
+
+			By("removing the cluster scope once namespaces are selected")
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.Namespaces = []string{"ns-1"}
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.GetAnnotations()).NotTo(HaveKey(operatorsv1.ScopeAnnotation))
+		})
+	})
+
+	When("a ScopeTemplate binds the operator's own ServiceAccount", func() {
+		var (
+			r        *ScopeInstanceReconciler
+			si       *operatorsv1.ScopeInstance
+			recorder *record.FakeRecorder
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-operator-subject")
+			st.Spec.ClusterRoles[0].Subjects = append(st.Spec.ClusterRoles[0].Subjects, rbacv1.Subject{
+				Kind:      rbacv1.ServiceAccountKind,
+				Namespace: "oria-operator-system",
+				Name:      "oria-operator-controller-manager",
+			})
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-operator-subject",
+					UID:  "scopeinstance-operator-subject-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1"},
+				},
+			}
+			recorder = record.NewFakeRecorder(10)
+			r = &ScopeInstanceReconciler{
+				Client:   newFakeClient(si, st, newTestClusterRole("test")),
+				Scheme:   scheme.Scheme,
+				Recorder: recorder,
+				OperatorServiceAccount: types.NamespacedName{
+					Namespace: "oria-operator-system",
+					Name:      "oria-operator-controller-manager",
+				},
+			}
+		})
+
+		It("should refuse to bind it by default", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si)).To(BeEmpty())
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			cond := meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonOperatorSubject))
+			Expect(cond.Message).To(ContainSubstring("ServiceAccount oria-operator-controller-manager"))
+		})
+
+		It("should bind it with a warning when configured to", func() {
+			r.OperatorSubjectPolicy = OperatorSubjectWarn
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si)).To(HaveLen(1))
+			Expect(recorder.Events).To(Receive(HavePrefix(corev1.EventTypeWarning + " " + operatorsv1.ReasonOperatorSubject)))
+		})
+
+		It("should flag the username and groups of the ServiceAccount", func() {
+			for _, subject := range []rbacv1.Subject{
+				{Kind: rbacv1.UserKind, Name: "system:serviceaccount:oria-operator-system:oria-operator-controller-manager"},
+				{Kind: rbacv1.GroupKind, Name: "system:serviceaccounts"},
+				{Kind: rbacv1.GroupKind, Name: "system:serviceaccounts:oria-operator-system"},
+			} {
+				Expect(r.checkOperatorSubjects(si, []rbacv1.Subject{subject})).To(MatchError(errOperatorSubject), subject.Name)
+			}
+			Expect(r.checkOperatorSubjects(si, []rbacv1.Subject{
+				{Kind: rbacv1.ServiceAccountKind, Namespace: "other", Name: "oria-operator-controller-manager"},
+				{Kind: rbacv1.GroupKind, Name: "system:serviceaccounts:other"},
+			})).To(Succeed())
+		})
+	})
+
+	When("a default ScopeTemplate is configured", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			defaultST := newTestScopeTemplate("scopetemplate-default")
+			otherST := newTestScopeTemplate("scopetemplate-not-default")
+			otherST.Spec.ClusterRoles[0].GenerateName = "other"
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-default-template",
+					UID:  "scopeinstance-default-template-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					Namespaces: []string{"ns-1"},
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client:               newFakeClient(si, defaultST, otherST, newTestClusterRole("test"), newTestClusterRole("other")),
+				Scheme:               scheme.Scheme,
+				DefaultScopeTemplate: defaultST.GetName(),
+			}
+		})
+
+		It("should use it for a ScopeInstance that references no ScopeTemplate", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			rbs := listFakeRoleBindings(r.Client, "ns-1", si)
+			Expect(rbs).To(HaveLen(1))
+			Expect(rbs[0].RoleRef.Name).To(Equal("test"))
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.GetLabels()).To(HaveKeyWithValue(scopeTemplateNameKey, "scopetemplate-default"))
+
+			st := &ScopeTemplateReconciler{Client: r.Client, DefaultScopeTemplate: r.DefaultScopeTemplate}
+			Expect(st.mapToScopeTemplate(si)).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "scopetemplate-default"}},
+			))
+		})
+
+		It("should use the ScopeTemplate referenced by the ScopeInstance instead", func() {
+			si.Spec.ScopeTemplateName = "scopetemplate-not-default"
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			rbs := listFakeRoleBindings(r.Client, "ns-1", si)
+			Expect(rbs).To(HaveLen(1))
+			Expect(rbs[0].RoleRef.Name).To(Equal("other"))
+		})
+	})
+
+	When("the RoleBindings of a ScopeInstance are written concurrently", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			c  *concurrentCreateClient
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-concurrent")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-concurrent",
+					UID:  "scopeinstance-concurrent-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+				},
+			}
+			for i := 0; i < 20; i++ {
+				si.Spec.Namespaces = append(si.Spec.Namespaces, fmt.Sprintf("ns-%d", i))
+			}
+			c = &concurrentCreateClient{
+				Client:   newFakeClient(si, st, newTestClusterRole("test")),
+				released: make(chan struct{}),
+			}
+			r = &ScopeInstanceReconciler{
+				Client:                     c,
+				Scheme:                     scheme.Scheme,
+				MaxConcurrentBindingWrites: 4,
+			}
+		})
+
+		It("should create every RoleBinding with at most MaxConcurrentBindingWrites in flight", func() {
+			c.arrivals = 4
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.maxInFlight).To(Equal(4))
+			for _, ns := range si.Spec.Namespaces {
+				Expect(listFakeRoleBindings(r.Client, ns, si)).To(HaveLen(1))
+			}
+
+			expectIdempotentReconcile(r, si.GetName())
+		})
+
+		It("should report the failure of every RoleBinding written at once", func() {
+			si.Spec.Namespaces = []string{"ns-1", "ns-2", "ns-3"}
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+			r.MaxConcurrentBindingWrites = 3
+			c.arrivals = 3
+			c.failing = sets.NewString("ns-2", "ns-3")
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("creating test- in ns-2: injected failure"))
+			Expect(err.Error()).To(ContainSubstring("creating test- in ns-3: injected failure"))
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si)).To(HaveLen(1))
+		})
+	})
+
+	When("the operator is not allowed to write RoleBindings in a selected namespace", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			c  *failingCreateClient
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-forbidden")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-forbidden",
+					UID:  "scopeinstance-forbidden-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1", "ns-forbidden"},
+				},
+			}
+			c = &failingCreateClient{
+				Client:    newFakeClient(si, st, newTestClusterRole("test")),
+				namespace: "ns-forbidden",
+				err: k8sapierrors.NewForbidden(rbacv1.Resource("rolebindings"), "",
+					errors.New("the operator may not create rolebindings in ns-forbidden")),
+			}
+			r = &ScopeInstanceReconciler{
+				Client:                  c,
+				Scheme:                  scheme.Scheme,
+				SkipForbiddenNamespaces: true,
+			}
+		})
+
+		It("should skip the namespace and record it in the status", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si)).To(HaveLen(1))
+			Expect(listFakeRoleBindings(r.Client, "ns-forbidden", si)).To(BeEmpty())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.Status.ForbiddenNamespaces).To(Equal([]string{"ns-forbidden"}))
+			cond := meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonScopingSuccessful))
+		})
+
+		It("should bind the namespace once the operator is allowed to", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			c.namespace = ""
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(listFakeRoleBindings(r.Client, "ns-forbidden", si)).To(HaveLen(1))
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.Status.ForbiddenNamespaces).To(BeEmpty())
+		})
+
+		It("should fail the reconcile unless SkipForbiddenNamespaces is set", func() {
+			r.SkipForbiddenNamespaces = false
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(k8sapierrors.IsForbidden(err)).To(BeTrue())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.Status.ForbiddenNamespaces).To(BeEmpty())
+			cond := meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonScopingFailed))
+		})
+	})
+
+	When("the force-sync annotation of a ScopeInstance changes", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-force-sync")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-force-sync",
+					UID:  "scopeinstance-force-sync-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1", "ns-2"},
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client:   newFakeClient(si, st, newTestClusterRole("test")),
+				Scheme:   scheme.Scheme,
+				bindings: newBindingIndex(nil),
+			}
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			expectConverged(r, si.GetName())
+		})
+
+		setForceSync := func(nonce string) {
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			metav1.SetMetaDataAnnotation(&si.ObjectMeta, forceSyncKey, nonce)
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+		}
+
+		It("should rewrite every binding once per nonce", func() {
+			setForceSync("1")
+
+			c := &writeCountingClient{Client: r.Client}
+			r.Client = c
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			r.Client = c.Client
+
+			var patches []string
+			for _, write := range c.writes {
+				if strings.HasPrefix(write, "patch *unstructured.Unstructured ns-") {
+					patches = append(patches, write)
+				}
+			}
+			Expect(patches).To(HaveLen(2))
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.Status.ObservedForceSync).To(Equal("1"))
+			expectConverged(r, si.GetName())
+		})
+
+		It("should repair a binding changed behind the back of the binding index", func() {
+			rb := listFakeRoleBindings(r.Client, "ns-1", si)[0]
+			rb.Subjects = []rbacv1.Subject{{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "mallory"}}
+			Expect(r.Client.Update(ctx, &rb)).To(Succeed())
+
+			By("missing the drift without a force sync")
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si)[0].Subjects).To(HaveLen(1))
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si)[0].Subjects[0].Name).To(Equal("mallory"))
+
+			By("repairing it with a force sync")
+			setForceSync("2")
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si)[0].Subjects[0].Name).To(Equal("manager"))
+		})
+	})
+
+	When("deletion safe mode is enabled", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-safe-mode")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-safe-mode",
+					UID:  "scopeinstance-safe-mode-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1", "ns-2"},
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client:           newFakeClient(si, st, newTestClusterRole("test")),
+				Scheme:           scheme.Scheme,
+				DeletionSafeMode: true,
+			}
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		setNamespaces := func(namespaces ...string) {
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.Namespaces = namespaces
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+		}
+
+		It("should defer a deletion intended by a single reconcile", func() {
+			setNamespaces("ns-1")
+
+			res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(Equal(deletionConfirmationDelay))
+			Expect(listFakeRoleBindings(r.Client, "ns-2", si)).To(HaveLen(1))
+
+			By("confirming the deletion on the next reconcile")
+			res, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(BeZero())
+			Expect(listFakeRoleBindings(r.Client, "ns-2", si)).To(BeEmpty())
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si)).To(HaveLen(1))
+		})
+
+		It("should drop a deletion intent not repeated by the next reconcile", func() {
+			setNamespaces("ns-1")
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("reading the original spec again")
+			setNamespaces("ns-1", "ns-2")
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listFakeRoleBindings(r.Client, "ns-2", si)).To(HaveLen(1))
+
+			By("requiring a new confirmation for the next deletion")
+			setNamespaces("ns-1")
+			res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(Equal(deletionConfirmationDelay))
+			Expect(listFakeRoleBindings(r.Client, "ns-2", si)).To(HaveLen(1))
+		})
+
+		It("should defer the deletion of a replaced binding", func() {
+			current := listFakeRoleBindings(r.Client, "ns-1", si)[0]
+			Expect(r.Client.Delete(ctx, &current)).To(Succeed())
+			stale := current.DeepCopy()
+			stale.SetResourceVersion("")
+			stale.SetUID("")
+			stale.RoleRef.Name = "previous"
+			Expect(r.Client.Create(ctx, stale)).To(Succeed())
+
+			res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(Equal(deletionConfirmationDelay))
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si)).To(HaveLen(2))
+
+			By("confirming the deletion on the next reconciles")
+			Eventually(func() []string {
+				_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+				Expect(err).NotTo(HaveOccurred())
+				var roles []string
+				for _, rb := range listFakeRoleBindings(r.Client, "ns-1", si) {
+					roles = append(roles, rb.RoleRef.Name)
+				}
+				return roles
+			}).Should(ConsistOf("test"))
+		})
+	})
+
+	When("a ScopeInstance overrides the ClusterRole of a ScopeTemplate entry", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-overrides")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-overrides",
+					UID:  "scopeinstance-overrides-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName:        st.GetName(),
+					Namespaces:               []string{"ns-1"},
+					ClusterRoleNameOverrides: map[string]string{"test": "test-staging"},
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test"),
+					newTestClusterRole("test-staging"), newTestClusterRole("test-production")),
+				Scheme: scheme.Scheme,
+			}
+		})
+
+		It("should bind the overriding ClusterRole", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			rbs := listFakeRoleBindings(r.Client, "ns-1", si)
+			Expect(rbs).To(HaveLen(1))
+			Expect(rbs[0].RoleRef.Name).To(Equal("test-staging"))
+			Expect(rbs[0].Labels).To(HaveKeyWithValue(clusterRoleBindingGenerateKey, "test"))
+
+			expectIdempotentReconcile(r, si.GetName())
+		})
+
+		It("should recreate the RoleBinding when the override changes", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			old := listFakeRoleBindings(r.Client, "ns-1", si)[0]
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.ClusterRoleNameOverrides["test"] = "test-production"
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			rbs := listFakeRoleBindings(r.Client, "ns-1", si)
+			Expect(rbs).To(HaveLen(1))
+			Expect(rbs[0].RoleRef.Name).To(Equal("test-production"))
+			Expect(rbs[0].GetName()).NotTo(Equal(old.GetName()))
+		})
+
+		It("should wait for the overriding ClusterRole to exist", func() {
+			si.Spec.ClusterRoleNameOverrides["test"] = "test-missing"
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+
+			res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(Equal(clusterRoleRequeueDelay))
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si)).To(BeEmpty())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			cond := meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonWaitingForClusterRole))
+			Expect(cond.Message).To(ContainSubstring("test-missing"))
+		})
+	})
+
+	When("RoleBindings are created and deleted in the namespaces of a ScopeInstance", func() {
+		var (
+			r        *ScopeInstanceReconciler
+			si       *operatorsv1.ScopeInstance
+			recorder *objectRecorder
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-namespace-events")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-namespace-events",
+					UID:  "scopeinstance-namespace-events-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1", "ns-2"},
+				},
+			}
+			recorder = &objectRecorder{}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test"),
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-1", UID: "ns-1-uid"}},
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-2", UID: "ns-2-uid"}},
+				),
+				Scheme:   scheme.Scheme,
+				Recorder: recorder,
+			}
+		})
+
+		It("should record an event in each namespace", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			var expected []recordedEvent
+			for _, ns := range si.Spec.Namespaces {
+				rbs := listFakeRoleBindings(r.Client, ns, si)
+				Expect(rbs).To(HaveLen(1))
+				expected = append(expected, recordedEvent{
+					kind:      "Namespace",
+					name:      ns,
+					namespace: ns,
+					uid:       types.UID(ns + "-uid"),
+					reason:    operatorsv1.ReasonRoleBindingCreated,
+					message:   fmt.Sprintf("Created RoleBinding %s of ClusterRole test for ScopeInstance %s", rbs[0].GetName(), si.GetName()),
+				})
+			}
+			Expect(recorder.events).To(ConsistOf(expected))
+
+			By("removing a namespace")
+			deleted := listFakeRoleBindings(r.Client, "ns-2", si)[0]
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.Namespaces = []string{"ns-1"}
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+			recorder.events = nil
+
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.events).To(ConsistOf(recordedEvent{
+				kind:      "Namespace",
+				name:      "ns-2",
+				namespace: "ns-2",
+				uid:       "ns-2-uid",
+				reason:    operatorsv1.ReasonRoleBindingDeleted,
+				message:   fmt.Sprintf("Deleted RoleBinding %s of ClusterRole test for ScopeInstance %s", deleted.GetName(), si.GetName()),
+			}))
+		})
+
+		It("should not record namespace events for a ClusterRoleBinding", func() {
+			si.Spec.Namespaces = nil
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listFakeClusterRoleBindings(r.Client, si)).To(HaveLen(1))
+			Expect(recorder.events).To(BeEmpty())
+		})
+	})
+
+	When("the ClusterRoles of the ScopeTemplate are created one by one", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-partial")
+			second := st.Spec.ClusterRoles[0]
+			second.GenerateName = "second"
+			st.Spec.ClusterRoles = append(st.Spec.ClusterRoles, second)
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-partial", UID: "scopeinstance-partial-uid"},
+				Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName(), Namespaces: []string{"ns-1"}},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st),
+				Scheme: scheme.Scheme,
+			}
+		})
+		// reconcilePartial reconciles the ScopeInstance, returning the
+		// generateNames of its RoleBindings
+		reconcilePartial := func() (ctrl.Result, *operatorsv1.ScopeInstance, []string) {
+			res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+			existing := &operatorsv1.ScopeInstance{}
+			ExpectWithOffset(1, r.Client.Get(ctx, client.ObjectKeyFromObject(si), existing)).To(Succeed())
+			var names []string
+			for _, rb := range listFakeRoleBindings(r.Client, "ns-1", si) {
+				names = append(names, rb.Labels[clusterRoleBindingGenerateKey])
+			}
+			return res, existing, names
+		}
+
+		It("should bind each ClusterRole as it becomes available", func() {
+			res, existing, names := reconcilePartial()
+			Expect(res.RequeueAfter).To(Equal(clusterRoleRequeueDelay))
+			Expect(names).To(BeEmpty())
+			Expect(existing.Status.PendingClusterRoles).To(ConsistOf("test", "second"))
+
+			By("creating the first ClusterRole")
+			Expect(r.Client.Create(ctx, newTestClusterRole("test"))).To(Succeed())
+			res, existing, names = reconcilePartial()
+			Expect(res.RequeueAfter).To(Equal(clusterRoleRequeueDelay))
+			Expect(names).To(ConsistOf("test"))
+			Expect(existing.Status.PendingClusterRoles).To(ConsistOf("second"))
+			cond := meta.FindStatusCondition(existing.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonWaitingForClusterRole))
+			Expect(cond.Message).To(Equal("waiting for ClusterRoles to be created: second"))
+
+			By("creating the second ClusterRole")
+			Expect(r.Client.Create(ctx, newTestClusterRole("second"))).To(Succeed())
+			res, existing, names = reconcilePartial()
+			Expect(res.RequeueAfter).To(BeZero())
+			Expect(names).To(ConsistOf("test", "second"))
+			Expect(existing.Status.PendingClusterRoles).To(BeEmpty())
+			Expect(meta.IsStatusConditionTrue(existing.Status.Conditions, operatorsv1.TypeScoped)).To(BeTrue())
+		})
+
+		It("should keep the bindings of a ClusterRole that is recreated", func() {
+			Expect(r.Client.Create(ctx, newTestClusterRole("test"))).To(Succeed())
+			Expect(r.Client.Create(ctx, newTestClusterRole("second"))).To(Succeed())
+			_, _, names := reconcilePartial()
+			Expect(names).To(ConsistOf("test", "second"))
+
+			Expect(r.Client.Delete(ctx, newTestClusterRole("second"))).To(Succeed())
+			res, existing, names := reconcilePartial()
+			Expect(res.RequeueAfter).To(Equal(clusterRoleRequeueDelay))
+			Expect(existing.Status.PendingClusterRoles).To(ConsistOf("second"))
+			Expect(names).To(ConsistOf("test", "second"))
+		})
+
+		It("should withhold the bindings of a pending ClusterRole in a changed namespace", func() {
+			Expect(r.Client.Create(ctx, newTestClusterRole("test"))).To(Succeed())
+			Expect(r.Client.Create(ctx, newTestClusterRole("second"))).To(Succeed())
+			reconcilePartial()
+
+			Expect(r.Client.Delete(ctx, newTestClusterRole("second"))).To(Succeed())
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.Namespaces = append(si.Spec.Namespaces, "ns-2")
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+			r.dirty = newDirtyNamespaces()
+			r.dirty.markNamespace(si.GetName(), "ns-2")
+			res, existing, _ := reconcilePartial()
+
+			Expect(res.RequeueAfter).To(Equal(clusterRoleRequeueDelay))
+			rbs := listFakeRoleBindings(r.Client, "ns-2", si)
+			Expect(rbs).To(HaveLen(1))
+			Expect(rbs[0].RoleRef.Name).To(Equal("test"))
+			Expect(meta.FindStatusCondition(existing.Status.Conditions, operatorsv1.TypeScoped).Reason).To(Equal(operatorsv1.ReasonWaitingForClusterRole))
+		})
+
+		It("should report bindings with too many subjects alongside the pending ClusterRoles", func() {
+			Expect(r.Client.Create(ctx, newTestClusterRole("test"))).To(Succeed())
+			r.MaxSubjectsPerBinding = 1
+			st := &operatorsv1.ScopeTemplate{}
+			Expect(r.Client.Get(ctx, types.NamespacedName{Name: si.Spec.ScopeTemplateName}, st)).To(Succeed())
+			st.Spec.ClusterRoles[0].Subjects = append(st.Spec.ClusterRoles[0].Subjects, rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "auditors"})
+			Expect(r.Client.Update(ctx, st)).To(Succeed())
+
+			res, existing, names := reconcilePartial()
+			Expect(res.RequeueAfter).To(Equal(clusterRoleRequeueDelay))
+			Expect(names).To(BeEmpty())
+			cond := meta.FindStatusCondition(existing.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonTooManySubjects))
+			Expect(cond.Message).To(And(ContainSubstring("RoleBinding test in namespace ns-1 has 2 subjects"), ContainSubstring("ClusterRoles pending: RoleBinding second")))
+		})
+	})
+
+	When("the ScopeTemplate of a ScopeInstance is unhealthy", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			st *operatorsv1.ScopeTemplate
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-unhealthy")
+			meta.SetStatusCondition(&st.Status.Conditions, metav1.Condition{
+				Type:    operatorsv1.TypeTemplated,
+				Status:  metav1.ConditionFalse,
+				Reason:  operatorsv1.ReasonPolicyViolation,
+				Message: `ClusterRole test grants "*" on secrets`,
+			})
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-unhealthy", UID: "scopeinstance-unhealthy-uid"},
+				Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName(), Namespaces: []string{"ns-1"}},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test")),
+				Scheme: scheme.Scheme,
+			}
+		})
+		templateUnhealthy := func() *metav1.Condition {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+			existing := &operatorsv1.ScopeInstance{}
+			ExpectWithOffset(1, r.Client.Get(ctx, client.ObjectKeyFromObject(si), existing)).To(Succeed())
+			return meta.FindStatusCondition(existing.Status.Conditions, operatorsv1.TypeTemplateUnhealthy)
+		}
+
+		It("should surface the failure of the ScopeTemplate on the ScopeInstance", func() {
+			cond := templateUnhealthy()
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonPolicyViolation))
+			Expect(cond.Message).To(Equal(`ScopeTemplate scopetemplate-unhealthy: ClusterRole test grants "*" on secrets`))
+		})
+
+		It("should clear the condition once the ScopeTemplate recovers", func() {
+			Expect(templateUnhealthy().Status).To(Equal(metav1.ConditionTrue))
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(st), st)).To(Succeed())
+			updateStatusTemplatingSuccessful(st, "ClusterRoles created")
+			Expect(r.Client.Status().Update(ctx, st)).To(Succeed())
+			cond := templateUnhealthy()
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonTemplateHealthy))
+		})
+
+		It("should not add the condition for a healthy ScopeTemplate", func() {
+			st.Status.Conditions = nil
+			r.Client = newFakeClient(si, st, newTestClusterRole("test"))
+			Expect(templateUnhealthy()).To(BeNil())
+		})
+
+		It("should only requeue for status updates that change the health of the ScopeTemplate", func() {
+			healthy := st.DeepCopy()
+			updateStatusTemplatingSuccessful(healthy, "ClusterRoles created")
+			Expect(scopeTemplateHealthChanged(event.UpdateEvent{ObjectOld: st, ObjectNew: healthy})).To(BeTrue())
+
+			relabeled := st.DeepCopy()
+			relabeled.SetLabels(map[string]string{"team": "a"})
+			Expect(scopeTemplateHealthChanged(event.UpdateEvent{ObjectOld: st, ObjectNew: relabeled})).To(BeFalse())
+		})
+	})
+
+	When("a ScopeInstance pins its ScopeTemplate by UID", func() {
+		var (
+			r      *ScopeInstanceReconciler
+			si     *operatorsv1.ScopeInstance
+			pinned *operatorsv1.ScopeTemplate
+		)
+		BeforeEach(func() {
+			// The ScopeInstance was moved to scopetemplate-uid, which does
+			// not exist yet, after pinning another ScopeTemplate
+			pinned = newTestScopeTemplate("scopetemplate-uid-pinned")
+			pinned.SetUID("scopetemplate-uid-uid")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-uid", UID: "scopeinstance-uid-uid"},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: "scopetemplate-uid",
+					ScopeTemplateUID:  pinned.GetUID(),
+					Namespaces:        []string{"ns-1"},
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, pinned, newTestClusterRole("test")),
+				Scheme: scheme.Scheme,
+			}
+		})
+		reconcileUID := func() *operatorsv1.ScopeInstance {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+			existing := &operatorsv1.ScopeInstance{}
+			ExpectWithOffset(1, r.Client.Get(ctx, client.ObjectKeyFromObject(si), existing)).To(Succeed())
+			return existing
+		}
+
+		It("should bind the ScopeTemplate with the UID rather than the one named", func() {
+			existing := reconcileUID()
+			Expect(meta.IsStatusConditionTrue(existing.Status.Conditions, operatorsv1.TypeScoped)).To(BeTrue())
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si)).To(HaveLen(1))
+		})
+
+		It("should fall back to the name when no ScopeTemplate has the UID", func() {
+			Expect(r.Client.Delete(ctx, pinned)).To(Succeed())
+			existing := reconcileUID()
+			cond := meta.FindStatusCondition(existing.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonScopeTemplateNotFound))
+
+			Expect(r.Client.Create(ctx, newTestScopeTemplate("scopetemplate-uid"))).To(Succeed())
+			existing = reconcileUID()
+			Expect(meta.IsStatusConditionTrue(existing.Status.Conditions, operatorsv1.TypeScoped)).To(BeTrue())
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si)).To(HaveLen(1))
+		})
+
+		It("should report a failure to look the ScopeTemplate up by UID", func() {
+			r.Client = &failingListClient{Client: r.Client}
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).To(HaveOccurred())
+			existing := &operatorsv1.ScopeInstance{}
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), existing)).To(Succeed())
+			cond := meta.FindStatusCondition(existing.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonScopingFailed))
+			Expect(cond.Message).To(ContainSubstring("injected failure"))
+		})
+
+		It("should enqueue the ScopeInstance for the ScopeTemplate it pins", func() {
+			Expect(r.mapToScopeInstance(pinned)).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}},
+			))
+			Expect(r.mapToScopeInstance(newTestScopeTemplate("scopetemplate-other"))).To(BeEmpty())
+
+			str := &ScopeTemplateReconciler{Client: r.Client, Scheme: scheme.Scheme}
+			Expect(str.mapToScopeTemplate(si)).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: pinned.GetName()}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: "scopetemplate-uid"}},
+			))
+		})
+	})
+
+	When("ScopeInstances are annotated for a dry run", func() {
+		var (
+			c  *dryRunClient
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-dry-run")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "scopeinstance-dry-run",
+					UID:         "scopeinstance-dry-run-uid",
+					Annotations: map[string]string{dryRunKey: "true"},
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName(), Namespaces: []string{"ns-1"}},
+			}
+			c = &dryRunClient{Client: newFakeClient(si, st, newTestClusterRole("test"))}
+			r = &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme}
+		})
+		reconcileDryRun := func() *operatorsv1.ScopeInstance {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+			existing := &operatorsv1.ScopeInstance{}
+			ExpectWithOffset(1, c.Get(ctx, client.ObjectKeyFromObject(si), existing)).To(Succeed())
+			return existing
+		}
+
+		It("should create the bindings with a server-side dry run only", func() {
+			existing := reconcileDryRun()
+			Expect(c.creates).To(HaveLen(1))
+			Expect(c.creates[0].DryRun).To(Equal([]string{metav1.DryRunAll}))
+			Expect(listFakeRoleBindings(c, "ns-1", si)).To(BeEmpty())
+
+			cond := meta.FindStatusCondition(existing.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonDryRun))
+			Expect(cond.Message).To(Equal("dry run of 1 (Cluster)RoleBindings found 0 discrepancies, nothing was written"))
+			Expect(existing.Status.DryRunDiscrepancies).To(BeEmpty())
+		})
+
+		It("should surface the mutations of admission in the status", func() {
+			c.mutate = func(obj client.Object) {
+				obj.SetLabels(labels.Merge(obj.GetLabels(), map[string]string{"team.example.com/owner": "platform"}))
+			}
+			existing := reconcileDryRun()
+			Expect(listFakeRoleBindings(c, "ns-1", si)).To(BeEmpty())
+			Expect(existing.Status.DryRunDiscrepancies).To(Equal([]string{
+				`RoleBinding test in namespace ns-1: label team.example.com/owner would be "platform"`,
+			}))
+			Expect(meta.FindStatusCondition(existing.Status.Conditions, operatorsv1.TypeScoped).Message).To(ContainSubstring("found 1 discrepancies"))
+		})
+
+		It("should write the bindings and clear the discrepancies once the annotation is removed", func() {
+			c.mutate = func(obj client.Object) {
+				obj.SetLabels(labels.Merge(obj.GetLabels(), map[string]string{"team.example.com/owner": "platform"}))
+			}
+			Expect(reconcileDryRun().Status.DryRunDiscrepancies).To(HaveLen(1))
+
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			delete(si.Annotations, dryRunKey)
+			Expect(c.Update(ctx, si)).To(Succeed())
+			existing := reconcileDryRun()
+			Expect(listFakeRoleBindings(c, "ns-1", si)).To(HaveLen(1))
+			Expect(existing.Status.DryRunDiscrepancies).To(BeNil())
+			Expect(meta.FindStatusCondition(existing.Status.Conditions, operatorsv1.TypeScoped).Reason).NotTo(Equal(operatorsv1.ReasonDryRun))
+		})
+	})
+
+	When("bindings have more subjects than MaxSubjectsPerBinding", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			st *operatorsv1.ScopeTemplate
+			si *operatorsv1.ScopeInstance
+		)
+		group := func(name string) rbacv1.Subject {
+			return rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: name}
+		}
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-subject-limit")
+			st.Spec.Bindings = []operatorsv1.BindingTemplate{{
+				GenerateName: "crowded",
+				RoleRef:      rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "test"},
+				Subjects:     []rbacv1.Subject{group("sre"), group("developers"), group("auditors")},
+			}}
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-subject-limit", UID: "scopeinstance-subject-limit-uid"},
+				Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName(), Namespaces: []string{"ns-1"}},
+			}
+			r = &ScopeInstanceReconciler{
+				Client:                newFakeClient(si, st, newTestClusterRole("test")),
+				Scheme:                scheme.Scheme,
+				MaxSubjectsPerBinding: 2,
+			}
+		})
+		reconcileSubjectLimit := func() *operatorsv1.ScopeInstance {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+			existing := &operatorsv1.ScopeInstance{}
+			ExpectWithOffset(1, r.Client.Get(ctx, client.ObjectKeyFromObject(si), existing)).To(Succeed())
+			return existing
+		}
+		generateNames := func() []string {
+			var names []string
+			for _, rb := range listFakeRoleBindings(r.Client, "ns-1", si) {
+				names = append(names, rb.Labels[clusterRoleBindingGenerateKey])
+			}
+			return names
+		}
+
+		It("should skip the binding over the limit", func() {
+			existing := reconcileSubjectLimit()
+			Expect(generateNames()).To(ConsistOf("test"))
+
+			cond := meta.FindStatusCondition(existing.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonTooManySubjects))
+			Expect(cond.Message).To(ContainSubstring("RoleBinding crowded in namespace ns-1 has 3 subjects, more than the maximum of 2"))
+		})
+
+		It("should not report the skipped binding as converged", func() {
+			reconcileSubjectLimit()
+			existing := reconcileSubjectLimit()
+			progressing := meta.FindStatusCondition(existing.Status.Conditions, operatorsv1.TypeProgressing)
+			Expect(progressing).NotTo(BeNil())
+			Expect(progressing.Status).To(Equal(metav1.ConditionFalse))
+			Expect(progressing.Reason).To(Equal(operatorsv1.ReasonTooManySubjects))
+		})
+
+		It("should leave the existing binding in place once it exceeds the limit", func() {
+			r.MaxSubjectsPerBinding = 0
+			reconcileSubjectLimit()
+			Expect(generateNames()).To(ConsistOf("test", "crowded"))
+
+			r.MaxSubjectsPerBinding = 2
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(st), st)).To(Succeed())
+			st.Spec.ClusterRoles[0].Subjects = append(st.Spec.ClusterRoles[0].Subjects, group("sre"))
+			Expect(r.Client.Update(ctx, st)).To(Succeed())
+			existing := reconcileSubjectLimit()
+			Expect(meta.FindStatusCondition(existing.Status.Conditions, operatorsv1.TypeScoped).Reason).To(Equal(operatorsv1.ReasonTooManySubjects))
+			Expect(generateNames()).To(ConsistOf("test", "crowded"))
+			for _, rb := range listFakeRoleBindings(r.Client, "ns-1", si) {
+				if rb.Labels[clusterRoleBindingGenerateKey] == "test" {
+					Expect(rb.Subjects).To(HaveLen(2))
+				}
+			}
+		})
+	})
+
+	When("ScopeTemplate Bindings select ClusterRoles by label", func() {
+		var (
+			r               *ScopeInstanceReconciler
+			st              *operatorsv1.ScopeTemplate
+			si              *operatorsv1.ScopeInstance
+			teamB           *rbacv1.ClusterRole
+			aggregateLabels = map[string]string{"rbac.example.com/aggregate-to-team": "true"}
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-cluster-role-selector")
+			st.Spec.ClusterRoles = nil
+			st.Spec.Bindings = []operatorsv1.BindingTemplate{{
+				GenerateName:        "team",
+				ClusterRoleSelector: &metav1.LabelSelector{MatchLabels: aggregateLabels},
+				Subjects:            []rbacv1.Subject{{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: "manager"}},
+			}}
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-cluster-role-selector", UID: "scopeinstance-cluster-role-selector-uid"},
+				Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName(), Namespaces: []string{"ns-1"}},
+			}
+			teamA := newTestClusterRole("team-a")
+			teamA.Labels = aggregateLabels
+			teamB = newTestClusterRole("team-b")
+			teamB.Labels = aggregateLabels
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, teamA, teamB, newTestClusterRole("unrelated")),
+				Scheme: scheme.Scheme,
+			}
+		})
+		reconcileSelected := func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		}
+		boundClusterRoles := func() map[string]string {
+			bound := map[string]string{}
+			for _, rb := range listFakeRoleBindings(r.Client, "ns-1", si) {
+				bound[rb.Labels[clusterRoleBindingGenerateKey]] = rb.RoleRef.Name
+			}
+			return bound
+		}
+
+		It("should bind each matching ClusterRole", func() {
+			reconcileSelected()
+			Expect(boundClusterRoles()).To(Equal(map[string]string{"team-team-a": "team-a", "team-team-b": "team-b"}))
+
+			existing := &operatorsv1.ScopeInstance{}
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), existing)).To(Succeed())
+			Expect(existing.Status.GeneratedBindings).To(HaveLen(2))
+			expectIdempotentReconcile(r, si.GetName())
+		})
+
+		It("should delete the bindings of a ClusterRole that is removed", func() {
+			reconcileSelected()
+
+			By("deleting one of the matching ClusterRoles")
+			Expect(r.mapClusterRoleToScopeInstance(teamB)).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: si.GetName()},
+			}))
+			Expect(r.Client.Delete(ctx, teamB)).To(Succeed())
+			reconcileSelected()
+			Expect(boundClusterRoles()).To(Equal(map[string]string{"team-team-a": "team-a"}))
+			expectIdempotentReconcile(r, si.GetName())
+		})
+
+		It("should only requeue for the ClusterRoles matching a selector", func() {
+			Expect(r.mapClusterRoleToScopeInstance(newTestClusterRole("unrelated"))).To(BeEmpty())
+		})
+
+		It("should bind each matching ClusterRole in the desired bindings", func() {
+			expanded, err := r.withSelectedClusterRoles(ctx, st)
+			Expect(err).NotTo(HaveOccurred())
+			desired, err := r.desiredBindings(ctx, si, expanded, si.Spec.Namespaces, nil)
+			Expect(err).NotTo(HaveOccurred())
+			roleRefs := map[string]string{}
+			for _, binding := range desired {
+				roleRefs[binding.GetLabels()[clusterRoleBindingGenerateKey]] = bindingRoleRef(binding).Name
+			}
+			Expect(roleRefs).To(Equal(map[string]string{"team-team-a": "team-a", "team-team-b": "team-b"}))
+		})
+
+		// expectInvalidSelector reconciles the ScopeInstance once the
+		// ScopeTemplate is updated, expecting no binding to be created.
+		expectInvalidSelector := func(update func(st *operatorsv1.ScopeTemplate), message string) {
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(st), st)).To(Succeed())
+			update(st)
+			Expect(r.Client.Update(ctx, st)).To(Succeed())
+			reconcileSelected()
+
+			Expect(boundClusterRoles()).To(BeEmpty())
+			existing := &operatorsv1.ScopeInstance{}
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), existing)).To(Succeed())
+			cond := meta.FindStatusCondition(existing.Status.Conditions, operatorsv1.TypeScoped)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonInvalidClusterRoleSelector))
+			Expect(cond.Message).To(ContainSubstring(message))
+		}
+
+		It("should refuse an empty selector", func() {
+			expectInvalidSelector(func(st *operatorsv1.ScopeTemplate) {
+				st.Spec.Bindings[0].ClusterRoleSelector = &metav1.LabelSelector{}
+			}, "would select every ClusterRole")
+		})
+
+		It("should refuse the selected ClusterRoles whose generateName is taken", func() {
+			expectInvalidSelector(func(st *operatorsv1.ScopeTemplate) {
+				st.Spec.Bindings = append(st.Spec.Bindings, operatorsv1.BindingTemplate{
+					GenerateName: "team-team-a",
+					RoleRef:      rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "unrelated"},
+					Subjects:     st.Spec.Bindings[0].Subjects,
+				})
+			}, "several entries would have the generateName team-team-a")
+		})
+	})
+
+	When("ScopeTemplates reference group sets", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+			cm *corev1.ConfigMap
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-group-set")
+			st.Spec.ClusterRoles[0].GroupSets = []string{"platform-team"}
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-group-set", UID: "scopeinstance-group-set-uid"},
+				Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName(), Namespaces: []string{"ns-1"}},
+			}
+			cm = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "oria-system", Name: "platform-team"},
+				Data:       map[string]string{groupSetGroupsKey: "sre\n\n  developers\nmanager\n"},
+			}
+			r = &ScopeInstanceReconciler{
+				Client:            newFakeClient(si, st, cm, newTestClusterRole("test")),
+				Scheme:            scheme.Scheme,
+				GroupSetNamespace: "oria-system",
+			}
+		})
+		reconcileGroupSet := func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		}
+		group := func(name string) rbacv1.Subject {
+			return rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: name}
+		}
+		subjects := func() []rbacv1.Subject {
+			rbs := listFakeRoleBindings(r.Client, "ns-1", si)
+			ExpectWithOffset(1, rbs).To(HaveLen(1))
+			return rbs[0].Subjects
+		}
+
+		It("should bind the groups of the group set", func() {
+			reconcileGroupSet()
+			Expect(subjects()).To(ConsistOf(group("manager"), group("developers"), group("sre")))
+			expectIdempotentReconcile(r, si.GetName())
+		})
+
+		It("should follow the changes to the group set", func() {
+			reconcileGroupSet()
+
+			By("changing the groups of the group set")
+			Expect(r.mapGroupSetToScopeInstance(cm)).To(ConsistOf(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: si.GetName()},
+			}))
+			cm.Data[groupSetGroupsKey] = "auditors"
+			Expect(r.Client.Update(ctx, cm)).To(Succeed())
+			reconcileGroupSet()
+			Expect(subjects()).To(ConsistOf(group("manager"), group("auditors")))
+		})
+
+		It("should only requeue for the group sets referenced in the group set namespace", func() {
+			other := cm.DeepCopy()
+			other.Namespace = "default"
+			Expect(r.mapGroupSetToScopeInstance(other)).To(BeEmpty())
+			other = cm.DeepCopy()
+			other.Name = "other-team"
+			Expect(r.mapGroupSetToScopeInstance(other)).To(BeEmpty())
+		})
+
+		It("should fail to scope a ScopeInstance whose group set is missing", func() {
+			Expect(r.Client.Delete(ctx, cm)).To(Succeed())
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).To(MatchError(ContainSubstring("getting group set platform-team for ClusterRole test")))
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si)).To(BeEmpty())
+		})
+	})
+
+	When("bindings record their provenance", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+			st *operatorsv1.ScopeTemplate
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-provenance")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-provenance", UID: "scopeinstance-provenance-uid"},
+				Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName(), Namespaces: []string{"ns-1"}},
+			}
+			r = &ScopeInstanceReconciler{Client: newFakeClient(si, st, newTestClusterRole("test")), Scheme: scheme.Scheme}
+		})
+		reconcile := func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		}
+		// expectProvenance asserts that the RoleBinding in ns-1 records the
+		// ScopeInstance, the ScopeTemplate and their current hash.
+		expectProvenance := func() *rbacv1.RoleBinding {
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(st), st)).To(Succeed())
+			rbs := listFakeRoleBindings(r.Client, "ns-1", si)
+			ExpectWithOffset(1, rbs).To(HaveLen(1))
+			ExpectWithOffset(1, rbs[0].Annotations).To(And(
+				HaveKeyWithValue(provenanceScopeInstanceKey, "scopeinstance-provenance"),
+				HaveKeyWithValue(provenanceScopeTemplateKey, "scopetemplate-provenance"),
+				HaveKeyWithValue(referenceHashKey, hashScopeInstanceAndTemplate(si, st)),
+			))
+			return &rbs[0]
+		}
+
+		It("should annotate the bindings with their provenance and keep it up to date", func() {
+			reconcile()
+			created := expectProvenance()
+			expectIdempotentReconcile(r, si.GetName())
+
+			By("changing the subjects of the ScopeTemplate")
+			st.Spec.ClusterRoles[0].Subjects = append(st.Spec.ClusterRoles[0].Subjects, rbacv1.Subject{Kind: "User", APIGroup: rbacv1.GroupName, Name: "auditor"})
+			Expect(r.Client.Update(ctx, st)).To(Succeed())
+			reconcile()
+			updated := expectProvenance()
+			Expect(updated.Name).To(Equal(created.Name))
+			Expect(updated.Annotations[referenceHashKey]).NotTo(Equal(created.Annotations[referenceHashKey]))
+		})
+
+		It("should annotate the bindings created before provenance was recorded", func() {
+			reconcile()
+			rb := expectProvenance()
+			delete(rb.Annotations, provenanceScopeInstanceKey)
+			delete(rb.Annotations, provenanceScopeTemplateKey)
+			Expect(r.Client.Update(ctx, rb)).To(Succeed())
+
+			reconcile()
+			expectProvenance()
+		})
+	})
+
+	When("the cache still lists deleted bindings", func() {
+		It("should not take them for duplicates and converge once the deletions are observed", func() {
+			st := newTestScopeTemplate("scopetemplate-cache-lag")
+			si := &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-cache-lag",
+					UID:  "scopeinstance-cache-lag-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1"},
+				},
+			}
+			c := &laggingCacheClient{Client: newFakeClient(si, st, newTestClusterRole("test"), newTestClusterRole("test-override"))}
+			r := &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme}
+
+			// reconcile reconciles the ScopeInstance and returns its
+			// Progressing condition.
+			reconcile := func() (ctrl.Result, *metav1.Condition) {
+				res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+				ExpectWithOffset(1, err).NotTo(HaveOccurred())
+				ExpectWithOffset(1, r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+				return res, meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeProgressing)
+			}
+			reconcile()
+
+			By("replacing the RoleBinding with one of another ClusterRole")
+			si.Spec.ClusterRoleNameOverrides = map[string]string{"test": "test-override"}
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+			reconcile()
+			Expect(c.deleted).To(HaveLen(1))
+			Expect(listFakeRoleBindings(c, "ns-1", si)).To(HaveLen(2))
+
+			By("ignoring the deleted RoleBinding the cache still lists")
+			res, progressing := reconcile()
+			Expect(res.RequeueAfter).To(Equal(deletionObservedDelay))
+			Expect(progressing.Status).To(Equal(metav1.ConditionTrue))
+			Expect(progressing.Reason).To(Equal(operatorsv1.ReasonDeletionsUnobserved))
+			Expect(meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped).Reason).To(Equal(operatorsv1.ReasonScopingSuccessful))
+			rbs := listFakeRoleBindings(c.Client, "ns-1", si)
+			Expect(rbs).To(HaveLen(1))
+			Expect(rbs[0].RoleRef.Name).To(Equal("test-override"))
+
+			By("converging once the cache observes the deletion")
+			c.observe(r)
+			res, progressing = reconcile()
+			Expect(res.RequeueAfter).To(BeZero())
+			Expect(progressing.Status).To(Equal(metav1.ConditionFalse))
+			Expect(listFakeRoleBindings(c, "ns-1", si)).To(Equal(rbs))
+			expectNoWrites(r, si.GetName())
+		})
+	})
+
+	When("a ScopeInstance converges", func() {
+		It("should be Progressing while it writes bindings and not once they are as desired", func() {
+			st := newTestScopeTemplate("scopetemplate-progressing")
+			si := &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-progressing",
+					UID:  "scopeinstance-progressing-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1", "ns-2"},
+				},
+			}
+			r := &ScopeInstanceReconciler{Client: newFakeClient(si, st, newTestClusterRole("test")), Scheme: scheme.Scheme}
+
+			// progressing reconciles the ScopeInstance and returns its
+			// Progressing condition.
+			progressing := func() *metav1.Condition {
+				_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+				ExpectWithOffset(1, err).NotTo(HaveOccurred())
+				ExpectWithOffset(1, r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+				return meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeProgressing)
+			}
+
+			condition := progressing()
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(operatorsv1.ReasonBindingsChanging))
+			Expect(condition.Message).To(Equal("Created 2, updated 0 and deleted 0 bindings"))
+
+			condition = progressing()
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(operatorsv1.ReasonConverged))
+			Expect(meta.IsStatusConditionTrue(si.Status.Conditions, operatorsv1.TypeScoped)).To(BeTrue())
+			expectNoWrites(r, si.GetName())
+
+			By("progressing again once a namespace is deselected")
+			si.Spec.Namespaces = []string{"ns-1"}
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+			condition = progressing()
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Message).To(Equal("Created 0, updated 0 and deleted 1 bindings"))
+			expectIdempotentReconcile(r, si.GetName())
+		})
+
+		It("should not report a ScopeInstance refused without retrying as converged", func() {
+			st := newTestScopeTemplate("scopetemplate-refused")
+			st.Spec.ClusterRoles[0].SubjectExpression = `[{"kind": "ServiceAccount", "name": unknown}]`
+			si := &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-refused",
+					UID:  "scopeinstance-refused-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1"},
+				},
+			}
+			r := &ScopeInstanceReconciler{Client: newFakeClient(si, st, newTestClusterRole("test")), Scheme: scheme.Scheme}
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			condition := meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeProgressing)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(operatorsv1.ReasonInvalidSubjectExpression))
+		})
+	})
+
+	When("lists are served by a read replica", func() {
+		It("should list from the Reader and write through the Client", func() {
+			st := newTestScopeTemplate("scopetemplate-replica")
+			si := &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-replica",
+					UID:  "scopeinstance-replica-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1"},
+				},
+			}
+			base := newFakeClient(si, st, newTestClusterRole("test"))
+			primary := &listKindRecorder{Client: base}
+			replica := &listKindRecorder{Client: base}
+			c := &writeCountingClient{Client: primary}
+			r := &ScopeInstanceReconciler{Client: c, Reader: replica, Scheme: scheme.Scheme}
+
+			Expect(r.ensureBindings(ctx, si, st, si.Spec.Namespaces, sets.NewString(), sets.NewString())).To(Succeed())
+			Expect(r.mapToScopeInstance(st)).To(ConsistOf(ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}}))
+
+			Expect(replica.lists).To(ConsistOf("*v1.RoleBindingList", "*v1alpha1.ScopeInstanceList"))
+			Expect(primary.lists).To(BeEmpty())
+			Expect(c.writes).To(ContainElement(HavePrefix("create *v1.RoleBinding ns-1/")))
+			Expect(listFakeRoleBindings(base, "ns-1", si)).To(HaveLen(1))
+		})
+	})
+
+	When("a ScopeInstance names its RoleBindings with a template", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			st *operatorsv1.ScopeTemplate
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-binding-name")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-binding-name",
+					UID:  "scopeinstance-binding-name-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName:   st.GetName(),
+					Namespaces:          []string{"ns-1", "ns-2"},
+					BindingNameTemplate: "{{.ClusterRole}}-{{.Namespace}}",
+				},
+			}
+		})
+
+		// reconcile reconciles the ScopeInstance and returns its Scoped
+		// condition.
+		reconcile := func() (*metav1.Condition, error) {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			ExpectWithOffset(1, r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			return meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped), err
+		}
+
+		names := func(namespace string) []string {
+			var names []string
+			for _, rb := range listFakeRoleBindings(r.Client, namespace, si) {
+				names = append(names, rb.GetName())
+			}
+			return names
+		}
+
+		It("should name the RoleBindings as rendered and rename them when the template changes", func() {
+			r = &ScopeInstanceReconciler{Client: newFakeClient(si, st, newTestClusterRole("test")), Scheme: scheme.Scheme}
+			_, err := reconcile()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names("ns-1")).To(ConsistOf("test-ns-1"))
+			Expect(names("ns-2")).To(ConsistOf("test-ns-2"))
+			expectIdempotentReconcile(r, si.GetName())
+
+			By("renaming the RoleBindings")
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.BindingNameTemplate = "{{.ScopeInstance}}-{{.GenerateName}}"
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+			_, err = reconcile()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names("ns-1")).To(ConsistOf("scopeinstance-binding-name-test"))
+			Expect(names("ns-2")).To(ConsistOf("scopeinstance-binding-name-test"))
+		})
+
+		It("should refuse to bind another role under the name of a RoleBinding", func() {
+			si.Spec.BindingNameTemplate = "access-{{.Namespace}}"
+			r = &ScopeInstanceReconciler{Client: newFakeClient(si, st, newTestClusterRole("test"), newTestClusterRole("test-override")), Scheme: scheme.Scheme}
+			_, err := reconcile()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names("ns-1")).To(ConsistOf("access-ns-1"))
+
+			By("overriding the ClusterRole without changing the rendered name")
+			si.Spec.ClusterRoleNameOverrides = map[string]string{"test": "test-override"}
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+			cond, err := reconcile()
+			Expect(err).To(MatchError(errBindingNameCollision))
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonBindingNameCollision))
+			Expect(cond.Message).To(ContainSubstring("RoleBinding ns-1/access-ns-1 binds ClusterRole test and would be deleted to bind ClusterRole test-override"))
+			for _, ns := range []string{"ns-1", "ns-2"} {
+				rbs := listFakeRoleBindings(r.Client, ns, si)
+				Expect(rbs).To(HaveLen(1))
+				Expect(rbs[0].RoleRef.Name).To(Equal("test"))
+			}
+
+			By("binding the other role once the RoleBindings are deleted")
+			for _, ns := range []string{"ns-1", "ns-2"} {
+				rbs := listFakeRoleBindings(r.Client, ns, si)
+				for i := range rbs {
+					Expect(r.Client.Delete(ctx, &rbs[i])).To(Succeed())
+				}
+			}
+			_, err = reconcile()
+			Expect(err).NotTo(HaveOccurred())
+			rbs := listFakeRoleBindings(r.Client, "ns-1", si)
+			Expect(rbs).To(HaveLen(1))
+			Expect(rbs[0].GetName()).To(Equal("access-ns-1"))
+			Expect(rbs[0].RoleRef.Name).To(Equal("test-override"))
+		})
+
+		It("should report two ClusterRoles rendering the same name", func() {
+			second := st.Spec.ClusterRoles[0]
+			second.GenerateName = "test-2"
+			st.Spec.ClusterRoles = append(st.Spec.ClusterRoles, second)
+			si.Spec.BindingNameTemplate = "access-{{.Namespace}}"
+			r = &ScopeInstanceReconciler{Client: newFakeClient(si, st, newTestClusterRole("test"), newTestClusterRole("test-2")), Scheme: scheme.Scheme}
+
+			cond, err := reconcile()
+			Expect(err).To(MatchError(errBindingNameCollision))
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonBindingNameCollision))
+			Expect(cond.Message).To(ContainSubstring("ClusterRoles test and test-2 both render RoleBinding ns-1/access-ns-1"))
+			Expect(names("ns-1")).To(BeEmpty())
+		})
+
+		It("should not take over a RoleBinding it does not manage", func() {
+			taken := &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "test-ns-1"},
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "view"},
+			}
+			r = &ScopeInstanceReconciler{Client: newFakeClient(si, st, newTestClusterRole("test"), taken), Scheme: scheme.Scheme}
+
+			cond, err := reconcile()
+			Expect(err).To(MatchError(errBindingNameCollision))
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonBindingNameCollision))
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(taken), taken)).To(Succeed())
+			Expect(taken.RoleRef.Name).To(Equal("view"))
+			Expect(taken.Labels).NotTo(HaveKey(scopeInstanceUIDKey))
+		})
+
+		It("should report a template that does not render a valid name", func() {
+			si.Spec.BindingNameTemplate = "{{.Namespace}}/{{.ClusterRole}}"
+			r = &ScopeInstanceReconciler{Client: newFakeClient(si, st, newTestClusterRole("test")), Scheme: scheme.Scheme}
+
+			cond, err := reconcile()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cond.Reason).To(Equal(operatorsv1.ReasonInvalidBindingNameTemplate))
+			Expect(names("ns-1")).To(BeEmpty())
+		})
+	})
+
+	When("the status writes of a ScopeInstance are debounced", func() {
+		var (
+			c  *writeCountingClient
+			st *operatorsv1.ScopeTemplate
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-debounce")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-debounce",
+					UID:  "scopeinstance-debounce-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1"},
+				},
+			}
+			c = &writeCountingClient{Client: newFakeClient(si, st, newTestClusterRole("test"),
+				newTestClusterRole("test-1"), newTestClusterRole("test-2"), newTestClusterRole("test-3"))}
+		})
+
+		// burst reconciles the ScopeInstance after each of three changes
+		// to its ScopeTemplate, each adding a ClusterRole to its status, and
+		// returns how many status writes were made.
+		burst := func(r *ScopeInstanceReconciler) int {
+			for i := 1; i <= 3; i++ {
+				ExpectWithOffset(1, c.Get(ctx, client.ObjectKeyFromObject(st), st)).To(Succeed())
+				clusterRole := st.Spec.ClusterRoles[0]
+				clusterRole.GenerateName = fmt.Sprintf("test-%d", i)
+				st.Spec.ClusterRoles = append(st.Spec.ClusterRoles, clusterRole)
+				ExpectWithOffset(1, c.Update(ctx, st)).To(Succeed())
+				res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+				ExpectWithOffset(1, err).NotTo(HaveOccurred())
+				if r.StatusDebounce > 0 {
+					ExpectWithOffset(1, res.RequeueAfter).To(BeNumerically(">", 0))
+					ExpectWithOffset(1, res.RequeueAfter).To(BeNumerically("<=", r.StatusDebounce))
+				}
+			}
+			writes := 0
+			for _, write := range c.writes {
+				if strings.HasPrefix(write, "patch status") {
+					writes++
+				}
+			}
+			return writes
+		}
+
+		It("should write every status change without a debounce", func() {
+			r := &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme}
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(burst(r)).To(Equal(4))
+		})
+
+		It("should coalesce the status writes of a burst and write the final status once the window passes", func() {
+			r := &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme, StatusDebounce: time.Hour}
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(burst(r)).To(Equal(1))
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.Status.GeneratedBindings).To(HaveLen(1))
+
+			By("writing the status current at the end of the window")
+			r.statusWrittenAt[si.GetName()] = time.Now().Add(-time.Hour)
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.Status.GeneratedBindings).To(HaveLen(4))
+			expectIdempotentReconcile(r, si.GetName())
+		})
+	})
+
+	When("a namespace is annotated to be excluded from scoping", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-no-scope")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-no-scope",
+					UID:  "scopeinstance-no-scope-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1", "ns-2"},
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test"),
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-1"}},
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-2"}},
+				),
+				Scheme: scheme.Scheme,
+				dirty:  newDirtyNamespaces(),
+			}
+		})
+
+		// setExcluded sets the exclusion annotation of the namespace, asserting
+		// that the update enqueues the ScopeInstance, and reconciles it.
+		setExcluded := func(name, value string) {
+			ns := &corev1.Namespace{}
+			ExpectWithOffset(1, r.Client.Get(ctx, client.ObjectKey{Name: name}, ns)).To(Succeed())
+			old := ns.DeepCopy()
+			ns.Annotations = map[string]string{noScopeKey: value}
+			ExpectWithOffset(1, r.Client.Update(ctx, ns)).To(Succeed())
+			ExpectWithOffset(1, r.namespaceSelectionChanged(event.UpdateEvent{ObjectOld: old, ObjectNew: ns})).To(BeTrue())
+			ExpectWithOffset(1, r.mapNamespaceToScopeInstance(ns)).To(ConsistOf(ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}}))
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		}
+
+		It("should delete its RoleBinding and not bind it again until the annotation is removed", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listFakeRoleBindings(r.Client, "ns-2", si)).To(HaveLen(1))
+
+			setExcluded("ns-2", "true")
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si)).To(HaveLen(1))
+			Expect(listFakeRoleBindings(r.Client, "ns-2", si)).To(BeEmpty())
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.Status.Namespaces).To(Equal([]string{"ns-1"}))
+
+			By("keeping the namespace excluded on a full reconcile")
+			expectIdempotentReconcile(r, si.GetName())
+			Expect(listFakeRoleBindings(r.Client, "ns-2", si)).To(BeEmpty())
+
+			By("binding the namespace again once it is no longer excluded")
+			setExcluded("ns-2", "false")
+			Expect(listFakeRoleBindings(r.Client, "ns-2", si)).To(HaveLen(1))
+		})
+	})
+
+	When("a ScopeTemplate binds both Roles and ClusterRoles", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-mixed")
+			st.Spec.Bindings = []operatorsv1.BindingTemplate{
+				{
+					GenerateName: "view",
+					RoleRef:      rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "view"},
+					Subjects:     []rbacv1.Subject{{Kind: "Group", APIGroup: rbacv1.GroupName, Name: "viewers"}},
+				},
+				{
+					GenerateName: "deployer",
+					RoleRef:      rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "deployer"},
+					Subjects:     []rbacv1.Subject{{Kind: "Group", APIGroup: rbacv1.GroupName, Name: "deployers"}},
+				},
+			}
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-mixed",
+					UID:  "scopeinstance-mixed-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1", "ns-2"},
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test"), newTestClusterRole("view")),
+				Scheme: scheme.Scheme,
+			}
+		})
+
+		// roleRefs returns the RoleRefs of the given bindings.
+		roleRefs := func(bindings []rbacv1.RoleBinding) []rbacv1.RoleRef {
+			var refs []rbacv1.RoleRef
+			for _, rb := range bindings {
+				refs = append(refs, rb.RoleRef)
+			}
+			return refs
+		}
+
+		It("should create a RoleBinding for every entry in each namespace", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, ns := range []string{"ns-1", "ns-2"} {
+				Expect(roleRefs(listFakeRoleBindings(r.Client, ns, si))).To(ConsistOf(
+					rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "test"},
+					rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "view"},
+					rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "deployer"},
+				))
+			}
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.Status.GeneratedBindings).To(HaveLen(3))
+			Expect(si.Status.GeneratedBindings[2]).To(Equal(operatorsv1.GeneratedBinding{
+				GenerateName: "deployer",
+				Kind:         "RoleBinding",
+				RoleRef:      rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "deployer"},
+			}))
+
+			expectIdempotentReconcile(r, si.GetName())
+		})
+
+		It("should only bind the ClusterRoles cluster wide", func() {
+			si.Spec.Namespaces = nil
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			var refs []rbacv1.RoleRef
+			for _, crb := range listFakeClusterRoleBindings(r.Client, si) {
+				refs = append(refs, crb.RoleRef)
+			}
+			Expect(refs).To(ConsistOf(
+				rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "test"},
+				rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "view"},
+			))
+			Expect(listFakeRoleBindings(r.Client, "", si)).To(BeEmpty())
+		})
+	})
+
+	When("bindings are deleted with a propagation policy", func() {
+		var (
+			c  *deleteOptionsRecorder
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-propagation")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-propagation",
+					UID:  "scopeinstance-propagation-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1", "ns-2"},
+				},
+			}
+			c = &deleteOptionsRecorder{Client: newFakeClient(si, st, newTestClusterRole("test"))}
+			r = &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme}
+		})
+
+		// removeNamespace reconciles the ScopeInstance, then removes ns-2 from
+		// it and reconciles it again.
+		removeNamespace := func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.Namespaces = []string{"ns-1"}
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listFakeRoleBindings(r.Client, "ns-2", si)).To(BeEmpty())
+		}
+
+		It("should pass the configured policy to every delete", func() {
+			r.DeletePropagation = metav1.DeletePropagationOrphan
+			removeNamespace()
+
+			Expect(c.deletes).To(HaveLen(1))
+			Expect(c.deletes[0].PropagationPolicy).NotTo(BeNil())
+			Expect(*c.deletes[0].PropagationPolicy).To(Equal(metav1.DeletePropagationOrphan))
+		})
+
+		It("should leave the policy to the API server by default", func() {
+			removeNamespace()
+
+			Expect(c.deletes).To(HaveLen(1))
+			Expect(c.deletes[0].PropagationPolicy).To(BeNil())
+		})
+	})
+
+	When("a ScopeInstance sets the audience of its bindings", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-audience")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-audience",
+					UID:  "scopeinstance-audience-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1"},
+					Audience:          "vault",
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test")),
+				Scheme: scheme.Scheme,
+			}
+		})
+
+		It("should annotate the RoleBindings with the audience", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			rbs := listFakeRoleBindings(r.Client, "ns-1", si)
+			Expect(rbs).To(HaveLen(1))
+			Expect(rbs[0].Annotations).To(HaveKeyWithValue(audienceKey, "vault"))
+			Expect(rbs[0].Annotations).To(HaveKey(referenceHashKey))
+
+			expectIdempotentReconcile(r, si.GetName())
+
+			By("changing the audience")
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.Audience = "sts.amazonaws.com"
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			rbs = listFakeRoleBindings(r.Client, "ns-1", si)
+			Expect(rbs).To(HaveLen(1))
+			Expect(rbs[0].Annotations).To(HaveKeyWithValue(audienceKey, "sts.amazonaws.com"))
+			Expect(rbs[0].Annotations).To(HaveKeyWithValue(referenceHashKey, hashScopeInstanceAndTemplate(si, newTestScopeTemplate("scopetemplate-audience"))))
+		})
+
+		It("should annotate the ClusterRoleBinding with the audience", func() {
+			si.Spec.Namespaces = nil
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			crbs := listFakeClusterRoleBindings(r.Client, si)
+			Expect(crbs).To(HaveLen(1))
+			Expect(crbs[0].Annotations).To(HaveKeyWithValue(audienceKey, "vault"))
+		})
+	})
+
+	When("the reconciles of a ScopeInstance keep failing", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			c  *failingCreateClient
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-circuit-breaker")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-circuit-breaker",
+					UID:  "scopeinstance-circuit-breaker-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-1"},
+				},
+			}
+			c = &failingCreateClient{
+				Client:    newFakeClient(si, st, newTestClusterRole("test")),
+				namespace: "ns-1",
+				err:       k8sapierrors.NewServiceUnavailable("etcd is overloaded"),
+			}
+			r = &ScopeInstanceReconciler{
+				Client:                  c,
+				Scheme:                  scheme.Scheme,
+				CircuitBreakerThreshold: 3,
+				CircuitBreakerInterval:  10 * time.Minute,
+			}
+		})
+		reconcileScopeInstance := func() (ctrl.Result, error) {
+			return r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+		}
+		expectScopedReason := func(reason string) {
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			cond := meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped)
+			ExpectWithOffset(1, cond).NotTo(BeNil())
+			ExpectWithOffset(1, cond.Reason).To(Equal(reason))
+		}
+
+		It("should back off to the circuit breaker interval until a reconcile succeeds", func() {
+			for i := 0; i < 2; i++ {
+				_, err := reconcileScopeInstance()
+				Expect(k8sapierrors.IsServiceUnavailable(err)).To(BeTrue())
+			}
+			expectScopedReason(operatorsv1.ReasonScopingFailed)
+
+			By("opening the circuit")
+			for i := 0; i < 2; i++ {
+				res, err := reconcileScopeInstance()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res.RequeueAfter).To(Equal(10 * time.Minute))
+			}
+			expectScopedReason(operatorsv1.ReasonCircuitOpen)
+			Expect(meta.FindStatusCondition(si.Status.Conditions, operatorsv1.TypeScoped).Message).To(
+				HavePrefix("4 consecutive reconciles failed, retrying every 10m0s"))
+
+			By("closing the circuit once the API server recovers")
+			c.namespace = ""
+			res, err := reconcileScopeInstance()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(BeZero())
+			expectScopedReason(operatorsv1.ReasonScopingSuccessful)
+
+			By("counting failures from zero again")
+			c.namespace = "ns-1"
+			Expect(c.Client.DeleteAllOf(ctx, &rbacv1.RoleBinding{}, client.InNamespace("ns-1"))).To(Succeed())
+			_, err = reconcileScopeInstance()
+			Expect(k8sapierrors.IsServiceUnavailable(err)).To(BeTrue())
 		})
-		It("should return a different hash if the scopetemplate changes", func() {
-			notexpected := util.HashObject(&referenceHash{
-				ScopeInstanceSpec: &si.Spec,
-				ScopeTemplateSpec: &st.Spec,
-			})
-			st.Spec = operatorsv1.ScopeTemplateSpec{}
-			thehash := hashScopeInstanceAndTemplate(si, st)
-			Expect(notexpected).ToNot(Equal(thehash))
+	})
+
+	When("a ScopeInstance lists a namespace twice", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-duplicate-namespaces")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-duplicate-namespaces",
+					UID:  "scopeinstance-duplicate-namespaces-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: st.GetName(),
+					Namespaces:        []string{"ns-2", "ns-1", "ns-2"},
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client:                     newFakeClient(si, st, newTestClusterRole("test")),
+				Scheme:                     scheme.Scheme,
+				MaxConcurrentBindingWrites: 4,
+			}
+		})
+
+		It("should create a single RoleBinding in it", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si)).To(HaveLen(1))
+			Expect(listFakeRoleBindings(r.Client, "ns-2", si)).To(HaveLen(1))
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.Status.Namespaces).To(Equal([]string{"ns-1", "ns-2"}))
+
+			expectIdempotentReconcile(r, si.GetName())
+		})
+	})
+
+	When("the status of a ScopeInstance lists its generated bindings", func() {
+		var (
+			r  *ScopeInstanceReconciler
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-generated-bindings")
+			clusterWide := st.Spec.ClusterRoles[0]
+			clusterWide.GenerateName = "cluster-test"
+			clusterWide.Scope = operatorsv1.ClusterRoleScopeCluster
+			st.Spec.ClusterRoles = append(st.Spec.ClusterRoles, clusterWide)
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "scopeinstance-generated-bindings",
+					UID:  "scopeinstance-generated-bindings-uid",
+				},
+				Spec: operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName:        st.GetName(),
+					Namespaces:               []string{"ns-1"},
+					ClusterRoleNameOverrides: map[string]string{"test": "test-staging"},
+				},
+			}
+			r = &ScopeInstanceReconciler{
+				Client: newFakeClient(si, st, newTestClusterRole("test"),
+					newTestClusterRole("test-staging"), newTestClusterRole("cluster-test")),
+				Scheme: scheme.Scheme,
+			}
+		})
+
+		It("should record the RoleRef of each binding", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			rbs := listFakeRoleBindings(r.Client, "ns-1", si)
+			Expect(rbs).To(HaveLen(1))
+			crbs := listFakeClusterRoleBindings(r.Client, si)
+			Expect(crbs).To(HaveLen(1))
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.Status.GeneratedBindings).To(Equal([]operatorsv1.GeneratedBinding{
+				{GenerateName: "test", Kind: "RoleBinding", RoleRef: rbs[0].RoleRef},
+				{GenerateName: "cluster-test", Kind: "ClusterRoleBinding", RoleRef: crbs[0].RoleRef},
+			}))
+			Expect(si.Status.GeneratedBindings[0].RoleRef.Name).To(Equal("test-staging"))
+			Expect(si.Status.GeneratedBindings[1].RoleRef.Name).To(Equal("cluster-test"))
+
+			expectIdempotentReconcile(r, si.GetName())
+		})
+
+		It("should clear the generated bindings once they are deleted", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.ExpiresAt = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			Expect(si.Status.GeneratedBindings).To(BeEmpty())
+		})
+	})
+
+	When("ScopeInstances sharing a ClusterRole consolidate their ClusterRoleBindings", func() {
+		var (
+			r        *ScopeInstanceReconciler
+			si, si2  *operatorsv1.ScopeInstance
+			manager  = rbacv1.Subject{Kind: "Group", APIGroup: rbacv1.GroupName, Name: "manager"}
+			auditors = rbacv1.Subject{Kind: "Group", APIGroup: rbacv1.GroupName, Name: "auditors"}
+		)
+		BeforeEach(func() {
+			st := newTestScopeTemplate("scopetemplate-shared")
+			st2 := newTestScopeTemplate("scopetemplate-shared-2")
+			st2.Spec.ClusterRoles[0].Subjects = []rbacv1.Subject{auditors}
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-shared", UID: "scopeinstance-shared-uid"},
+				Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName()},
+			}
+			si2 = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-shared-2", UID: "scopeinstance-shared-2-uid"},
+				Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st2.GetName()},
+			}
+			r = &ScopeInstanceReconciler{
+				Client:                         newFakeClient(si, si2, st, st2, newTestClusterRole("test")),
+				Scheme:                         scheme.Scheme,
+				ConsolidateClusterRoleBindings: true,
+			}
+			for _, in := range []*operatorsv1.ScopeInstance{si, si2} {
+				_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: in.GetName()}})
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+		getShared := func() (*rbacv1.ClusterRoleBinding, error) {
+			crb := &rbacv1.ClusterRoleBinding{}
+			return crb, r.Client.Get(ctx, client.ObjectKey{Name: "oria-shared-test"}, crb)
+		}
+
+		It("should merge the subjects of both into a single ClusterRoleBinding", func() {
+			crb, err := getShared()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(crb.RoleRef.Name).To(Equal("test"))
+			Expect(crb.Subjects).To(Equal([]rbacv1.Subject{auditors, manager}))
+			Expect(crb.GetLabels()).To(HaveKeyWithValue(sharedOwnerKeyPrefix+string(si.GetUID()), "true"))
+			Expect(crb.GetLabels()).To(HaveKeyWithValue(sharedOwnerKeyPrefix+string(si2.GetUID()), "true"))
+			for _, ref := range crb.OwnerReferences {
+				Expect(ref.Controller).To(BeNil())
+			}
+			Expect(crb.OwnerReferences).To(HaveLen(2))
+
+			for _, in := range []*operatorsv1.ScopeInstance{si, si2} {
+				Expect(listFakeClusterRoleBindings(r.Client, in)).To(BeEmpty())
+				expectIdempotentReconcile(r, in.GetName())
+			}
+		})
+
+		It("should annotate the ClusterRoleBinding with the provenance of each owner", func() {
+			crb, err := getShared()
+			Expect(err).NotTo(HaveOccurred())
+			for _, in := range []*operatorsv1.ScopeInstance{si, si2} {
+				Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(in), in)).To(Succeed())
+				st := &operatorsv1.ScopeTemplate{}
+				Expect(r.Client.Get(ctx, client.ObjectKey{Name: in.Spec.ScopeTemplateName}, st)).To(Succeed())
+				Expect(crb.Annotations).To(HaveKey(sharedProvenanceKeyPrefix + string(in.GetUID())))
+				provenance := sharedProvenance{}
+				Expect(json.Unmarshal([]byte(crb.Annotations[sharedProvenanceKeyPrefix+string(in.GetUID())]), &provenance)).To(Succeed())
+				Expect(provenance).To(Equal(sharedProvenance{
+					ScopeInstance: in.GetName(),
+					ScopeTemplate: st.GetName(),
+					Hash:          hashScopeInstanceAndTemplate(in, st),
+				}))
+			}
+
+			By("deleting one of the owners")
+			Expect(r.Client.Delete(ctx, si)).To(Succeed())
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			crb, err = getShared()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(crb.Annotations).NotTo(HaveKey(sharedProvenanceKeyPrefix + string(si.GetUID())))
+			Expect(crb.Annotations).To(HaveKey(sharedProvenanceKeyPrefix + string(si2.GetUID())))
+		})
+
+		It("should keep the ClusterRoleBinding for the remaining owner when one is deleted", func() {
+			Expect(r.Client.Delete(ctx, si)).To(Succeed())
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			crb, err := getShared()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(crb.Subjects).To(Equal([]rbacv1.Subject{auditors}))
+			Expect(crb.GetLabels()).NotTo(HaveKey(sharedOwnerKeyPrefix + string(si.GetUID())))
+			Expect(crb.OwnerReferences).To(ConsistOf(HaveField("UID", si2.GetUID())))
+
+			By("deleting the last owner")
+			Expect(r.Client.Delete(ctx, si2)).To(Succeed())
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si2.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = getShared()
+			Expect(k8sapierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should defer the deletion of the ClusterRoleBinding without owners in DeletionSafeMode", func() {
+			r.DeletionSafeMode = true
+			for _, in := range []*operatorsv1.ScopeInstance{si, si2} {
+				Expect(r.Client.Delete(ctx, in)).To(Succeed())
+			}
+			res, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si2.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(Equal(deletionConfirmationDelay))
+			crb, err := getShared()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(crb.OwnerReferences).To(HaveLen(2))
+
+			By("confirming the deletion on the next reconcile")
+			res, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si2.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).To(BeZero())
+			_, err = getShared()
+			Expect(k8sapierrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should leave the ClusterRoleBinding once a ScopeInstance selects namespaces", func() {
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si2), si2)).To(Succeed())
+			si2.Spec.Namespaces = []string{"ns-1"}
+			Expect(r.Client.Update(ctx, si2)).To(Succeed())
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si2.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			crb, err := getShared()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(crb.Subjects).To(Equal([]rbacv1.Subject{manager}))
+			Expect(listFakeRoleBindings(r.Client, "ns-1", si2)).To(HaveLen(1))
+		})
+
+		It("should replace the ClusterRoleBinding a ScopeInstance owned alone", func() {
+			r.ConsolidateClusterRoleBindings = false
+			Expect(r.Client.Delete(ctx, si2)).To(Succeed())
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listFakeClusterRoleBindings(r.Client, si)).To(HaveLen(1))
+
+			r.ConsolidateClusterRoleBindings = true
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listFakeClusterRoleBindings(r.Client, si)).To(BeEmpty())
+
+			By("dropping the owner deleted while consolidation was disabled")
+			crb, err := getShared()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(crb.Subjects).To(Equal([]rbacv1.Subject{manager}))
+			Expect(crb.OwnerReferences).To(ConsistOf(HaveField("UID", si.GetUID())))
 		})
 	})
 
@@ -493,3 +4708,493 @@ func listRoleBinding(namespace string, numberOfExpectedRoleBindings int, labels
 
 	return roleBindingList
 }
+
+// newTestScopeTemplate returns a ScopeTemplate with a single ClusterRole
+// bound to the "manager" group.
+func newTestScopeTemplate(name string) *operatorsv1.ScopeTemplate {
+	return &operatorsv1.ScopeTemplate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			UID:  types.UID(name + "-uid"),
+		},
+		Spec: operatorsv1.ScopeTemplateSpec{
+			ClusterRoles: []operatorsv1.ClusterRoleTemplate{
+				{
+					GenerateName: "test",
+					Rules: []rbacv1.PolicyRule{
+						{
+							APIGroups: []string{""},
+							Verbs:     []string{"get", "watch", "list"},
+							Resources: []string{"secrets"},
+						},
+					},
+					Subjects: []rbacv1.Subject{
+						{
+							Kind:     "Group",
+							APIGroup: "rbac.authorization.k8s.io",
+							Name:     "manager",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// newTestClusterRole returns a ClusterRole as it would be created by the
+// ScopeTemplate controller for newTestScopeTemplate.
+func newTestClusterRole(name string) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{""},
+				Verbs:     []string{"get", "watch", "list"},
+				Resources: []string{"secrets"},
+			},
+		},
+	}
+}
+
+func newTestServiceAccount(namespace, name string, labels map[string]string) *corev1.ServiceAccount {
+	return &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+	}
+}
+
+// listFakeRoleBindings returns the RoleBindings owned by the ScopeInstance
+// in the given namespace without waiting for them to appear.
+func listFakeRoleBindings(c client.Client, namespace string, si *operatorsv1.ScopeInstance) []rbacv1.RoleBinding {
+	roleBindingList := &rbacv1.RoleBindingList{}
+	Expect(c.List(ctx, roleBindingList, client.InNamespace(namespace), client.MatchingLabels{
+		scopeInstanceUIDKey: string(si.GetUID()),
+	})).To(Succeed())
+	return roleBindingList.Items
+}
+
+// listFakeClusterRoleBindings returns the ClusterRoleBindings owned by the
+// ScopeInstance without waiting for them to appear.
+func listFakeClusterRoleBindings(c client.Client, si *operatorsv1.ScopeInstance) []rbacv1.ClusterRoleBinding {
+	clusterRoleBindingList := &rbacv1.ClusterRoleBindingList{}
+	Expect(c.List(ctx, clusterRoleBindingList, client.MatchingLabels{
+		scopeInstanceUIDKey: string(si.GetUID()),
+	})).To(Succeed())
+	return clusterRoleBindingList.Items
+}
+
+// expectIdempotentReconcile reconciles the named ScopeInstance and asserts
+// that it converges without writing any binding.
+func expectIdempotentReconcile(r *ScopeInstanceReconciler, name string) {
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: name}})
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	expectConverged(r, name)
+}
+
+// expectConverged reconciles the named ScopeInstance and asserts that the
+// reconcile writes nothing but the status flipping Progressing to False, and
+// that the next reconcile does not write anything.
+func expectConverged(r *ScopeInstanceReconciler, name string) {
+	c := &writeCountingClient{Client: r.Client}
+	r.Client = c
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: name}})
+	r.Client = c.Client
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	ExpectWithOffset(1, c.writes).To(Or(BeEmpty(), ConsistOf("patch status *v1alpha1.ScopeInstance /"+name)),
+		"a converging reconcile should only write the status")
+
+	in := &operatorsv1.ScopeInstance{}
+	ExpectWithOffset(1, r.Client.Get(ctx, types.NamespacedName{Name: name}, in)).To(Succeed())
+	ExpectWithOffset(1, meta.IsStatusConditionFalse(in.Status.Conditions, operatorsv1.TypeProgressing)).To(BeTrue(),
+		"a converged ScopeInstance should not be Progressing")
+	expectNoWrites(r, name)
+}
+
+// expectNoWrites reconciles the named ScopeInstance and asserts that the
+// reconcile does not write anything.
+func expectNoWrites(r *ScopeInstanceReconciler, name string) {
+	c := &writeCountingClient{Client: r.Client}
+	r.Client = c
+	defer func() { r.Client = c.Client }()
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: name}})
+	ExpectWithOffset(2, err).NotTo(HaveOccurred())
+	ExpectWithOffset(2, c.writes).To(BeEmpty(), "a steady state reconcile should not write anything")
+}
+
+// deleteOptionsRecorder records the options of the Delete calls made
+// through the client.
+type deleteOptionsRecorder struct {
+	client.Client
+	deletes []client.DeleteOptions
+}
+
+func (c *deleteOptionsRecorder) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.deletes = append(c.deletes, *(&client.DeleteOptions{}).ApplyOptions(opts))
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+// listKindRecorder records the types of the lists made through the client.
+type listKindRecorder struct {
+	client.Client
+	lists []string
+}
+
+func (c *listKindRecorder) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	c.lists = append(c.lists, fmt.Sprintf("%T", list))
+	return c.Client.List(ctx, list, opts...)
+}
+
+// laggingCacheClient keeps listing the bindings deleted through it, like a
+// cache that has not observed their deletion yet.
+type laggingCacheClient struct {
+	client.Client
+	deleted []client.Object
+}
+
+func (c *laggingCacheClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if err := c.Client.Delete(ctx, obj, opts...); err != nil {
+		return err
+	}
+	c.deleted = append(c.deleted, obj.DeepCopyObject().(client.Object))
+	return nil
+}
+
+func (c *laggingCacheClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if err := c.Client.List(ctx, list, opts...); err != nil {
+		return err
+	}
+	listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+	for _, obj := range c.deleted {
+		if listOpts.Namespace != "" && obj.GetNamespace() != listOpts.Namespace ||
+			listOpts.LabelSelector != nil && !listOpts.LabelSelector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		switch l := list.(type) {
+		case *rbacv1.RoleBindingList:
+			if rb, ok := obj.(*rbacv1.RoleBinding); ok {
+				l.Items = append(l.Items, *rb)
+			}
+		case *rbacv1.ClusterRoleBindingList:
+			if crb, ok := obj.(*rbacv1.ClusterRoleBinding); ok {
+				l.Items = append(l.Items, *crb)
+			}
+		}
+	}
+	return nil
+}
+
+// observe catches the cache up, delivering the delete events of the deleted
+// bindings to the reconciler.
+func (c *laggingCacheClient) observe(r *ScopeInstanceReconciler) {
+	for _, obj := range c.deleted {
+		r.notSelfDeleted(event.DeleteEvent{Object: obj})
+	}
+	c.deleted = nil
+}
+
+// writeCountingClient records every write made through the client. Bindings
+// may be written concurrently, so the record is guarded by mu.
+type writeCountingClient struct {
+	client.Client
+	mu     sync.Mutex
+	writes []string
+}
+
+func (c *writeCountingClient) record(verb string, obj client.Object) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writes = append(c.writes, fmt.Sprintf("%s %T %s", verb, obj, client.ObjectKeyFromObject(obj)))
+}
+
+func (c *writeCountingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.record("create", obj)
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *writeCountingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.record("update", obj)
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *writeCountingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.record("patch", obj)
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *writeCountingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.record("delete", obj)
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *writeCountingClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	c.record("deleteAllOf", obj)
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (c *writeCountingClient) Status() client.StatusWriter {
+	return &writeCountingStatusWriter{StatusWriter: c.Client.Status(), client: c}
+}
+
+type writeCountingStatusWriter struct {
+	client.StatusWriter
+	client *writeCountingClient
+}
+
+func (w *writeCountingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	w.client.record("update status", obj)
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func (w *writeCountingStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	w.client.record("patch status", obj)
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
+
+// dryRunClient records the options of the creates it receives. If set,
+// mutate runs on the objects created with a server-side dry run, as a
+// mutating admission webhook would.
+type dryRunClient struct {
+	client.Client
+	creates []*client.CreateOptions
+	mutate  func(client.Object)
+}
+
+func (c *dryRunClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	createOpts := (&client.CreateOptions{}).ApplyOptions(opts)
+	c.creates = append(c.creates, createOpts)
+	if err := c.Client.Create(ctx, obj, opts...); err != nil {
+		return err
+	}
+	if c.mutate != nil && len(createOpts.DryRun) > 0 {
+		c.mutate(obj)
+	}
+	return nil
+}
+
+// conflictingStatusClient returns a conflict error for the first status
+// patches it receives, simulating concurrent writers. If set, race runs once
+// before the first status patch, as a write landing in between.
+type conflictingStatusClient struct {
+	client.Client
+	conflicts int
+	patches   int
+	race      func()
+}
+
+func (c *conflictingStatusClient) Status() client.StatusWriter {
+	return &conflictingStatusWriter{StatusWriter: c.Client.Status(), client: c}
+}
+
+type conflictingStatusWriter struct {
+	client.StatusWriter
+	client *conflictingStatusClient
+}
+
+func (w *conflictingStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	w.client.patches++
+	if race := w.client.race; race != nil {
+		w.client.race = nil
+		race()
+	}
+	if w.client.conflicts > 0 {
+		w.client.conflicts--
+		return k8sapierrors.NewConflict(schema.GroupResource{Resource: "scopeinstances"}, obj.GetName(), fmt.Errorf("injected conflict"))
+	}
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
+
+// failingCreateClient fails to create any object in namespace.
+type failingCreateClient struct {
+	client.Client
+	namespace string
+	// err is returned instead of a generic error if set
+	err error
+}
+
+func (c *failingCreateClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if c.namespace != "" && obj.GetNamespace() == c.namespace {
+		if c.err != nil {
+			return c.err
+		}
+		return fmt.Errorf("creating %s in %s: injected failure", obj.GetGenerateName(), c.namespace)
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+// failingListClient fails to list ScopeTemplates.
+type failingListClient struct {
+	client.Client
+}
+
+func (c *failingListClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if _, ok := list.(*operatorsv1.ScopeTemplateList); ok {
+		return fmt.Errorf("listing ScopeTemplates: injected failure")
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+// concurrentCreateClient holds the first RoleBinding creates until arrivals
+// of them are in flight at once, recording the most in flight at any time, and fails
+// the creates in the failing namespaces.
+type concurrentCreateClient struct {
+	client.Client
+	arrivals int
+	failing  sets.String
+	released chan struct{}
+
+	mu          sync.Mutex
+	arrived     int
+	inFlight    int
+	maxInFlight int
+}
+
+func (c *concurrentCreateClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if _, ok := obj.(*rbacv1.RoleBinding); !ok {
+		return c.Client.Create(ctx, obj, opts...)
+	}
+
+	c.mu.Lock()
+	c.arrived++
+	c.inFlight++
+	if c.inFlight > c.maxInFlight {
+		c.maxInFlight = c.inFlight
+	}
+	if c.arrived == c.arrivals {
+		close(c.released)
+	}
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.inFlight--
+		c.mu.Unlock()
+	}()
+
+	select {
+	case <-c.released:
+	case <-time.After(time.Second):
+		return fmt.Errorf("creating %s in %s: fewer than %d creates in flight", obj.GetGenerateName(), obj.GetNamespace(), c.arrivals)
+	}
+	if c.failing.Has(obj.GetNamespace()) {
+		return fmt.Errorf("creating %s in %s: injected failure", obj.GetGenerateName(), obj.GetNamespace())
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+// objectRecorder records the events of every object, along with the object.
+type objectRecorder struct {
+	mu     sync.Mutex
+	events []recordedEvent
+}
+
+type recordedEvent struct {
+	kind, name, namespace string
+	uid                   types.UID
+	reason, message       string
+}
+
+var _ record.EventRecorder = &objectRecorder{}
+
+func (r *objectRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	obj := object.(client.Object)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, recordedEvent{
+		kind:      obj.GetObjectKind().GroupVersionKind().Kind,
+		name:      obj.GetName(),
+		namespace: obj.GetNamespace(),
+		uid:       obj.GetUID(),
+		reason:    reason,
+		message:   message,
+	})
+}
+
+func (r *objectRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *objectRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Eventf(object, eventtype, reason, messageFmt, args...)
+}
+
+// bindingSwapClient records the writes made to RoleBindings, and the
+// RoleBindings in the namespace whenever a RoleBinding is deleted.
+type bindingSwapClient struct {
+	client.Client
+	ops         []string
+	deletedWith []string
+}
+
+func (c *bindingSwapClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if _, ok := obj.(*rbacv1.RoleBinding); ok {
+		c.ops = append(c.ops, "create")
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *bindingSwapClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if _, ok := obj.(*rbacv1.RoleBinding); ok {
+		c.ops = append(c.ops, "delete")
+		roleBindingList := &rbacv1.RoleBindingList{}
+		if err := c.Client.List(ctx, roleBindingList, client.InNamespace(obj.GetNamespace())); err != nil {
+			return err
+		}
+		c.deletedWith = nil
+		for _, rb := range roleBindingList.Items {
+			c.deletedWith = append(c.deletedWith, rb.GetName())
+		}
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+// fieldManagerClient records the field manager of every write made through
+// the client.
+type fieldManagerClient struct {
+	client.Client
+	fieldManagers []string
+}
+
+func (c *fieldManagerClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.fieldManagers = append(c.fieldManagers, (&client.CreateOptions{}).ApplyOptions(opts).FieldManager)
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *fieldManagerClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.fieldManagers = append(c.fieldManagers, (&client.UpdateOptions{}).ApplyOptions(opts).FieldManager)
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *fieldManagerClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.fieldManagers = append(c.fieldManagers, (&client.PatchOptions{}).ApplyOptions(opts).FieldManager)
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+// newFakeClient returns a fake client seeded with the given objects. The fake
+// client does not support server-side apply, so apply patches are sent as
+// merge patches instead.
+func newFakeClient(objs ...client.Object) client.Client {
+	return &mergeApplyClient{
+		Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build(),
+	}
+}
+
+type mergeApplyClient struct {
+	client.Client
+}
+
+func (c *mergeApplyClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	}
+
+	data, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	return c.Client.Patch(ctx, obj, client.RawPatch(types.MergePatchType, data))
+}
@@ -18,8 +18,9 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"reflect"
+	"sort"
 
 	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
 	"operator-framework/oria-operator/util"
@@ -33,8 +34,8 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/selection"
-	"k8s.io/apimachinery/pkg/types"
 	apimacherrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -47,18 +48,41 @@ import (
 type ScopeTemplateReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// FieldManager is the field manager used for every write, defaulting to
+	// DefaultFieldManager.
+	FieldManager string
+
+	// KeyPrefix is the prefix of the labels and annotations the bookkeeping
+	// of the ClusterRoles is kept in, defaulting to DefaultKeyPrefix. It must
+	// match the KeyPrefix of the ScopeInstanceReconciler.
+	KeyPrefix string
+
+	// DefaultScopeTemplate is the name of the ScopeTemplate used by
+	// ScopeInstances that reference none.
+	DefaultScopeTemplate string
+
+	// RulePolicy lists the rules the ClusterRoles of a ScopeTemplate may not
+	// grant. No ClusterRole is created for a ScopeTemplate violating it.
+	RulePolicy RulePolicy
+
+	// keys maps the bookkeeping keys under KeyPrefix
+	keys *keyMapping
 }
 
 const (
 	// scopeTemplateUIDKey is used to track "owners" of (Cluster)Roles we create.
 	scopeTemplateUIDKey = "operators.coreos.io/scopeTemplateUID"
 
-	// scopeTemplateHashKey is used to track "abandoned" (Cluster)Roles we created.
+	// scopeTemplateHashKey is an annotation used to track "abandoned" (Cluster)Roles we created.
 	scopeTemplateHashKey = "operators.coreos.io/scopeTemplateHash"
 
+	// rulesHashKey is an annotation holding the hash of the rules a ClusterRole was created with,
+	// so that rules edited by others can be told apart from a changed ScopeTemplate.
+	rulesHashKey = "operators.coreos.io/rulesHash"
+
 	// generateNames are used to track each binding we create for a single scopeTemplate
 	clusterRoleGenerateKey = "operators.coreos.io/generateName"
-	stCtrlFieldOwner       = "scopetemplate-controller"
 )
 
 //+kubebuilder:rbac:groups=operators.io.operator-framework,resources=scopetemplates,verbs=get;list;watch;create;update;patch;delete
@@ -96,19 +120,31 @@ func (r *ScopeTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	// object update to ensure that the status update can be processed before
 	// a potential deletion.
 	if !equality.Semantic.DeepEqual(existingSt.Status, reconciledSt.Status) {
-		if updateErr := r.Client.Status().Update(ctx, reconciledSt); updateErr != nil {
+		if updateErr := r.Client.Status().Update(ctx, reconciledSt, r.fieldOwner()); updateErr != nil {
 			return res, apimacherrors.NewAggregate([]error{reconcileErr, updateErr})
 		}
+		// The status update bumps the resourceVersion, which should not be
+		// mistaken for a change to the main object.
+		existingSt.SetResourceVersion(reconciledSt.GetResourceVersion())
 	}
-	existingSt.Status, reconciledSt.Status = operatorsv1.ScopeTemplateStatus{}, operatorsv1.ScopeTemplateStatus{}
-	if !equality.Semantic.DeepEqual(existingSt, reconciledSt) {
-		if updateErr := r.Client.Update(ctx, reconciledSt); updateErr != nil {
+	// Compare everything but the status, which was written above.
+	if !equality.Semantic.DeepEqual(existingSt.ObjectMeta, reconciledSt.ObjectMeta) ||
+		!equality.Semantic.DeepEqual(existingSt.Spec, reconciledSt.Spec) {
+		if updateErr := r.Client.Update(ctx, reconciledSt, r.fieldOwner()); updateErr != nil {
 			return res, apimacherrors.NewAggregate([]error{reconcileErr, updateErr})
 		}
 	}
 	return res, reconcileErr
 }
 
+// fieldOwner returns the field manager used for writes.
+func (r *ScopeTemplateReconciler) fieldOwner() client.FieldOwner {
+	if r.FieldManager == "" {
+		return client.FieldOwner(DefaultFieldManager)
+	}
+	return client.FieldOwner(r.FieldManager)
+}
+
 func (r *ScopeTemplateReconciler) reconcile(ctx context.Context, st *operatorsv1.ScopeTemplate) (ctrl.Result, error) {
 	scopeinstances := operatorsv1.ScopeInstanceList{}
 	if err := r.Client.List(ctx, &scopeinstances, &client.ListOptions{}); err != nil {
@@ -116,11 +152,26 @@ func (r *ScopeTemplateReconciler) reconcile(ctx context.Context, st *operatorsv1
 		return ctrl.Result{}, err
 	}
 
+	policyErr := r.RulePolicy.check(st)
+
+	var references []operatorsv1.ScopeInstance
 	for _, sInstance := range scopeinstances.Items {
-		if sInstance.Spec.ScopeTemplateName != st.Name {
+		if !referencesScopeTemplate(&sInstance, st, r.DefaultScopeTemplate) {
 			continue
 		}
-		// create ClusterRoles based on the ScopeTemplate
+		references = append(references, sInstance)
+	}
+
+	// The ClusterRoles of a previous ScopeTemplate of the same name would
+	// keep the ones of this ScopeTemplate from being created
+	if err := r.deleteStaleClusterRoles(ctx, st); err != nil {
+		updateStatusTemplatingFailed(st, err)
+		return ctrl.Result{}, err
+	}
+
+	// ClusterRoles are only created once a ScopeInstance references the
+	// ScopeTemplate, and deleted below once none does.
+	if len(references) > 0 && policyErr == nil {
 		log.Log.Info("ScopeInstance found that references ScopeTemplate", "name", st.Name)
 		if err := r.ensureClusterRoles(ctx, st); err != nil {
 			updateStatusTemplatingFailed(st, err)
@@ -128,41 +179,85 @@ func (r *ScopeTemplateReconciler) reconcile(ctx context.Context, st *operatorsv1
 		}
 	}
 
-	// Add requirement to delete old (Cluster)Roles
-	stHashReq, err := labels.NewRequirement(scopeTemplateHashKey, selection.NotEquals, []string{util.HashObject(st.Spec)})
-	if err != nil {
+	if err := r.updateReferences(ctx, st, references); err != nil {
 		updateStatusTemplatingFailed(st, err)
 		return ctrl.Result{}, err
 	}
 
-	// Only look for old (Cluster)Roles that map to this ScopeTemplate UID
-	stUIDReq, err := labels.NewRequirement(scopeTemplateUIDKey, selection.Equals, []string{string(st.GetUID())})
-	if err != nil {
-		updateStatusTemplatingFailed(st, err)
-		return ctrl.Result{}, err
+	// Delete old (Cluster)Roles whose hash no longer matches the ScopeTemplate,
+	// or every (Cluster)Role once no ScopeInstance references the ScopeTemplate
+	stHash := util.HashObject(st.Spec)
+	isOutOfDate := func(cr *rbacv1.ClusterRole) bool {
+		return len(references) == 0 || cr.GetAnnotations()[r.keys.key(scopeTemplateHashKey)] != stHash
 	}
 
-	listOptions := &client.ListOptions{
-		LabelSelector: labels.NewSelector().Add(*stHashReq, *stUIDReq),
+	// Only look for old (Cluster)Roles that map to this ScopeTemplate UID
+	listOptions := client.MatchingLabels{
+		r.keys.key(scopeTemplateUIDKey): string(st.GetUID()),
 	}
 
-	if err := r.deleteClusterRoles(ctx, listOptions); err != nil {
+	if err := r.deleteClusterRoles(ctx, isOutOfDate, listOptions); err != nil {
 		updateStatusTemplatingFailed(st, err)
 		return ctrl.Result{}, err
 	}
 
+	if policyErr != nil {
+		log.Log.Info("ScopeTemplate violates the rule policy", "name", st.Name, "error", policyErr.Error())
+		updateStatusTemplatePolicyViolation(st, policyErr)
+		return ctrl.Result{}, nil
+	}
+
 	updateStatusTemplatingSuccessful(st, fmt.Sprintf("ScopeTemplate %q successfully reconciled", st.Name))
 	log.Log.Info("No ScopeTemplate error")
 	return ctrl.Result{}, nil
 }
 
+// updateReferences records the ScopeInstances referencing the ScopeTemplate
+// and the number of bindings created for them in its status.
+func (r *ScopeTemplateReconciler) updateReferences(ctx context.Context, st *operatorsv1.ScopeTemplate, references []operatorsv1.ScopeInstance) error {
+	var names []string
+	bindingCount := 0
+	for _, in := range references {
+		names = append(names, in.GetName())
+
+		listOption := client.MatchingLabels{
+			r.keys.key(scopeInstanceUIDKey): string(in.GetUID()),
+		}
+		crbList := &rbacv1.ClusterRoleBindingList{}
+		if err := r.Client.List(ctx, crbList, listOption); err != nil {
+			return err
+		}
+		rbList := &rbacv1.RoleBindingList{}
+		if err := r.Client.List(ctx, rbList, listOption); err != nil {
+			return err
+		}
+		bindingCount += len(crbList.Items) + len(rbList.Items)
+	}
+	sort.Strings(names)
+
+	st.Status.ScopeInstances = names
+	st.Status.BindingCount = bindingCount
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *ScopeTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.keys == nil {
+		keys, err := newKeyMapping(r.KeyPrefix)
+		if err != nil {
+			return err
+		}
+		r.keys = keys
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&operatorsv1.ScopeTemplate{}).
 		// Set up a watch for ScopeInstance to handle requeuing of requests for ScopeTemplate
 		Watches(&source.Kind{Type: &operatorsv1.ScopeInstance{}}, handler.EnqueueRequestsFromMapFunc(r.mapToScopeTemplate)).
 		Owns(&rbacv1.ClusterRole{}).
+		// Set up watches for bindings to keep the binding count in the status up to date
+		Watches(&source.Kind{Type: &rbacv1.ClusterRoleBinding{}}, handler.EnqueueRequestsFromMapFunc(r.mapBindingToScopeTemplate)).
+		Watches(&source.Kind{Type: &rbacv1.RoleBinding{}}, handler.EnqueueRequestsFromMapFunc(r.mapBindingToScopeTemplate)).
 		Complete(r)
 }
 
@@ -176,28 +271,70 @@ func (r *ScopeTemplateReconciler) mapToScopeTemplate(obj client.Object) (request
 		return nil
 	}
 
+	// enqueue the ScopeTemplate pinned by UID, if any
+	st, err := scopeTemplateByUID(context.TODO(), r.Client, scopeInstance)
+	if err != nil {
+		log.Log.Error(err, "error listing scopetemplates")
+	} else if st != nil {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(st)})
+	}
+
 	// Exit early if scopeInstance doesn't reference a scopeTemplate
-	if scopeInstance.Spec.ScopeTemplateName == "" {
-		return nil
+	key := scopeTemplateKey(scopeInstance, r.DefaultScopeTemplate)
+	if key.Name == "" {
+		return requests
 	}
 
 	// enqueue requests for ScopeTemplate based on Name and Namespace
 	request := reconcile.Request{
-		NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: scopeInstance.Spec.ScopeTemplateName},
+		NamespacedName: key,
 	}
 	requests = append(requests, request)
 
 	return requests
 }
 
+// mapBindingToScopeTemplate enqueues the ScopeTemplate referenced by the
+// ScopeInstance that created the binding.
+func (r *ScopeTemplateReconciler) mapBindingToScopeTemplate(obj client.Object) (requests []reconcile.Request) {
+	if obj == nil {
+		return nil
+	}
+
+	uid, ok := obj.GetLabels()[r.keys.key(scopeInstanceUIDKey)]
+	if !ok {
+		return nil
+	}
+
+	ctx := context.TODO()
+	scopeInstanceList := &operatorsv1.ScopeInstanceList{}
+
+	if err := r.Client.List(ctx, scopeInstanceList); err != nil {
+		log.Log.Error(err, "error listing scopeinstances")
+		return nil
+	}
+
+	for _, si := range scopeInstanceList.Items {
+		if string(si.GetUID()) != uid {
+			continue
+		}
+		requests = append(requests, r.mapToScopeTemplate(&si)...)
+	}
+
+	return
+}
+
 func (r *ScopeTemplateReconciler) ensureClusterRoles(ctx context.Context, st *operatorsv1.ScopeTemplate) error {
 	for _, cr := range st.Spec.ClusterRoles {
-		clusterRole := r.clusterRoleManifest(&cr, st)
+		clusterRole, err := r.clusterRoleManifest(&cr, st)
+		if err != nil {
+			return err
+		}
 
 		crList := &rbacv1.ClusterRoleList{}
 		if err := r.Client.List(ctx, crList, client.MatchingLabels{
-			scopeTemplateUIDKey:    string(st.GetUID()),
-			clusterRoleGenerateKey: cr.GenerateName,
+			r.keys.key(scopeTemplateUIDKey):    string(st.GetUID()),
+			r.keys.key(clusterRoleGenerateKey): shortGenerateName(cr.GenerateName),
 		}); err != nil {
 			return err
 		}
@@ -209,7 +346,7 @@ func (r *ScopeTemplateReconciler) ensureClusterRoles(ctx context.Context, st *op
 
 		// Create the ClusterRole if it does not exist
 		if len(crList.Items) == 0 {
-			if err := r.Client.Create(ctx, clusterRole); err != nil {
+			if err := r.Client.Create(ctx, clusterRole, r.fieldOwner()); err != nil {
 				return err
 			}
 			continue
@@ -217,20 +354,41 @@ func (r *ScopeTemplateReconciler) ensureClusterRoles(ctx context.Context, st *op
 
 		existingCR := &crList.Items[0]
 
-		if util.IsOwnedByLabel(existingCR.DeepCopy(), st) &&
-			reflect.DeepEqual(existingCR.Rules, clusterRole.Rules) &&
-			reflect.DeepEqual(existingCR.Labels, clusterRole.Labels) {
+		// The hash of the ScopeTemplate was kept in a label of the same key
+		// before it moved to an annotation, the apply patch leaves it in place
+		if _, err := removeLabel(ctx, r.Client, existingCR, r.keys.key(scopeTemplateHashKey)); err != nil {
+			return err
+		}
+
+		// Labels added by others, e.g. for aggregation, are left in place.
+		// The owner reference is restored if missing, so that the ClusterRole
+		// is garbage collected along with the ScopeTemplate.
+		rulesHash := hashRules(existingCR.Rules)
+		if util.IsOwnedByLabel(existingCR.DeepCopy(), st, r.keys.key(scopeInstanceUIDKey)) &&
+			metav1.IsControlledBy(existingCR, st) &&
+			rulesHash == clusterRole.Annotations[r.keys.key(rulesHashKey)] &&
+			labels.SelectorFromSet(clusterRole.Labels).Matches(labels.Set(existingCR.Labels)) &&
+			existingCR.Annotations[r.keys.key(scopeTemplateHashKey)] == clusterRole.Annotations[r.keys.key(scopeTemplateHashKey)] &&
+			existingCR.Annotations[r.keys.key(rulesHashKey)] == clusterRole.Annotations[r.keys.key(rulesHashKey)] {
 			log.Log.V(2).Info("existing ClusterRole does not need to be updated", "UID", existingCR.GetUID())
-			return nil
+			continue
 		}
 
+		// The rules no longer match the hash they were written with, someone
+		// else edited them. They are restored below.
+		if existing := existingCR.Annotations[r.keys.key(rulesHashKey)]; existing != "" && existing != rulesHash {
+			log.Log.Info("restoring the edited rules of ClusterRole", "name", existingCR.GetName(), "scopeTemplate", st.GetName())
+		}
+
+		// Update the rules of the existing ClusterRole in place, its name is
+		// referenced by the bindings of every ScopeInstance.
 		patchObj := r.clusterRolePatchObj(existingCR, clusterRole)
 
 		// server-side apply patch
 		if err := r.Client.Patch(ctx,
 			patchObj,
 			client.Apply,
-			client.FieldOwner(stCtrlFieldOwner),
+			r.fieldOwner(),
 			client.ForceOwnership); err != nil {
 			return err
 		}
@@ -247,13 +405,16 @@ func (r *ScopeTemplateReconciler) clusterRolePatchObj(oldCr *rbacv1.ClusterRole,
 				"name":            oldCr.Name,
 				"ownerReferences": cr.OwnerReferences,
 				"labels":          cr.Labels,
+				"annotations":     cr.Annotations,
 			},
 			"rules": cr.Rules,
 		},
 	}
 }
 
-func (r *ScopeTemplateReconciler) deleteClusterRoles(ctx context.Context, listOptions ...client.ListOption) error {
+// deleteClusterRoles will delete the ClusterRoles matching the list options
+// for which shouldDelete returns true.
+func (r *ScopeTemplateReconciler) deleteClusterRoles(ctx context.Context, shouldDelete func(cr *rbacv1.ClusterRole) bool, listOptions ...client.ListOption) error {
 	clusterRoles := &rbacv1.ClusterRoleList{}
 	if err := r.Client.List(ctx, clusterRoles, listOptions...); err != nil {
 		// TODO: Aggregate errors
@@ -261,6 +422,9 @@ func (r *ScopeTemplateReconciler) deleteClusterRoles(ctx context.Context, listOp
 	}
 
 	for _, crb := range clusterRoles.Items {
+		if !shouldDelete(&crb) {
+			continue
+		}
 		// TODO: Aggregate errors
 		if err := r.Client.Delete(ctx, &crb); err != nil && !k8sapierrors.IsNotFound(err) {
 			return err
@@ -269,24 +433,99 @@ func (r *ScopeTemplateReconciler) deleteClusterRoles(ctx context.Context, listOp
 	return nil
 }
 
-func (r *ScopeTemplateReconciler) clusterRoleManifest(crt *operatorsv1.ClusterRoleTemplate, st *operatorsv1.ScopeTemplate) *rbacv1.ClusterRole {
+// deleteStaleClusterRoles deletes the ClusterRoles left behind by a deleted
+// ScopeTemplate that the ScopeTemplate replaced, such as when it is recreated
+// with the same name. They are labeled with a scopeTemplateUIDKey that no
+// ScopeTemplate has anymore, and are either controlled by a ScopeTemplate of
+// the same name or, having lost their owner reference, have the name of one
+// of the ClusterRoles of the ScopeTemplate. The garbage collector would
+// delete the former eventually, but not the latter.
+func (r *ScopeTemplateReconciler) deleteStaleClusterRoles(ctx context.Context, st *operatorsv1.ScopeTemplate) error {
+	hasOtherUID, err := labels.NewRequirement(r.keys.key(scopeTemplateUIDKey), selection.NotEquals, []string{string(st.GetUID())})
+	if err != nil {
+		return err
+	}
+	labeled, err := labels.NewRequirement(r.keys.key(scopeTemplateUIDKey), selection.Exists, nil)
+	if err != nil {
+		return err
+	}
+	clusterRoles := &rbacv1.ClusterRoleList{}
+	if err := r.Client.List(ctx, clusterRoles, client.MatchingLabelsSelector{Selector: labels.NewSelector().Add(*labeled, *hasOtherUID)}); err != nil {
+		return err
+	}
+
+	names := sets.NewString()
+	for _, cr := range st.Spec.ClusterRoles {
+		names.Insert(cr.GenerateName)
+	}
+	var candidates []rbacv1.ClusterRole
+	for _, cr := range clusterRoles.Items {
+		owner := metav1.GetControllerOf(&cr)
+		if owner != nil && owner.Kind == "ScopeTemplate" && owner.Name == st.GetName() ||
+			owner == nil && names.Has(cr.GetName()) {
+			candidates = append(candidates, cr)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	scopeTemplates := &operatorsv1.ScopeTemplateList{}
+	if err := r.Client.List(ctx, scopeTemplates); err != nil {
+		return err
+	}
+	uids := sets.NewString()
+	for _, existing := range scopeTemplates.Items {
+		uids.Insert(string(existing.GetUID()))
+	}
+	for _, cr := range candidates {
+		if uids.Has(cr.GetLabels()[r.keys.key(scopeTemplateUIDKey)]) {
+			continue
+		}
+		log.Log.Info("deleting ClusterRole of a previous ScopeTemplate", "name", cr.GetName(), "scopeTemplate", st.GetName(), "previousUID", cr.GetLabels()[r.keys.key(scopeTemplateUIDKey)])
+		if err := r.Client.Delete(ctx, &cr); err != nil && !k8sapierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// hashRules returns the hash of the rules as written to the API server, where
+// empty lists are dropped, so that rules read back from a ClusterRole hash
+// the same as the rules they were created from.
+func hashRules(rules []rbacv1.PolicyRule) string {
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return util.HashObject(rules)
+	}
+	return util.HashObject(string(data))
+}
+
+// clusterRoleManifest returns the ClusterRole for a ClusterRoleTemplate,
+// controlled by the ScopeTemplate so that it is garbage collected along with
+// it. An error is returned if the owner reference cannot be set, e.g. if the
+// ScopeTemplate kind is not registered in the scheme, rather than leaking a
+// ClusterRole without an owner.
+func (r *ScopeTemplateReconciler) clusterRoleManifest(crt *operatorsv1.ClusterRoleTemplate, st *operatorsv1.ScopeTemplate) (*rbacv1.ClusterRole, error) {
 	cr := &rbacv1.ClusterRole{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: crt.GenerateName,
 			Labels: map[string]string{
-				scopeTemplateUIDKey:    string(st.GetUID()),
-				scopeTemplateHashKey:   util.HashObject(st.Spec),
-				clusterRoleGenerateKey: crt.GenerateName,
+				r.keys.key(scopeTemplateUIDKey):    string(st.GetUID()),
+				r.keys.key(clusterRoleGenerateKey): shortGenerateName(crt.GenerateName),
+			},
+			Annotations: map[string]string{
+				r.keys.key(scopeTemplateHashKey): util.HashObject(st.Spec),
+				r.keys.key(rulesHashKey):         hashRules(crt.Rules),
 			},
 		},
 		Rules: crt.Rules,
 	}
 
-	err := ctrl.SetControllerReference(st, cr, r.Scheme)
-	if err != nil {
-		log.Log.Error(err, "setting controller reference for ClusterRoleBinding")
+	if err := ctrl.SetControllerReference(st, cr, r.Scheme); err != nil {
+		return nil, fmt.Errorf("setting controller reference for ClusterRole %s: %w", cr.GetName(), err)
 	}
-	return cr
+	return cr, nil
 }
 
 func updateStatusTemplatingFailed(st *operatorsv1.ScopeTemplate, err error) {
@@ -306,3 +545,12 @@ func updateStatusTemplatingSuccessful(st *operatorsv1.ScopeTemplate, msg string)
 		Message: msg,
 	})
 }
+
+func updateStatusTemplatePolicyViolation(st *operatorsv1.ScopeTemplate, err error) {
+	meta.SetStatusCondition(&st.Status.Conditions, metav1.Condition{
+		Type:    operatorsv1.TypeTemplated,
+		Status:  metav1.ConditionFalse,
+		Reason:  operatorsv1.ReasonPolicyViolation,
+		Message: err.Error(),
+	})
+}
@@ -17,17 +17,26 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+	"operator-framework/oria-operator/util"
 )
 
 const (
@@ -166,6 +175,455 @@ var _ = Describe("ScopeTemplate", func() {
 	})
 })
 
+var _ = Describe("ScopeTemplateReconciler", func() {
+	When("ClusterRoles track the hash of the ScopeTemplate", func() {
+		var (
+			r  *ScopeTemplateReconciler
+			st *operatorsv1.ScopeTemplate
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-cr-hash")
+			r = &ScopeTemplateReconciler{
+				Client: newFakeClient(st, &operatorsv1.ScopeInstance{
+					ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-cr-hash"},
+					Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName()},
+				}),
+				Scheme: scheme.Scheme,
+			}
+		})
+
+		It("should store the hash as an annotation and delete out of date ClusterRoles", func() {
+			stale := &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "stale",
+					Labels: map[string]string{
+						scopeTemplateUIDKey:    string(st.GetUID()),
+						clusterRoleGenerateKey: "stale",
+					},
+					Annotations: map[string]string{
+						scopeTemplateHashKey: "stale-hash",
+					},
+				},
+			}
+			Expect(r.Client.Create(ctx, stale)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			clusterRoles := &rbacv1.ClusterRoleList{}
+			Expect(r.Client.List(ctx, clusterRoles, client.MatchingLabels{scopeTemplateUIDKey: string(st.GetUID())})).To(Succeed())
+			Expect(clusterRoles.Items).To(HaveLen(1))
+			Expect(clusterRoles.Items[0].Name).To(Equal("test"))
+			Expect(clusterRoles.Items[0].Labels).NotTo(HaveKey(scopeTemplateHashKey))
+			Expect(clusterRoles.Items[0].Annotations).To(HaveKeyWithValue(scopeTemplateHashKey, util.HashObject(st.Spec)))
+		})
+
+		It("should remove the hash label of ClusterRoles created before it moved to an annotation", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			cr := &rbacv1.ClusterRole{}
+			Expect(r.Client.Get(ctx, client.ObjectKey{Name: "test"}, cr)).To(Succeed())
+			cr.Labels[scopeTemplateHashKey] = util.HashObject(st.Spec)
+			Expect(r.Client.Update(ctx, cr)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.Client.Get(ctx, client.ObjectKey{Name: "test"}, cr)).To(Succeed())
+			Expect(cr.Labels).NotTo(HaveKey(scopeTemplateHashKey))
+			Expect(cr.Labels).To(HaveKeyWithValue(scopeTemplateUIDKey, string(st.GetUID())))
+		})
+	})
+
+	When("the rules of a ScopeTemplate ClusterRole change", func() {
+		var (
+			r  *ScopeTemplateReconciler
+			c  *deleteCountingClient
+			st *operatorsv1.ScopeTemplate
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-rules")
+			st.Spec.ClusterRoles = append(st.Spec.ClusterRoles, operatorsv1.ClusterRoleTemplate{
+				GenerateName: "other",
+				Rules:        st.Spec.ClusterRoles[0].Rules,
+				Subjects:     st.Spec.ClusterRoles[0].Subjects,
+			})
+			c = &deleteCountingClient{Client: newFakeClient(st, &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-rules"},
+				Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName()},
+			})}
+			r = &ScopeTemplateReconciler{Client: c, Scheme: scheme.Scheme}
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should update the rules of the existing ClusterRole", func() {
+			By("aggregating the ClusterRole into another one")
+			cr := &rbacv1.ClusterRole{}
+			Expect(r.Client.Get(ctx, client.ObjectKey{Name: "test"}, cr)).To(Succeed())
+			cr.Labels["rbac.authorization.k8s.io/aggregate-to-admin"] = "true"
+			cr.AggregationRule = &rbacv1.AggregationRule{
+				ClusterRoleSelectors: []metav1.LabelSelector{{MatchLabels: map[string]string{"example.com/aggregate": "true"}}},
+			}
+			Expect(r.Client.Update(ctx, cr)).To(Succeed())
+
+			By("changing the rules")
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(st), st)).To(Succeed())
+			st.Spec.ClusterRoles[0].Rules = []rbacv1.PolicyRule{{
+				APIGroups: []string{""},
+				Resources: []string{"configmaps"},
+				Verbs:     []string{"get"},
+			}}
+			Expect(r.Client.Update(ctx, st)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(c.deletes).To(BeZero())
+			Expect(r.Client.Get(ctx, client.ObjectKey{Name: "test"}, cr)).To(Succeed())
+			Expect(cr.Rules).To(Equal(st.Spec.ClusterRoles[0].Rules))
+			Expect(cr.Labels).To(HaveKeyWithValue("rbac.authorization.k8s.io/aggregate-to-admin", "true"))
+			Expect(cr.AggregationRule).NotTo(BeNil())
+			Expect(cr.Annotations).To(HaveKeyWithValue(scopeTemplateHashKey, util.HashObject(st.Spec)))
+		})
+
+		It("should recreate a missing ClusterRole after an up to date one", func() {
+			Expect(r.Client.Delete(ctx, &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "other"}})).To(Succeed())
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.Client.Get(ctx, client.ObjectKey{Name: "other"}, &rbacv1.ClusterRole{})).To(Succeed())
+		})
+	})
+
+	When("the rules of a ScopeTemplate ClusterRole are edited", func() {
+		var (
+			r  *ScopeTemplateReconciler
+			c  *writeCountingClient
+			st *operatorsv1.ScopeTemplate
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-edited-rules")
+			st.Spec.ClusterRoles[0].Rules[0].ResourceNames = []string{}
+			c = &writeCountingClient{Client: newFakeClient(st, &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-edited-rules"},
+				Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName()},
+			})}
+			r = &ScopeTemplateReconciler{Client: c, Scheme: scheme.Scheme}
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		// clusterRoleWrites returns the writes of ClusterRoles made since the
+		// last call.
+		clusterRoleWrites := func() []string {
+			var writes []string
+			for _, write := range c.writes {
+				if strings.Contains(write, "ClusterRole ") || strings.Contains(write, "*unstructured.Unstructured") {
+					writes = append(writes, write)
+				}
+			}
+			c.writes = nil
+			return writes
+		}
+
+		It("should track the hash of the rules and leave unedited ClusterRoles alone", func() {
+			cr := &rbacv1.ClusterRole{}
+			Expect(r.Client.Get(ctx, client.ObjectKey{Name: "test"}, cr)).To(Succeed())
+			Expect(cr.Annotations).To(HaveKeyWithValue(rulesHashKey, hashRules(st.Spec.ClusterRoles[0].Rules)))
+			Expect(hashRules(cr.Rules)).To(Equal(hashRules(st.Spec.ClusterRoles[0].Rules)))
+
+			clusterRoleWrites()
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clusterRoleWrites()).To(BeEmpty())
+		})
+
+		It("should restore the rules of the ClusterRole", func() {
+			cr := &rbacv1.ClusterRole{}
+			Expect(r.Client.Get(ctx, client.ObjectKey{Name: "test"}, cr)).To(Succeed())
+			cr.Rules = append(cr.Rules, rbacv1.PolicyRule{
+				APIGroups: []string{"*"},
+				Resources: []string{"*"},
+				Verbs:     []string{"*"},
+			})
+			Expect(r.Client.Update(ctx, cr)).To(Succeed())
+
+			clusterRoleWrites()
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clusterRoleWrites()).To(HaveLen(1))
+
+			Expect(r.Client.Get(ctx, client.ObjectKey{Name: "test"}, cr)).To(Succeed())
+			Expect(cr.Rules).To(HaveLen(1))
+			Expect(cr.Rules[0].Resources).To(Equal(st.Spec.ClusterRoles[0].Rules[0].Resources))
+			Expect(cr.Rules[0].Verbs).To(Equal(st.Spec.ClusterRoles[0].Rules[0].Verbs))
+			Expect(cr.Annotations).To(HaveKeyWithValue(rulesHashKey, hashRules(st.Spec.ClusterRoles[0].Rules)))
+		})
+	})
+
+	When("several ScopeInstances reference the ScopeTemplate", func() {
+		var (
+			r   *ScopeTemplateReconciler
+			sir *ScopeInstanceReconciler
+			st  *operatorsv1.ScopeTemplate
+			sis []*operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-references")
+			sis = []*operatorsv1.ScopeInstance{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-references-b", UID: "scopeinstance-references-b-uid"},
+					Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName(), Namespaces: []string{"ns-1", "ns-2"}},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-references-a", UID: "scopeinstance-references-a-uid"},
+					Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName()},
+				},
+			}
+			c := newFakeClient(st, sis[0], sis[1],
+				&operatorsv1.ScopeInstance{
+					ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-references-other", UID: "scopeinstance-references-other-uid"},
+					Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: "other"},
+				},
+			)
+			r = &ScopeTemplateReconciler{Client: c, Scheme: scheme.Scheme}
+			sir = &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme}
+		})
+
+		It("should list the ScopeInstances and count their bindings in the status", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, si := range sis {
+				_, err := sir.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			By("enqueuing the ScopeTemplate for the bindings of its ScopeInstances")
+			rbs := listFakeRoleBindings(r.Client, "ns-1", sis[0])
+			Expect(rbs).To(HaveLen(1))
+			Expect(r.mapBindingToScopeTemplate(&rbs[0])).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}},
+			))
+
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(st), st)).To(Succeed())
+			Expect(st.Status.ScopeInstances).To(Equal([]string{"scopeinstance-references-a", "scopeinstance-references-b"}))
+			Expect(st.Status.BindingCount).To(Equal(3))
+		})
+	})
+
+	When("a ClusterRole of the ScopeTemplate has lost its owner reference", func() {
+		var (
+			r  *ScopeTemplateReconciler
+			st *operatorsv1.ScopeTemplate
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-owner")
+			r = &ScopeTemplateReconciler{
+				Client: newFakeClient(st, &operatorsv1.ScopeInstance{
+					ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-owner"},
+					Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName()},
+				}),
+				Scheme: scheme.Scheme,
+			}
+		})
+
+		It("should restore the owner reference so that it is garbage collected", func() {
+			orphan, err := r.clusterRoleManifest(&st.Spec.ClusterRoles[0], st)
+			Expect(err).NotTo(HaveOccurred())
+			orphan.OwnerReferences = nil
+			Expect(r.Client.Create(ctx, orphan)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			cr := &rbacv1.ClusterRole{}
+			Expect(r.Client.Get(ctx, client.ObjectKey{Name: "test"}, cr)).To(Succeed())
+			Expect(metav1.GetControllerOf(cr)).To(Equal(&metav1.OwnerReference{
+				APIVersion:         operatorsv1.GroupVersion.String(),
+				Kind:               "ScopeTemplate",
+				Name:               st.GetName(),
+				UID:                st.GetUID(),
+				Controller:         pointer.Bool(true),
+				BlockOwnerDeletion: pointer.Bool(true),
+			}))
+		})
+
+		It("should not create ClusterRoles without an owner if the ScopeTemplate kind is not registered", func() {
+			r.Scheme = runtime.NewScheme()
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).To(MatchError(ContainSubstring("setting controller reference for ClusterRole test")))
+
+			err = r.Client.Get(ctx, client.ObjectKey{Name: "test"}, &rbacv1.ClusterRole{})
+			Expect(k8sapierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	When("a ScopeTemplate is recreated with a new UID", func() {
+		var (
+			r  *ScopeTemplateReconciler
+			st *operatorsv1.ScopeTemplate
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-recreated")
+			r = &ScopeTemplateReconciler{
+				Client: newFakeClient(st, &operatorsv1.ScopeInstance{
+					ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-recreated"},
+					Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName()},
+				}),
+				Scheme: scheme.Scheme,
+			}
+		})
+
+		// recreate replaces the ScopeTemplate with one of the same name and
+		// a new UID, and reconciles it.
+		recreate := func() {
+			Expect(r.Client.Delete(ctx, st)).To(Succeed())
+			st = newTestScopeTemplate(st.GetName())
+			st.UID = "scopetemplate-recreated-uid-2"
+			Expect(r.Client.Create(ctx, st)).To(Succeed())
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		It("should replace the ClusterRoles of the previous ScopeTemplate", func() {
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			recreate()
+			cr := &rbacv1.ClusterRole{}
+			Expect(r.Client.Get(ctx, client.ObjectKey{Name: "test"}, cr)).To(Succeed())
+			Expect(cr.Labels[scopeTemplateUIDKey]).To(Equal("scopetemplate-recreated-uid-2"))
+			Expect(metav1.GetControllerOf(cr).UID).To(Equal(st.GetUID()))
+		})
+
+		It("should replace a ClusterRole of the previous ScopeTemplate that lost its owner reference", func() {
+			orphan, err := r.clusterRoleManifest(&st.Spec.ClusterRoles[0], st)
+			Expect(err).NotTo(HaveOccurred())
+			orphan.OwnerReferences = nil
+			Expect(r.Client.Create(ctx, orphan)).To(Succeed())
+
+			recreate()
+			cr := &rbacv1.ClusterRole{}
+			Expect(r.Client.Get(ctx, client.ObjectKey{Name: "test"}, cr)).To(Succeed())
+			Expect(cr.Labels[scopeTemplateUIDKey]).To(Equal("scopetemplate-recreated-uid-2"))
+		})
+
+		It("should replace the ClusterRoles of the previous ScopeTemplate under a label prefix", func() {
+			keys, err := newKeyMapping("example.com/")
+			Expect(err).NotTo(HaveOccurred())
+			r.keys = keys
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			recreate()
+			cr := &rbacv1.ClusterRole{}
+			Expect(r.Client.Get(ctx, client.ObjectKey{Name: "test"}, cr)).To(Succeed())
+			Expect(cr.Labels["example.com/scopeTemplateUID"]).To(Equal("scopetemplate-recreated-uid-2"))
+			Expect(cr.Labels).NotTo(HaveKey(scopeTemplateUIDKey))
+			Expect(metav1.GetControllerOf(cr).UID).To(Equal(st.GetUID()))
+		})
+
+		It("should replace a ClusterRole that lost its owner reference under a label prefix", func() {
+			keys, err := newKeyMapping("example.com/")
+			Expect(err).NotTo(HaveOccurred())
+			r.keys = keys
+			orphan, err := r.clusterRoleManifest(&st.Spec.ClusterRoles[0], st)
+			Expect(err).NotTo(HaveOccurred())
+			orphan.OwnerReferences = nil
+			Expect(r.Client.Create(ctx, orphan)).To(Succeed())
+
+			recreate()
+			cr := &rbacv1.ClusterRole{}
+			Expect(r.Client.Get(ctx, client.ObjectKey{Name: "test"}, cr)).To(Succeed())
+			Expect(cr.Labels["example.com/scopeTemplateUID"]).To(Equal("scopetemplate-recreated-uid-2"))
+		})
+
+		It("should leave the ClusterRoles of other ScopeTemplates alone", func() {
+			other := newTestScopeTemplate("scopetemplate-other")
+			Expect(r.Client.Create(ctx, other)).To(Succeed())
+			orphan, err := r.clusterRoleManifest(&other.Spec.ClusterRoles[0], other)
+			Expect(err).NotTo(HaveOccurred())
+			orphan.OwnerReferences = nil
+			Expect(r.Client.Create(ctx, orphan)).To(Succeed())
+
+			_, err = r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).To(HaveOccurred())
+			cr := &rbacv1.ClusterRole{}
+			Expect(r.Client.Get(ctx, client.ObjectKey{Name: "test"}, cr)).To(Succeed())
+			Expect(cr.Labels[scopeTemplateUIDKey]).To(Equal(string(other.GetUID())))
+		})
+	})
+
+	When("a ScopeTemplate is not referenced yet", func() {
+		It("should only create its ClusterRoles while a ScopeInstance references it", func() {
+			st := newTestScopeTemplate("scopetemplate-lazy")
+			r := &ScopeTemplateReconciler{Client: newFakeClient(st), Scheme: scheme.Scheme}
+			reconcileScopeTemplate := func() {
+				_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			reconcileScopeTemplate()
+			err := r.Client.Get(ctx, client.ObjectKey{Name: "test"}, &rbacv1.ClusterRole{})
+			Expect(k8sapierrors.IsNotFound(err)).To(BeTrue())
+
+			By("referencing the ScopeTemplate")
+			si := &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-lazy"},
+				Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName()},
+			}
+			Expect(r.Client.Create(ctx, si)).To(Succeed())
+			reconcileScopeTemplate()
+			Expect(r.Client.Get(ctx, client.ObjectKey{Name: "test"}, &rbacv1.ClusterRole{})).To(Succeed())
+
+			By("deleting the last reference")
+			Expect(r.Client.Delete(ctx, si)).To(Succeed())
+			reconcileScopeTemplate()
+			err = r.Client.Get(ctx, client.ObjectKey{Name: "test"}, &rbacv1.ClusterRole{})
+			Expect(k8sapierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	When("no ScopeInstance references the ScopeTemplate anymore", func() {
+		var (
+			r  *ScopeTemplateReconciler
+			st *operatorsv1.ScopeTemplate
+			si *operatorsv1.ScopeInstance
+		)
+		BeforeEach(func() {
+			st = newTestScopeTemplate("scopetemplate-unreferenced")
+			si = &operatorsv1.ScopeInstance{
+				ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-unreferenced"},
+				Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName()},
+			}
+			r = &ScopeTemplateReconciler{Client: newFakeClient(st, si), Scheme: scheme.Scheme}
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(r.Client.Get(ctx, client.ObjectKey{Name: "test"}, &rbacv1.ClusterRole{})).To(Succeed())
+		})
+
+		It("should delete its ClusterRoles", func() {
+			Expect(r.Client.Get(ctx, client.ObjectKeyFromObject(si), si)).To(Succeed())
+			si.Spec.ScopeTemplateName = "nonexistent"
+			Expect(r.Client.Update(ctx, si)).To(Succeed())
+
+			_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: st.GetName()}})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = r.Client.Get(ctx, client.ObjectKey{Name: "test"}, &rbacv1.ClusterRole{})
+			Expect(k8sapierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
+
 func listClusterRole(numberOfExpectedRoleBindings int, labels map[string]string) *rbacv1.ClusterRoleList {
 	clusterRoleList := &rbacv1.ClusterRoleList{}
 	Eventually(func() error {
@@ -184,3 +642,14 @@ func listClusterRole(numberOfExpectedRoleBindings int, labels map[string]string)
 
 	return clusterRoleList
 }
+
+// deleteCountingClient counts the Delete calls made through the client.
+type deleteCountingClient struct {
+	client.Client
+	deletes int
+}
+
+func (c *deleteCountingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.deletes++
+	return c.Client.Delete(ctx, obj, opts...)
+}
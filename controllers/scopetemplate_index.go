@@ -0,0 +1,64 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+// scopeTemplateIndexKey is the field index of the ScopeInstances by the key
+// of the ScopeTemplate they reference, be it through ScopeTemplateName,
+// ScopeTemplateRef or the DefaultScopeTemplate, and by their
+// ScopeTemplateUID.
+const scopeTemplateIndexKey = "spec.scopeTemplateName"
+
+// indexScopeTemplates registers the field index of the ScopeInstances by
+// ScopeTemplate with the indexer, the cache mapToScopeInstance lists from, so
+// that a ScopeTemplate event only lists the ScopeInstances referencing it.
+func (r *ScopeInstanceReconciler) indexScopeTemplates(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &operatorsv1.ScopeInstance{}, scopeTemplateIndexKey, r.scopeTemplateIndexValue); err != nil {
+		return err
+	}
+	r.scopeTemplatesIndexed = true
+	return nil
+}
+
+// scopeTemplateIndexValue returns the key of the ScopeTemplate referenced by
+// the ScopeInstance, and its ScopeTemplateUID if set, under which it is
+// indexed.
+func (r *ScopeInstanceReconciler) scopeTemplateIndexValue(obj client.Object) []string {
+	in, ok := obj.(*operatorsv1.ScopeInstance)
+	if !ok {
+		return nil
+	}
+	values := []string{scopeTemplateKey(in, r.DefaultScopeTemplate).String()}
+	if in.Spec.ScopeTemplateUID != "" {
+		values = append(values, scopeTemplateUIDIndexValue(in.Spec.ScopeTemplateUID))
+	}
+	return values
+}
+
+// scopeTemplateUIDIndexValue returns the index value of a ScopeTemplateUID,
+// which cannot be mistaken for the key of a ScopeTemplate.
+func scopeTemplateUIDIndexValue(uid types.UID) string {
+	return "uid:" + string(uid)
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+var _ = Describe("ScopeTemplate index", func() {
+	var reader *indexedReader
+	BeforeEach(func() {
+		reader = newIndexedReader(4, 2)
+	})
+
+	It("should only list the ScopeInstances referencing the ScopeTemplate", func() {
+		r := &ScopeInstanceReconciler{Reader: reader}
+		Expect(r.indexScopeTemplates(ctx, reader)).To(Succeed())
+
+		Expect(r.mapToScopeInstance(newTestScopeTemplate("scopetemplate-1"))).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Name: "scopeinstance-1"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Name: "scopeinstance-3"}},
+		))
+		Expect(reader.listed).To(Equal(2))
+	})
+
+	It("should index the ScopeInstances without a ScopeTemplate under the DefaultScopeTemplate", func() {
+		reader.items = append(reader.items, operatorsv1.ScopeInstance{ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-default"}})
+		r := &ScopeInstanceReconciler{Reader: reader, DefaultScopeTemplate: "scopetemplate-0"}
+		Expect(r.indexScopeTemplates(ctx, reader)).To(Succeed())
+
+		Expect(r.mapToScopeInstance(newTestScopeTemplate("scopetemplate-0"))).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Name: "scopeinstance-0"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Name: "scopeinstance-2"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Name: "scopeinstance-default"}},
+		))
+	})
+
+	It("should index the ScopeInstances by ScopeTemplateUID", func() {
+		reader.items[0].Spec.ScopeTemplateUID = "scopetemplate-renamed-uid"
+		r := &ScopeInstanceReconciler{Reader: reader}
+		Expect(r.indexScopeTemplates(ctx, reader)).To(Succeed())
+
+		renamed := newTestScopeTemplate("scopetemplate-renamed")
+		Expect(r.mapToScopeInstance(renamed)).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Name: "scopeinstance-0"}},
+		))
+		Expect(reader.listed).To(Equal(1))
+	})
+})
+
+// indexedReader serves the Lists of a fixed set of ScopeInstances through the
+// field indices registered with it, copying each listed ScopeInstance like
+// the cache of the manager does.
+type indexedReader struct {
+	client.Reader
+	items   []operatorsv1.ScopeInstance
+	indices map[string]map[string][]*operatorsv1.ScopeInstance
+	listed  int
+}
+
+// newIndexedReader returns an indexedReader holding the given number of
+// ScopeInstances, spread evenly over the given number of ScopeTemplates.
+func newIndexedReader(instances, templates int) *indexedReader {
+	reader := &indexedReader{indices: map[string]map[string][]*operatorsv1.ScopeInstance{}}
+	for i := 0; i < instances; i++ {
+		reader.items = append(reader.items, operatorsv1.ScopeInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("scopeinstance-%d", i)},
+			Spec: operatorsv1.ScopeInstanceSpec{
+				ScopeTemplateName: fmt.Sprintf("scopetemplate-%d", i%templates),
+				Namespaces:        []string{"ns-1", "ns-2"},
+			},
+		})
+	}
+	return reader
+}
+
+func (r *indexedReader) IndexField(_ context.Context, _ client.Object, field string, extractValue client.IndexerFunc) error {
+	index := map[string][]*operatorsv1.ScopeInstance{}
+	for i := range r.items {
+		for _, value := range extractValue(&r.items[i]) {
+			index[value] = append(index[value], &r.items[i])
+		}
+	}
+	r.indices[field] = index
+	return nil
+}
+
+func (r *indexedReader) List(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+	var items []*operatorsv1.ScopeInstance
+	for i := range r.items {
+		items = append(items, &r.items[i])
+	}
+	if listOpts.FieldSelector != nil {
+		for field, index := range r.indices {
+			if value, ok := listOpts.FieldSelector.RequiresExactMatch(field); ok {
+				items = index[value]
+			}
+		}
+	}
+
+	siList := list.(*operatorsv1.ScopeInstanceList)
+	siList.Items = nil
+	for _, item := range items {
+		siList.Items = append(siList.Items, *item.DeepCopy())
+	}
+	r.listed += len(items)
+	return nil
+}
+
+func BenchmarkMapToScopeInstanceWithoutIndex(b *testing.B) {
+	benchmarkMapToScopeInstance(b, false)
+}
+
+func BenchmarkMapToScopeInstanceWithIndex(b *testing.B) {
+	benchmarkMapToScopeInstance(b, true)
+}
+
+// benchmarkMapToScopeInstance measures mapping an event of each of 100
+// ScopeTemplates referenced by 1000 ScopeInstances.
+func benchmarkMapToScopeInstance(b *testing.B, indexed bool) {
+	reader := newIndexedReader(1000, 100)
+	r := &ScopeInstanceReconciler{Reader: reader}
+	if indexed {
+		if err := r.indexScopeTemplates(context.Background(), reader); err != nil {
+			b.Fatal(err)
+		}
+	}
+	var templates []*operatorsv1.ScopeTemplate
+	for i := 0; i < 100; i++ {
+		templates = append(templates, newTestScopeTemplate(fmt.Sprintf("scopetemplate-%d", i)))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, st := range templates {
+			if requests := r.mapToScopeInstance(st); len(requests) != 10 {
+				b.Fatalf("expected 10 requests, got %d", len(requests))
+			}
+		}
+	}
+	b.ReportMetric(float64(reader.listed)/float64(b.N), "listed/op")
+}
@@ -0,0 +1,348 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+	"operator-framework/oria-operator/util"
+)
+
+const (
+	// sharedClusterRoleBindingKey labels the ClusterRoleBindings shared by
+	// every ScopeInstance binding the same ClusterRole.
+	sharedClusterRoleBindingKey = "operators.coreos.io/shared"
+
+	// sharedOwnerKeyPrefix prefixes a label on a shared ClusterRoleBinding
+	// for each ScopeInstance UID sharing it.
+	sharedOwnerKeyPrefix = "owner.operators.coreos.io/"
+
+	// sharedSubjectsKeyPrefix prefixes an annotation on a shared
+	// ClusterRoleBinding holding the subjects of each ScopeInstance sharing it.
+	sharedSubjectsKeyPrefix = "subjects.operators.coreos.io/"
+
+	// sharedProvenanceKeyPrefix prefixes an annotation on a shared
+	// ClusterRoleBinding holding the sharedProvenance of each ScopeInstance
+	// sharing it.
+	sharedProvenanceKeyPrefix = "provenance.operators.coreos.io/"
+)
+
+// sharedProvenance records, for auditors, the ScopeInstance sharing a
+// ClusterRoleBinding, the ScopeTemplate it shares it for and the hash of
+// their specs its subjects were last written with, as the
+// provenanceScopeInstanceKey, provenanceScopeTemplateKey and referenceHashKey
+// annotations do on the bindings a ScopeInstance owns alone.
+type sharedProvenance struct {
+	ScopeInstance string `json:"scopeInstance"`
+	ScopeTemplate string `json:"scopeTemplate"`
+	Hash          string `json:"hash"`
+}
+
+// sharedClusterRoleBindingName returns the name of the ClusterRoleBinding
+// shared by the ScopeInstances binding the named ClusterRole.
+func (r *ScopeInstanceReconciler) sharedClusterRoleBindingName(clusterRole string) string {
+	return util.TruncateWithHash(r.ShadowPrefix+"oria-shared-"+clusterRole, validation.DNS1123SubdomainMaxLength)
+}
+
+// sharedClusterRoleBindingNames returns the names of the shared
+// ClusterRoleBindings the ScopeInstance should belong to.
+func (r *ScopeInstanceReconciler) sharedClusterRoleBindingNames(in *operatorsv1.ScopeInstance, st *operatorsv1.ScopeTemplate) sets.String {
+	names := sets.NewString()
+	for _, cr := range st.Spec.BindingTemplates() {
+		if (isClusterScoped(in) || isClusterBound(&cr)) && !bindsRole(&cr) {
+			names.Insert(r.sharedClusterRoleBindingName(roleRef(&cr, in).Name))
+		}
+	}
+	return names
+}
+
+// sharedOwnerKey returns the key of the label and, with a different prefix,
+// of the annotations recording the given binding owner on a shared
+// ClusterRoleBinding.
+func sharedOwnerKey(owner string) string {
+	return util.TruncateWithHash(owner, validation.LabelValueMaxLength)
+}
+
+// joinSharedClusterRoleBinding adds the subjects of the desired
+// ClusterRoleBinding of the ScopeInstance to the ClusterRoleBinding shared by
+// every ScopeInstance binding the same ClusterRole, creating it if needed.
+func (r *ScopeInstanceReconciler) joinSharedClusterRoleBinding(ctx context.Context, desired *rbacv1.ClusterRoleBinding, in *operatorsv1.ScopeInstance) error {
+	if err := r.checkOperatorSubjects(in, desired.Subjects); err != nil {
+		return err
+	}
+	subjects, err := json.Marshal(desired.Subjects)
+	if err != nil {
+		return err
+	}
+	provenance, err := json.Marshal(sharedProvenance{
+		ScopeInstance: desired.Annotations[r.keys.key(provenanceScopeInstanceKey)],
+		ScopeTemplate: desired.Annotations[r.keys.key(provenanceScopeTemplateKey)],
+		Hash:          desired.Annotations[r.keys.key(referenceHashKey)],
+	})
+	if err != nil {
+		return err
+	}
+
+	owner := r.bindingOwner(in)
+	join := func(crb *rbacv1.ClusterRoleBinding) {
+		crb.SetLabels(setKey(crb.GetLabels(), r.keys.key(sharedOwnerKeyPrefix)+sharedOwnerKey(owner), "true"))
+		crb.SetAnnotations(setKey(crb.GetAnnotations(), r.keys.key(sharedSubjectsKeyPrefix)+sharedOwnerKey(owner), string(subjects)))
+		crb.SetAnnotations(setKey(crb.GetAnnotations(), r.keys.key(sharedProvenanceKeyPrefix)+sharedOwnerKey(owner), string(provenance)))
+		for _, ref := range crb.OwnerReferences {
+			if ref.UID == in.GetUID() {
+				return
+			}
+		}
+		crb.OwnerReferences = append(crb.OwnerReferences, metav1.OwnerReference{
+			APIVersion: operatorsv1.GroupVersion.String(),
+			Kind:       "ScopeInstance",
+			Name:       in.GetName(),
+			UID:        in.GetUID(),
+		})
+	}
+
+	name := r.sharedClusterRoleBindingName(desired.RoleRef.Name)
+	crb := &rbacv1.ClusterRoleBinding{}
+	if err := r.Client.Get(ctx, client.ObjectKey{Name: name}, crb); err == nil {
+		return r.updateSharedClusterRoleBinding(ctx, in.GetName(), name, join)
+	} else if !k8sapierrors.IsNotFound(err) {
+		return newBindingError("get", &rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: name}}, err)
+	}
+
+	crb = &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{r.keys.key(sharedClusterRoleBindingKey): "true"},
+		},
+		RoleRef: desired.RoleRef,
+	}
+	join(crb)
+	crb.Subjects = sharedSubjects(r.keys, crb)
+	err = r.Client.Create(ctx, crb, r.fieldOwner())
+	countBindingOperation("ClusterRoleBinding", opCreate, err)
+	if err != nil {
+		// Another ScopeInstance created it first, join it instead
+		if k8sapierrors.IsAlreadyExists(err) {
+			return r.updateSharedClusterRoleBinding(ctx, in.GetName(), name, join)
+		}
+		return newBindingError("create", crb, err)
+	}
+	bindingsCreated.WithLabelValues("ClusterRoleBinding").Inc()
+	reconcileSummaryFrom(ctx).bindingCreated()
+	return nil
+}
+
+// leaveSharedClusterRoleBindings removes the ScopeInstance from the shared
+// ClusterRoleBindings it belongs to, except for those named in keep.
+func (r *ScopeInstanceReconciler) leaveSharedClusterRoleBindings(ctx context.Context, in *operatorsv1.ScopeInstance, keep sets.String) error {
+	owner := r.bindingOwner(in)
+	crbList := &rbacv1.ClusterRoleBindingList{}
+	if err := r.Client.List(ctx, crbList, client.MatchingLabels{
+		r.keys.key(sharedOwnerKeyPrefix) + sharedOwnerKey(owner): "true",
+	}); err != nil {
+		return err
+	}
+
+	for _, crb := range crbList.Items {
+		if keep.Has(crb.GetName()) {
+			continue
+		}
+		if err := r.updateSharedClusterRoleBinding(ctx, in.GetName(), crb.GetName(), func(crb *rbacv1.ClusterRoleBinding) {
+			removeSharedOwner(r.keys, crb, owner, in.GetUID())
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// leaveAllSharedClusterRoleBindings removes the ScopeInstance from every
+// shared ClusterRoleBinding, if ConsolidateClusterRoleBindings is enabled.
+func (r *ScopeInstanceReconciler) leaveAllSharedClusterRoleBindings(ctx context.Context, in *operatorsv1.ScopeInstance) error {
+	if !r.ConsolidateClusterRoleBindings {
+		return nil
+	}
+	return r.leaveSharedClusterRoleBindings(ctx, in, sets.NewString())
+}
+
+// pruneSharedClusterRoleBindings removes the named ScopeInstance, once it is
+// gone, from the shared ClusterRoleBindings that list it as an owner.
+func (r *ScopeInstanceReconciler) pruneSharedClusterRoleBindings(ctx context.Context, name string) error {
+	crbList := &rbacv1.ClusterRoleBindingList{}
+	if err := r.Client.List(ctx, crbList, client.MatchingLabels{r.keys.key(sharedClusterRoleBindingKey): "true"}); err != nil {
+		return err
+	}
+
+	for _, crb := range crbList.Items {
+		for _, ref := range crb.OwnerReferences {
+			if ref.Kind == "ScopeInstance" && ref.Name == name {
+				if err := r.updateSharedClusterRoleBinding(ctx, name, crb.GetName(), nil); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// updateSharedClusterRoleBinding applies mutate, if not nil, to the named
+// shared ClusterRoleBinding, drops the owners that no longer exist and
+// recomputes its subjects from the remaining owners. The ClusterRoleBinding
+// is deleted once no owner is left, as confirmedDeletes allows for the
+// reconcile of the named ScopeInstance, and is left as is until then.
+func (r *ScopeInstanceReconciler) updateSharedClusterRoleBinding(ctx context.Context, scopeInstance, name string, mutate func(*rbacv1.ClusterRoleBinding)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		existing := &rbacv1.ClusterRoleBinding{}
+		if err := r.Client.Get(ctx, client.ObjectKey{Name: name}, existing); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+
+		updated := existing.DeepCopy()
+		if mutate != nil {
+			mutate(updated)
+		}
+		if err := r.pruneSharedOwners(ctx, updated); err != nil {
+			return err
+		}
+
+		if len(updated.OwnerReferences) == 0 {
+			if _, deferred := r.confirmedDeletes(scopeInstance, []client.Object{existing}); deferred {
+				return nil
+			}
+			log.Log.V(2).Info("deleting shared ClusterRoleBinding without owners", "name", name)
+			err := r.Client.Delete(ctx, existing, client.Preconditions{ResourceVersion: &existing.ResourceVersion})
+			if k8sapierrors.IsNotFound(err) {
+				return nil
+			}
+			countBindingOperation("ClusterRoleBinding", opDelete, err)
+			if err != nil {
+				return newBindingError("delete", existing, err)
+			}
+			bindingsDeleted.WithLabelValues("ClusterRoleBinding").Inc()
+			reconcileSummaryFrom(ctx).bindingDeleted()
+			return nil
+		}
+
+		updated.Subjects = sharedSubjects(r.keys, updated)
+		if equality.Semantic.DeepEqual(existing, updated) {
+			return nil
+		}
+		err := r.Client.Update(ctx, updated, r.fieldOwner())
+		countBindingOperation("ClusterRoleBinding", opUpdate, err)
+		if err != nil {
+			if k8sapierrors.IsConflict(err) {
+				return err
+			}
+			return newBindingError("update", updated, err)
+		}
+		reconcileSummaryFrom(ctx).bindingUpdated()
+		return nil
+	})
+}
+
+// pruneSharedOwners removes the owners of a shared ClusterRoleBinding whose
+// ScopeInstance was deleted, possibly while the operator was not running.
+func (r *ScopeInstanceReconciler) pruneSharedOwners(ctx context.Context, crb *rbacv1.ClusterRoleBinding) error {
+	for _, ref := range append([]metav1.OwnerReference{}, crb.OwnerReferences...) {
+		if ref.Kind != "ScopeInstance" {
+			continue
+		}
+		in := &operatorsv1.ScopeInstance{}
+		err := r.Client.Get(ctx, client.ObjectKey{Name: ref.Name}, in)
+		if err != nil && !k8sapierrors.IsNotFound(err) {
+			return err
+		}
+		if err != nil || in.GetUID() != ref.UID {
+			removeSharedOwner(r.keys, crb, r.ShadowPrefix+string(ref.UID), ref.UID)
+		}
+	}
+	return nil
+}
+
+// removeSharedOwner removes the label, annotations and owner reference of a
+// ScopeInstance from a shared ClusterRoleBinding.
+func removeSharedOwner(keys *keyMapping, crb *rbacv1.ClusterRoleBinding, owner string, uid types.UID) {
+	delete(crb.Labels, keys.key(sharedOwnerKeyPrefix)+sharedOwnerKey(owner))
+	delete(crb.Annotations, keys.key(sharedSubjectsKeyPrefix)+sharedOwnerKey(owner))
+	delete(crb.Annotations, keys.key(sharedProvenanceKeyPrefix)+sharedOwnerKey(owner))
+	refs := crb.OwnerReferences[:0]
+	for _, ref := range crb.OwnerReferences {
+		if ref.UID != uid {
+			refs = append(refs, ref)
+		}
+	}
+	crb.OwnerReferences = refs
+}
+
+// sharedSubjects returns the union of the subjects of every owner of a shared
+// ClusterRoleBinding, in a stable order.
+func sharedSubjects(keys *keyMapping, crb *rbacv1.ClusterRoleBinding) []rbacv1.Subject {
+	seen := map[rbacv1.Subject]bool{}
+	var subjects []rbacv1.Subject
+	for key, value := range crb.GetAnnotations() {
+		if !strings.HasPrefix(key, keys.key(sharedSubjectsKeyPrefix)) {
+			continue
+		}
+		var owned []rbacv1.Subject
+		if err := json.Unmarshal([]byte(value), &owned); err != nil {
+			log.Log.Error(err, "ignoring invalid subjects of a shared ClusterRoleBinding", "name", crb.GetName(), "annotation", key)
+			continue
+		}
+		for _, subject := range owned {
+			if !seen[subject] {
+				seen[subject] = true
+				subjects = append(subjects, subject)
+			}
+		}
+	}
+	sort.Slice(subjects, func(i, j int) bool {
+		a, b := subjects[i], subjects[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return subjects
+}
+
+// setKey sets key to value in m, allocating m if needed.
+func setKey(m map[string]string, key, value string) map[string]string {
+	if m == nil {
+		m = map[string]string{}
+	}
+	m[key] = value
+	return m
+}
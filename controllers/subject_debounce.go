@@ -0,0 +1,156 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+// subjectDebounce delays the requests enqueued for ScopeTemplate updates that
+// only change subjects, so that subjects changed repeatedly within the delay,
+// such as by an external sync, are written to the bindings in one update.
+// Requests for a ScopeInstance that is already waiting are dropped, the
+// reconcile at the end of the delay reads the latest subjects. A nil
+// subjectDebounce is valid and delays nothing.
+type subjectDebounce struct {
+	delay time.Duration
+
+	mu      sync.Mutex
+	waiting map[reconcile.Request]bool
+}
+
+func newSubjectDebounce(delay time.Duration) *subjectDebounce {
+	if delay <= 0 {
+		return nil
+	}
+	return &subjectDebounce{delay: delay, waiting: map[reconcile.Request]bool{}}
+}
+
+// handler wraps h so that the requests it enqueues for subject changes are
+// delayed. Every other event is passed through.
+func (d *subjectDebounce) handler(h handler.EventHandler) handler.EventHandler {
+	if d == nil {
+		return h
+	}
+	return handler.Funcs{
+		CreateFunc:  h.Create,
+		DeleteFunc:  h.Delete,
+		GenericFunc: h.Generic,
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			oldST, oldOk := e.ObjectOld.(*operatorsv1.ScopeTemplate)
+			newST, newOk := e.ObjectNew.(*operatorsv1.ScopeTemplate)
+			if !oldOk || !newOk || !onlySubjectsChanged(&oldST.Spec, &newST.Spec) {
+				h.Update(e, q)
+				return
+			}
+			h.Update(e, &debouncedQueue{RateLimitingInterface: q, debounce: d})
+		},
+	}
+}
+
+// add adds the request to q once the delay passes, unless it is waiting
+// already.
+func (d *subjectDebounce) add(q workqueue.RateLimitingInterface, req reconcile.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.waiting[req] {
+		return
+	}
+	d.waiting[req] = true
+	time.AfterFunc(d.delay, func() {
+		d.mu.Lock()
+		delete(d.waiting, req)
+		d.mu.Unlock()
+		q.Add(req)
+	})
+}
+
+// debouncedQueue passes the requests added by an event handler to the
+// debounce.
+type debouncedQueue struct {
+	workqueue.RateLimitingInterface
+	debounce *subjectDebounce
+}
+
+func (q *debouncedQueue) Add(item interface{}) {
+	req, ok := item.(reconcile.Request)
+	if !ok {
+		q.RateLimitingInterface.Add(item)
+		return
+	}
+	q.debounce.add(q.RateLimitingInterface, req)
+}
+
+// onlySubjectsChanged returns true if the specs differ in the subjects of
+// their entries alone.
+func onlySubjectsChanged(oldSpec, newSpec *operatorsv1.ScopeTemplateSpec) bool {
+	if equality.Semantic.DeepEqual(oldSpec, newSpec) {
+		return false
+	}
+	return equality.Semantic.DeepEqual(withoutSubjects(oldSpec), withoutSubjects(newSpec))
+}
+
+func withoutSubjects(spec *operatorsv1.ScopeTemplateSpec) *operatorsv1.ScopeTemplateSpec {
+	spec = spec.DeepCopy()
+	for i := range spec.ClusterRoles {
+		spec.ClusterRoles[i].Subjects = nil
+	}
+	for i := range spec.Bindings {
+		spec.Bindings[i].Subjects = nil
+	}
+	return spec
+}
+
+// withSortedSubjects returns a copy of the spec listing the subjects of each
+// entry in a stable order, so that reordering them changes nothing.
+func withSortedSubjects(spec *operatorsv1.ScopeTemplateSpec) *operatorsv1.ScopeTemplateSpec {
+	spec = spec.DeepCopy()
+	for i := range spec.ClusterRoles {
+		sortSubjects(spec.ClusterRoles[i].Subjects)
+	}
+	for i := range spec.Bindings {
+		sortSubjects(spec.Bindings[i].Subjects)
+	}
+	return spec
+}
+
+func sortSubjects(subjects []rbacv1.Subject) {
+	sort.Slice(subjects, func(i, j int) bool {
+		a, b := subjects[i], subjects[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.APIGroup != b.APIGroup {
+			return a.APIGroup < b.APIGroup
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+}
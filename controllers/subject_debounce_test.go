@@ -0,0 +1,122 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+var _ = Describe("subjectDebounce", func() {
+	var (
+		c  *writeCountingClient
+		r  *ScopeInstanceReconciler
+		q  workqueue.RateLimitingInterface
+		h  handler.EventHandler
+		st *operatorsv1.ScopeTemplate
+		si *operatorsv1.ScopeInstance
+	)
+	BeforeEach(func() {
+		st = newTestScopeTemplate("scopetemplate-subject-debounce")
+		si = &operatorsv1.ScopeInstance{
+			ObjectMeta: metav1.ObjectMeta{Name: "scopeinstance-subject-debounce", UID: "scopeinstance-subject-debounce-uid"},
+			Spec:       operatorsv1.ScopeInstanceSpec{ScopeTemplateName: st.GetName(), Namespaces: []string{"ns-1"}},
+		}
+		c = &writeCountingClient{Client: newFakeClient(si, st, newTestClusterRole("test"))}
+		r = &ScopeInstanceReconciler{Client: c, Scheme: scheme.Scheme}
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+		Expect(err).NotTo(HaveOccurred())
+
+		q = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		DeferCleanup(q.ShutDown)
+		h = newSubjectDebounce(50 * time.Millisecond).handler(handler.EnqueueRequestsFromMapFunc(r.mapToScopeInstance))
+	})
+
+	// update changes the ScopeTemplate with change and passes the update to
+	// the handler.
+	update := func(change func(spec *operatorsv1.ScopeTemplateSpec)) {
+		old := &operatorsv1.ScopeTemplate{}
+		ExpectWithOffset(1, c.Get(ctx, client.ObjectKeyFromObject(st), old)).To(Succeed())
+		updated := old.DeepCopy()
+		change(&updated.Spec)
+		ExpectWithOffset(1, c.Update(ctx, updated)).To(Succeed())
+		h.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated}, q)
+	}
+
+	It("should batch rapid subject changes into a single binding update", func() {
+		for i := 0; i < 5; i++ {
+			update(func(spec *operatorsv1.ScopeTemplateSpec) {
+				spec.ClusterRoles[0].Subjects = append(spec.ClusterRoles[0].Subjects, rbacv1.Subject{
+					Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: fmt.Sprintf("synced-%d", i),
+				})
+			})
+		}
+		Expect(q.Len()).To(BeZero())
+		Eventually(q.Len).Should(Equal(1))
+		Consistently(q.Len, 100*time.Millisecond).Should(Equal(1))
+
+		c.writes = nil
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+		Expect(err).NotTo(HaveOccurred())
+		var bindingWrites []string
+		for _, write := range c.writes {
+			if !strings.Contains(write, "ScopeInstance") {
+				bindingWrites = append(bindingWrites, write)
+			}
+		}
+		Expect(bindingWrites).To(HaveLen(1))
+		Expect(listFakeRoleBindings(c, "ns-1", si)[0].Subjects).To(HaveLen(6))
+	})
+
+	It("should enqueue other changes right away", func() {
+		update(func(spec *operatorsv1.ScopeTemplateSpec) {
+			spec.ClusterRoles[0].Rules[0].Verbs = []string{"get"}
+		})
+		Expect(q.Len()).To(Equal(1))
+	})
+
+	It("should not update the bindings when the subjects are reordered", func() {
+		update(func(spec *operatorsv1.ScopeTemplateSpec) {
+			spec.ClusterRoles[0].Subjects = append(spec.ClusterRoles[0].Subjects, rbacv1.Subject{
+				Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: "alice",
+			})
+		})
+		_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: si.GetName()}})
+		Expect(err).NotTo(HaveOccurred())
+
+		update(func(spec *operatorsv1.ScopeTemplateSpec) {
+			subjects := spec.ClusterRoles[0].Subjects
+			subjects[0], subjects[1] = subjects[1], subjects[0]
+		})
+		expectIdempotentReconcile(r, si.GetName())
+	})
+})
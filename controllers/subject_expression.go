@@ -0,0 +1,116 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
+)
+
+const (
+	// subjectExpressionCostLimit bounds the work a single evaluation may do.
+	subjectExpressionCostLimit = 100000
+
+	// subjectExpressionTimeout bounds how long a single evaluation may take.
+	subjectExpressionTimeout = time.Second
+)
+
+// errInvalidSubjectExpression is wrapped by every error caused by the
+// SubjectExpression of a ScopeTemplate, as opposed to the cluster.
+var errInvalidSubjectExpression = errors.New("invalid subject expression")
+
+// subjectExpressionEnv only declares the variables available to a
+// SubjectExpression, so expressions cannot reach anything outside of them.
+var subjectExpressionEnv = func() *cel.Env {
+	env, err := cel.NewEnv(cel.Declarations(
+		decls.NewVar("scopeInstance", decls.NewMapType(decls.String, decls.Dyn)),
+		decls.NewVar("namespaceName", decls.String),
+	))
+	if err != nil {
+		panic(err)
+	}
+	return env
+}()
+
+// evaluateSubjectExpression evaluates the given SubjectExpression for a
+// binding of the ScopeInstance in namespace, which is empty for a
+// ClusterRoleBinding.
+func evaluateSubjectExpression(ctx context.Context, expression string, in *operatorsv1.ScopeInstance, namespace string) ([]rbacv1.Subject, error) {
+	ast, issues := subjectExpressionEnv.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidSubjectExpression, issues.Err())
+	}
+	program, err := subjectExpressionEnv.Program(ast,
+		cel.CostLimit(subjectExpressionCostLimit),
+		cel.InterruptCheckFrequency(100))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errInvalidSubjectExpression, err)
+	}
+
+	scopeInstance, err := runtime.DefaultUnstructuredConverter.ToUnstructured(in)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, subjectExpressionTimeout)
+	defer cancel()
+	out, _, err := program.ContextEval(ctx, map[string]interface{}{
+		"scopeInstance": scopeInstance,
+		"namespaceName": namespace,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: evaluating: %s", errInvalidSubjectExpression, err)
+	}
+
+	// Round trip the result through JSON to decode it as subjects
+	value, err := out.ConvertToNative(reflect.TypeOf(&structpb.Value{}))
+	if err != nil {
+		return nil, fmt.Errorf("%w: converting result: %s", errInvalidSubjectExpression, err)
+	}
+	b, err := protojson.Marshal(value.(*structpb.Value))
+	if err != nil {
+		return nil, fmt.Errorf("%w: converting result: %s", errInvalidSubjectExpression, err)
+	}
+	var subjects []rbacv1.Subject
+	if err := json.Unmarshal(b, &subjects); err != nil {
+		return nil, fmt.Errorf("%w: result is not a list of subjects: %s", errInvalidSubjectExpression, err)
+	}
+
+	for i := range subjects {
+		subject := &subjects[i]
+		if subject.Kind == "" || subject.Name == "" {
+			return nil, fmt.Errorf("%w: subject %d has no kind or name", errInvalidSubjectExpression, i)
+		}
+		if subject.Kind != rbacv1.ServiceAccountKind && subject.APIGroup == "" {
+			subject.APIGroup = rbacv1.GroupName
+		}
+	}
+	return subjects, nil
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// errTooManySubjects is wrapped by the errors returned for bindings skipped
+// for having more than MaxSubjectsPerBinding subjects.
+var errTooManySubjects = errors.New("too many subjects")
+
+// withinSubjectLimit returns the desired bindings that have at most
+// MaxSubjectsPerBinding subjects, and an error wrapping errTooManySubjects
+// describing the others, if any. Large subject lists bloat etcd and slow down
+// the API server, so the bindings exceeding the limit are not written.
+func (r *ScopeInstanceReconciler) withinSubjectLimit(desired []client.Object) ([]client.Object, error) {
+	if r.MaxSubjectsPerBinding <= 0 {
+		return desired, nil
+	}
+
+	var within []client.Object
+	var exceeding []string
+	for _, binding := range desired {
+		count := len(bindingSubjects(binding))
+		if count <= r.MaxSubjectsPerBinding {
+			within = append(within, binding)
+			continue
+		}
+		exceeding = append(exceeding, fmt.Sprintf("%s has %d", describeBinding(r.keys, binding), count))
+	}
+	if len(exceeding) == 0 {
+		return desired, nil
+	}
+	return within, fmt.Errorf("%w: %s subjects, more than the maximum of %d", errTooManySubjects, strings.Join(exceeding, ", "), r.MaxSubjectsPerBinding)
+}
+
+// describeBinding describes a desired binding, which may not have a name yet,
+// by its kind, the generateName of its entry and its namespace.
+func describeBinding(keys *keyMapping, binding client.Object) string {
+	description := bindingKind(binding) + " " + binding.GetLabels()[keys.key(clusterRoleBindingGenerateKey)]
+	if ns := binding.GetNamespace(); ns != "" {
+		description += " in namespace " + ns
+	}
+	return description
+}
+
+// bindingSubjects returns the subjects of a (Cluster)RoleBinding.
+func bindingSubjects(binding client.Object) []rbacv1.Subject {
+	switch b := binding.(type) {
+	case *rbacv1.RoleBinding:
+		return b.Subjects
+	case *rbacv1.ClusterRoleBinding:
+		return b.Subjects
+	default:
+		return nil
+	}
+}
@@ -17,22 +17,36 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
 	"operator-framework/oria-operator/controllers"
+	"operator-framework/oria-operator/util"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -52,11 +66,132 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var scopeTemplateGracePeriod time.Duration
+	var exportRBAC bool
+	var preflight bool
+	var atomicBindingSwap bool
+	var maxDeletesPerReconcile int
+	var maxConcurrentBindingWrites int
+	var maxSubjectsPerBinding int
+	var namespaceCreateQPS float64
+	var namespaceCreateBurst int
+	var skipForbiddenNamespaces bool
+	var deletionSafeMode bool
+	var consolidateClusterRoleBindings bool
+	var fieldManager string
+	var kubeAPIQPS float64
+	var kubeAPIBurst int
+	var shadowPrefix string
+	var renewalWindow time.Duration
+	var debugAddr string
+	var operatorServiceAccount string
+	var operatorSubjectPolicy string
+	var defaultScopeTemplate string
+	var forbiddenRules string
+	var clusterWideVerbs string
+	var clusterWideClusterRoles string
+	var circuitBreakerThreshold int
+	var circuitBreakerInterval time.Duration
+	var deletePropagation string
+	var heartbeatLease string
+	var statusDebounce time.Duration
+	var subjectChangeDebounce time.Duration
+	var readReplicaHost string
+	var sweepOrphanedBindings bool
+	var groupSetNamespace string
+	var labelPrefix string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.DurationVar(&scopeTemplateGracePeriod, "scope-template-grace-period", 30*time.Second,
+		"How long a ScopeTemplate may be missing before the bindings of the ScopeInstances referencing it are deleted.")
+	flag.BoolVar(&exportRBAC, "export-rbac", false,
+		"Write the (Cluster)RoleBindings managed by the operator to stdout as YAML and exit.")
+	flag.BoolVar(&preflight, "preflight", false,
+		"Verify that the operator is allowed to manage (Cluster)RoleBindings before starting.")
+	flag.BoolVar(&atomicBindingSwap, "atomic-binding-swap", false,
+		"Create new (Cluster)RoleBindings alongside the old ones when a ScopeInstance or ScopeTemplate changes, "+
+			"deleting the old ones only once every new binding has been created.")
+	flag.IntVar(&maxDeletesPerReconcile, "max-deletes-per-reconcile", 0,
+		"The most (Cluster)RoleBindings a single ScopeInstance reconcile may delete without confirmation. Zero means no limit.")
+	flag.IntVar(&maxConcurrentBindingWrites, "max-concurrent-binding-writes", 4,
+		"How many of the RoleBindings of a ClusterRole a single ScopeInstance reconcile creates or updates at once.")
+	flag.IntVar(&maxSubjectsPerBinding, "max-subjects-per-binding", 0,
+		"The most subjects a single (Cluster)RoleBinding may have. Bindings with more are not written and their "+
+			"ScopeInstance is marked TooManySubjects. Zero means no limit.")
+	flag.Float64Var(&namespaceCreateQPS, "namespace-create-qps", 0,
+		"How many RoleBindings per second the operator creates in a single namespace, such as while a cluster is bootstrapped. "+
+			"Zero does not throttle creates.")
+	flag.IntVar(&namespaceCreateBurst, "namespace-create-burst", 10,
+		"How many RoleBindings the operator may create at once in a single namespace before --namespace-create-qps applies.")
+	flag.BoolVar(&skipForbiddenNamespaces, "skip-forbidden-namespaces", false,
+		"Skip the namespaces in which the operator is not allowed to write RoleBindings, recording them in the "+
+			"ScopeInstance status, instead of failing the reconcile.")
+	flag.BoolVar(&deletionSafeMode, "deletion-safe-mode", false,
+		"Only delete a (Cluster)RoleBinding once two consecutive reconciles of its ScopeInstance intend to, "+
+			"such as while upgrading the operator.")
+	flag.BoolVar(&consolidateClusterRoleBindings, "consolidate-cluster-role-bindings", false,
+		"Bind the ScopeInstances bound cluster-wide to the same ClusterRole through a single shared ClusterRoleBinding.")
+	flag.StringVar(&fieldManager, "field-manager", controllers.DefaultFieldManager,
+		"The field manager used for every create, update and apply made by the operator.")
+	flag.Float64Var(&kubeAPIQPS, "kube-api-qps", 0,
+		"The QPS limit of the operator's Kubernetes client. Zero keeps the default.")
+	flag.IntVar(&kubeAPIBurst, "kube-api-burst", 0,
+		"The burst limit of the operator's Kubernetes client. Zero keeps the default.")
+	flag.StringVar(&shadowPrefix, "shadow-prefix", "",
+		"Prefix the names of every (Cluster)RoleBinding and its subjects, and the namespaces of its ServiceAccount subjects, "+
+			"so that the output of the operator can be inspected without granting anything to the real subjects. "+
+			"The bindings are created alongside the real ones, the prefix must fit in a label value with a UID.")
+	flag.DurationVar(&renewalWindow, "renewal-window", time.Hour,
+		"How long after the time in its operators.coreos.io/renew annotation a ScopeInstance expires.")
+	flag.StringVar(&debugAddr, "debug-bind-address", "",
+		"The loopback address serving the state of every ScopeInstance as JSON on "+controllers.DebugPath+". Disabled if empty.")
+	flag.StringVar(&operatorServiceAccount, "operator-service-account", "",
+		"The <namespace>/<name> of the ServiceAccount the operator runs as. "+
+			"(Cluster)RoleBindings granting to it are handled according to --operator-subject-policy. Disabled if empty.")
+	flag.StringVar(&operatorSubjectPolicy, "operator-subject-policy", string(controllers.OperatorSubjectReject),
+		"Whether to Reject or Warn on (Cluster)RoleBindings granting to the operator's own ServiceAccount.")
+	flag.StringVar(&defaultScopeTemplate, "default-scope-template", "",
+		"The name of the ScopeTemplate used by ScopeInstances that do not reference one. Such ScopeInstances are inert if empty.")
+	flag.StringVar(&forbiddenRules, "forbidden-rules", "",
+		"A comma separated list of <verb>:<resource>[.<group>] rules, any part of which may be *, that the ClusterRoles "+
+			"of a ScopeTemplate may not grant. No RBAC is created for ScopeTemplates granting one.")
+	flag.StringVar(&clusterWideVerbs, "cluster-wide-verbs", "",
+		"A comma separated list of the verbs, such as get,list,watch, that the ClusterRoles bound cluster-wide may grant. "+
+			"No binding is created for ScopeInstances binding another verb cluster-wide. Every verb is allowed if empty.")
+	flag.StringVar(&clusterWideClusterRoles, "cluster-wide-cluster-roles", "",
+		"A comma separated list of the ClusterRoles that may be bound cluster-wide whatever --cluster-wide-verbs allows.")
+	flag.IntVar(&circuitBreakerThreshold, "circuit-breaker-threshold", 0,
+		"How many consecutive reconciles of a ScopeInstance may fail before it is only retried every "+
+			"--circuit-breaker-interval until a reconcile succeeds. Zero disables the circuit breaker.")
+	flag.DurationVar(&circuitBreakerInterval, "circuit-breaker-interval", 5*time.Minute,
+		"How often a ScopeInstance is retried once its circuit breaker is open.")
+	flag.StringVar(&deletePropagation, "delete-propagation", "",
+		"The propagation policy, Foreground, Background or Orphan, of every (Cluster)RoleBinding deleted by the operator. "+
+			"The API server default is used if empty.")
+	flag.StringVar(&heartbeatLease, "heartbeat-lease", "",
+		"The <namespace>/<name> of a Lease renewed as ScopeInstances are reconciled, and while the operator is idle, "+
+			"so that a stalled operator can be detected from its renewTime. Disabled if empty.")
+	flag.DurationVar(&statusDebounce, "status-debounce", 0,
+		"Coalesce the status writes of each ScopeInstance made within this long of the previous one, "+
+			"such as while a ScopeTemplate change reconciles many ScopeInstances. Zero writes every status change right away.")
+	flag.DurationVar(&subjectChangeDebounce, "subject-change-debounce", 0,
+		"Delay the reconciles caused by ScopeTemplate updates that only change subjects by this long, "+
+			"so that subjects changed repeatedly are written to the bindings at once. Zero reconciles every change right away.")
+	flag.StringVar(&readReplicaHost, "read-replica-host", "",
+		"The address of an API server read replica. A cache filled from it serves the lists of ScopeInstances, "+
+			"ServiceAccounts and (Cluster)RoleBindings made by the ScopeInstance controller, writes still go to the primary. Disabled if empty.")
+	flag.BoolVar(&sweepOrphanedBindings, "sweep-orphaned-bindings", false,
+		"Delete on startup the (Cluster)RoleBindings of ScopeInstances that no longer exist, such as those deleted while the operator was down. "+
+			"The deletions are subject to --max-deletes-per-reconcile and --deletion-safe-mode.")
+	flag.StringVar(&groupSetNamespace, "group-set-namespace", "",
+		"The namespace of the ConfigMaps holding the group sets referenced by ScopeTemplates. Only the ConfigMaps "+
+			"of this namespace are cached. Referencing a group set fails if empty.")
+	flag.StringVar(&labelPrefix, "label-prefix", controllers.DefaultKeyPrefix,
+		"The prefix of the labels and annotations the operator keeps its bookkeeping in. The labels and annotations "+
+			"of bindings and ClusterRoles made under the default prefix are migrated to it on startup.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -65,13 +200,119 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	var operatorSA types.NamespacedName
+	if operatorServiceAccount != "" {
+		namespace, name, ok := strings.Cut(operatorServiceAccount, "/")
+		if !ok || namespace == "" || name == "" {
+			setupLog.Error(fmt.Errorf("expected <namespace>/<name>, got %q", operatorServiceAccount), "invalid --operator-service-account")
+			os.Exit(1)
+		}
+		operatorSA = types.NamespacedName{Namespace: namespace, Name: name}
+	}
+	var heartbeatKey types.NamespacedName
+	if heartbeatLease != "" {
+		namespace, name, ok := strings.Cut(heartbeatLease, "/")
+		if !ok || namespace == "" || name == "" {
+			setupLog.Error(fmt.Errorf("expected <namespace>/<name>, got %q", heartbeatLease), "invalid --heartbeat-lease")
+			os.Exit(1)
+		}
+		heartbeatKey = types.NamespacedName{Namespace: namespace, Name: name}
+	}
+	switch policy := controllers.OperatorSubjectPolicy(operatorSubjectPolicy); policy {
+	case controllers.OperatorSubjectReject, controllers.OperatorSubjectWarn:
+	default:
+		setupLog.Error(fmt.Errorf("expected %s or %s, got %q", controllers.OperatorSubjectReject, controllers.OperatorSubjectWarn, policy), "invalid --operator-subject-policy")
+		os.Exit(1)
+	}
+
+	switch policy := metav1.DeletionPropagation(deletePropagation); policy {
+	case "", metav1.DeletePropagationForeground, metav1.DeletePropagationBackground, metav1.DeletePropagationOrphan:
+	default:
+		setupLog.Error(fmt.Errorf("expected %s, %s or %s, got %q", metav1.DeletePropagationForeground,
+			metav1.DeletePropagationBackground, metav1.DeletePropagationOrphan, policy), "invalid --delete-propagation")
+		os.Exit(1)
+	}
+
+	if err := controllers.ValidateKeyPrefix(labelPrefix); err != nil {
+		setupLog.Error(err, "invalid --label-prefix")
+		os.Exit(1)
+	}
+
+	if err := controllers.ValidateShadowPrefix(shadowPrefix); err != nil {
+		setupLog.Error(err, "invalid --shadow-prefix")
+		os.Exit(1)
+	}
+
+	rulePolicy, err := controllers.ParseRulePolicy(forbiddenRules)
+	if err != nil {
+		setupLog.Error(err, "invalid --forbidden-rules")
+		os.Exit(1)
+	}
+
+	cfg := ctrl.GetConfigOrDie()
+	util.SetRateLimits(cfg, float32(kubeAPIQPS), kubeAPIBurst)
+
+	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		setupLog.Error(err, "unable to create discovery client")
+		os.Exit(1)
+	}
+	if err := controllers.CheckRBACAPI(dc); err != nil {
+		setupLog.Error(err, "incompatible cluster")
+		os.Exit(1)
+	}
+
+	if exportRBAC {
+		c, err := client.New(cfg, client.Options{Scheme: scheme})
+		if err != nil {
+			setupLog.Error(err, "unable to create client")
+			os.Exit(1)
+		}
+		if err := controllers.ExportManagedRBAC(context.Background(), c, labelPrefix, os.Stdout); err != nil {
+			setupLog.Error(err, "unable to export managed RBAC")
+			os.Exit(1)
+		}
+		return
+	}
+
+	if preflight {
+		c, err := client.New(cfg, client.Options{Scheme: scheme})
+		if err != nil {
+			setupLog.Error(err, "unable to create client")
+			os.Exit(1)
+		}
+		if err := controllers.Preflight(context.Background(), c); err != nil {
+			setupLog.Error(err, "preflight check failed, verify the RBAC granted to the operator's ServiceAccount")
+			os.Exit(1)
+		}
+		setupLog.Info("preflight check passed")
+	}
+
+	// The reconcilers only select the keys under the label prefix, so those
+	// made under the default one are moved before they start
+	if labelPrefix != controllers.DefaultKeyPrefix {
+		c, err := client.New(cfg, client.Options{Scheme: scheme})
+		if err != nil {
+			setupLog.Error(err, "unable to create client")
+			os.Exit(1)
+		}
+		if err := controllers.MigrateKeys(context.Background(), c, labelPrefix); err != nil {
+			setupLog.Error(err, "unable to migrate the bookkeeping keys to the label prefix")
+			os.Exit(1)
+		}
+	}
+
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
 		Port:                   9443,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "17ccecbb.io.operator-framework",
+		// Only the ConfigMaps of group sets are read
+		NewCache: cache.BuilderWithOptions(cache.Options{SelectorsByObject: cache.SelectorsByObject{
+			&corev1.ConfigMap{}: {Field: fields.OneTermEqualSelector("metadata.namespace", groupSetNamespace)},
+		}}),
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -89,20 +330,111 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = (&controllers.ScopeInstanceReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	scopeInstanceReconciler := &controllers.ScopeInstanceReconciler{
+		Client:                         mgr.GetClient(),
+		Scheme:                         mgr.GetScheme(),
+		ScopeTemplateGracePeriod:       scopeTemplateGracePeriod,
+		AtomicBindingSwap:              atomicBindingSwap,
+		MaxDeletesPerReconcile:         maxDeletesPerReconcile,
+		MaxConcurrentBindingWrites:     maxConcurrentBindingWrites,
+		MaxSubjectsPerBinding:          maxSubjectsPerBinding,
+		NamespaceCreateQPS:             float32(namespaceCreateQPS),
+		NamespaceCreateBurst:           namespaceCreateBurst,
+		SkipForbiddenNamespaces:        skipForbiddenNamespaces,
+		DeletionSafeMode:               deletionSafeMode,
+		ConsolidateClusterRoleBindings: consolidateClusterRoleBindings,
+		Recorder:                       mgr.GetEventRecorderFor("scopeinstance-controller"),
+		FieldManager:                   fieldManager,
+		KeyPrefix:                      labelPrefix,
+		ShadowPrefix:                   shadowPrefix,
+		RenewalWindow:                  renewalWindow,
+		OperatorServiceAccount:         operatorSA,
+		OperatorSubjectPolicy:          controllers.OperatorSubjectPolicy(operatorSubjectPolicy),
+		DefaultScopeTemplate:           defaultScopeTemplate,
+		GroupSetNamespace:              groupSetNamespace,
+		RulePolicy:                     rulePolicy,
+		ClusterWidePolicy:              controllers.ParseClusterWidePolicy(clusterWideVerbs, clusterWideClusterRoles),
+		CircuitBreakerThreshold:        circuitBreakerThreshold,
+		CircuitBreakerInterval:         circuitBreakerInterval,
+		DeletePropagation:              metav1.DeletionPropagation(deletePropagation),
+		StatusDebounce:                 statusDebounce,
+		SubjectChangeDebounce:          subjectChangeDebounce,
+	}
+	if readReplicaHost != "" {
+		replicaCfg := rest.CopyConfig(cfg)
+		replicaCfg.Host = readReplicaHost
+		replica, err := cluster.New(replicaCfg, func(o *cluster.Options) { o.Scheme = scheme })
+		if err != nil {
+			setupLog.Error(err, "unable to create read replica client")
+			os.Exit(1)
+		}
+		// The cache of the replica is started and synced along with the one of the manager
+		if err := mgr.Add(replica); err != nil {
+			setupLog.Error(err, "unable to add read replica")
+			os.Exit(1)
+		}
+		scopeInstanceReconciler.Reader = replica.GetCache()
+	}
+	if heartbeatLease != "" {
+		identity, err := os.Hostname()
+		if err != nil {
+			setupLog.Error(err, "unable to determine the heartbeat identity")
+			os.Exit(1)
+		}
+		scopeInstanceReconciler.Heartbeat = &controllers.Heartbeat{
+			Client:   mgr.GetClient(),
+			Reader:   mgr.GetAPIReader(),
+			Lease:    heartbeatKey,
+			Identity: identity,
+		}
+		if err := mgr.Add(scopeInstanceReconciler.Heartbeat); err != nil {
+			setupLog.Error(err, "unable to add heartbeat")
+			os.Exit(1)
+		}
+	}
+	if sweepOrphanedBindings {
+		if err := mgr.Add(scopeInstanceReconciler.OrphanSweep(mgr.GetAPIReader())); err != nil {
+			setupLog.Error(err, "unable to add orphaned binding sweep")
+			os.Exit(1)
+		}
+	}
+	if err = scopeInstanceReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ScopeInstance")
 		os.Exit(1)
 	}
+	if debugAddr != "" {
+		debugServer, err := scopeInstanceReconciler.DebugServer(debugAddr)
+		if err != nil {
+			setupLog.Error(err, "unable to create debug server")
+			os.Exit(1)
+		}
+		if err := mgr.Add(debugServer); err != nil {
+			setupLog.Error(err, "unable to add debug server")
+			os.Exit(1)
+		}
+	}
 	if err = (&controllers.ScopeTemplateReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:               mgr.GetClient(),
+		Scheme:               mgr.GetScheme(),
+		FieldManager:         fieldManager,
+		KeyPrefix:            labelPrefix,
+		DefaultScopeTemplate: defaultScopeTemplate,
+		RulePolicy:           rulePolicy,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ScopeTemplate")
 		os.Exit(1)
 	}
+	// The webhook needs serving certificates, see config/webhook and config/certmanager
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = (&operatorsv1.ScopeInstance{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ScopeInstance")
+			os.Exit(1)
+		}
+		if err = (&operatorsv1.ScopeTemplate{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ScopeTemplate")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -23,6 +23,72 @@ var _ = Describe("Oria-Operator e2e", func() {
 	testScenario("Scoping to all namespaces (cluster-wide scoping)", nil, true)
 })
 
+var _ = Describe("Deleting a ScopeTemplate", func() {
+	var (
+		scopeTemplate *operatorsv1.ScopeTemplate
+		scopeInstance *operatorsv1.ScopeInstance
+	)
+	BeforeEach(func() {
+		scopeTemplate = &operatorsv1.ScopeTemplate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "oria-e2e-scopetemplate-gc",
+			},
+			Spec: operatorsv1.ScopeTemplateSpec{
+				ClusterRoles: []operatorsv1.ClusterRoleTemplate{
+					{
+						GenerateName: "oria-e2e-clusterrole-gc",
+						Rules: []rbacv1.PolicyRule{
+							{
+								Verbs:     []string{"get"},
+								Resources: []string{"pods"},
+								APIGroups: []string{corev1.GroupName},
+							},
+						},
+					},
+				},
+			},
+		}
+		Eventually(func() error { return c.Create(context.Background(), scopeTemplate) }).Should(Succeed())
+
+		scopeInstance = &operatorsv1.ScopeInstance{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "oria-e2e-scopeinstance-gc",
+			},
+			Spec: operatorsv1.ScopeInstanceSpec{
+				ScopeTemplateName: scopeTemplate.Name,
+			},
+		}
+		Eventually(func() error { return c.Create(context.Background(), scopeInstance) }).Should(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(c.Delete(context.Background(), scopeInstance)).Should(Succeed())
+	})
+
+	It("Should garbage collect the ClusterRoles it generated", func() {
+		cr := &rbacv1.ClusterRole{}
+		fetchCr := func() error {
+			return c.Get(context.Background(), types.NamespacedName{Name: scopeTemplate.Spec.ClusterRoles[0].GenerateName}, cr)
+		}
+		Eventually(fetchCr).Should(Succeed())
+		Expect(cr.OwnerReferences).Should(ConsistOf(metav1.OwnerReference{
+			APIVersion:         operatorsv1.GroupVersion.String(),
+			Kind:               "ScopeTemplate",
+			Name:               scopeTemplate.GetName(),
+			UID:                scopeTemplate.GetUID(),
+			Controller:         pointer.Bool(true),
+			BlockOwnerDeletion: pointer.Bool(true),
+		}))
+
+		// The ScopeInstance still references the ScopeTemplate, so only the
+		// garbage collector removes the ClusterRole
+		Expect(c.Delete(context.Background(), scopeTemplate)).Should(Succeed())
+		Eventually(func() bool {
+			return apierrors.IsNotFound(fetchCr())
+		}).Should(BeTrue())
+	})
+})
+
 // testScenario is a generalized test suite that can be used to test different scenarios.
 // It accepts a description of the scenario, a list of namespaces, and a bool to indicate
 // whether or not the permissions should be cluster-scoped. If the permissions are
@@ -255,7 +321,7 @@ func testScenario(description string, namespaces []string, clusterScoped bool) {
 					Expect(cr.Rules).Should(Equal(scopeTemplate.Spec.ClusterRoles[0].Rules))
 					Expect(cr.Labels).Should(HaveKeyWithValue("operators.coreos.io/scopeTemplateUID", string(scopeTemplate.GetUID())))
 					Expect(cr.Labels).Should(HaveKeyWithValue("operators.coreos.io/generateName", scopeTemplate.Spec.ClusterRoles[0].GenerateName))
-					Expect(cr.Labels).Should(HaveKey("operators.coreos.io/scopeTemplateHash"))
+					Expect(cr.Annotations).Should(HaveKey("operators.coreos.io/scopeTemplateHash"))
 				})
 
 				It("Should create (Cluster)RoleBinding(s) as specified by the ScopeInstance", func() {
@@ -294,7 +360,7 @@ func testScenario(description string, namespaces []string, clusterScoped bool) {
 						Expect(crb.Name).Should(ContainSubstring(scopeTemplate.Spec.ClusterRoles[0].GenerateName))
 						Expect(crb.Labels).Should(HaveKeyWithValue("operators.coreos.io/scopeInstanceUID", string(scopeInstance.GetUID())))
 						Expect(crb.Labels).Should(HaveKeyWithValue("operators.coreos.io/generateName", scopeTemplate.Spec.ClusterRoles[0].GenerateName))
-						Expect(crb.Labels).Should(HaveKey("operators.coreos.io/scopeInstanceAndTemplateHash"))
+						Expect(crb.Annotations).Should(HaveKey("operators.coreos.io/scopeInstanceHash"))
 					} else {
 						for _, namespace := range namespaceList {
 							rb := &rbacv1.RoleBinding{}
@@ -327,7 +393,7 @@ func testScenario(description string, namespaces []string, clusterScoped bool) {
 							Expect(rb.Name).Should(ContainSubstring(scopeTemplate.Spec.ClusterRoles[0].GenerateName))
 							Expect(rb.Labels).Should(HaveKeyWithValue("operators.coreos.io/scopeInstanceUID", string(scopeInstance.GetUID())))
 							Expect(rb.Labels).Should(HaveKeyWithValue("operators.coreos.io/generateName", scopeTemplate.Spec.ClusterRoles[0].GenerateName))
-							Expect(rb.Labels).Should(HaveKey("operators.coreos.io/scopeInstanceAndTemplateHash"))
+							Expect(rb.Annotations).Should(HaveKey("operators.coreos.io/scopeInstanceHash"))
 						}
 					}
 				})
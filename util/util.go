@@ -9,6 +9,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/rest"
 )
 
 // Owner is used to build an OwnerReference, and we need type and object metadata
@@ -21,8 +22,10 @@ const (
 	OwnerLabel = "operators.coreos.io/scopeInstanceUID"
 )
 
-func IsOwnedByLabel(object metav1.Object, owner Owner) bool {
-	ok := GetOwnerByLabel(object, owner)
+// IsOwnedByLabel returns whether the label of the object, such as
+// OwnerLabel, or else an OwnerReference, names the owner.
+func IsOwnedByLabel(object metav1.Object, owner Owner, label string) bool {
+	ok := GetOwnerByLabel(object, owner, label)
 	if !ok {
 		// if not owned by label, see if we can find a reference
 		ownerref := GetOwnerByRef(object, owner)
@@ -34,19 +37,19 @@ func IsOwnedByLabel(object metav1.Object, owner Owner) bool {
 	return true
 }
 
-func GetOwnerByLabel(object metav1.Object, owner Owner) (ok bool) {
+func GetOwnerByLabel(object metav1.Object, owner Owner, label string) (ok bool) {
 	if object == nil || owner == nil {
 		// let's not panic in simple ways
 		return false
 	}
 
-	label, ok := object.GetLabels()[OwnerLabel]
+	value, ok := object.GetLabels()[label]
 	if !ok {
 		// if there is no label, we return false
 		return false
 	}
 
-	return label == string(owner.GetUID())
+	return value == string(owner.GetUID())
 }
 
 func GetOwnerByRef(object metav1.Object, owner Owner) (ok bool) {
@@ -63,13 +66,38 @@ func GetOwnerByRef(object metav1.Object, owner Owner) (ok bool) {
 	return false
 }
 
-// HashObject calculates a hash from an object
+// HashObject calculates a hash from an object. The hash is derived from a
+// 32-bit FNV-1a sum, so it is at most 10 alphanumeric characters long and is
+// always safe to use as a Kubernetes label value or in an object name.
 func HashObject(obj interface{}) string {
 	hasher := fnv.New32a()
 	deepHashObject(hasher, &obj)
 	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
 }
 
+// TruncateWithHash returns s unchanged if it is at most max characters long.
+// Otherwise s is shortened and suffixed with "-" and the hash of s, so that
+// the result is at most max characters long and distinct values of s remain
+// distinct. max must leave room for the hash, i.e. be larger than 11.
+func TruncateWithHash(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	hash := HashObject(s)
+	return s[:max-len(hash)-1] + "-" + hash
+}
+
+// SetRateLimits sets the client-side QPS and Burst of cfg. Values that are
+// not positive leave the existing limits of cfg in place.
+func SetRateLimits(cfg *rest.Config, qps float32, burst int) {
+	if qps > 0 {
+		cfg.QPS = qps
+	}
+	if burst > 0 {
+		cfg.Burst = burst
+	}
+}
+
 // DeepHashObject writes specified object to hash using the spew library
 // which follows pointers and prints actual values of the nested objects
 // ensuring the hash does not change when a pointer changes.
@@ -1,11 +1,15 @@
 package util
 
 import (
+	"strings"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/rest"
 
 	operatorsv1 "operator-framework/oria-operator/api/v1alpha1"
 )
@@ -55,7 +59,7 @@ var _ = Describe("Util", func() {
 				UID:        "uid",
 			}})
 
-			Expect(IsOwnedByLabel(rb.DeepCopy(), owner)).To(Equal(true))
+			Expect(IsOwnedByLabel(rb.DeepCopy(), owner, OwnerLabel)).To(Equal(true))
 		})
 		It("should return true if owner label matches", func() {
 			// set a specific UID for this owner
@@ -66,15 +70,15 @@ var _ = Describe("Util", func() {
 				OwnerLabel: "uid", // should match the uid of the owner
 			})
 
-			Expect(IsOwnedByLabel(rb.DeepCopy(), owner)).To(Equal(true))
+			Expect(IsOwnedByLabel(rb.DeepCopy(), owner, OwnerLabel)).To(Equal(true))
 		})
 		It("should return false if no label and no reference", func() {
-			Expect(IsOwnedByLabel(rb.DeepCopy(), owner)).To(Equal(false))
+			Expect(IsOwnedByLabel(rb.DeepCopy(), owner, OwnerLabel)).To(Equal(false))
 		})
 		It("should return false if either option is nil", func() {
-			Expect(IsOwnedByLabel(nil, nil)).To(Equal(false))
-			Expect(IsOwnedByLabel(rb.DeepCopy(), nil)).To(Equal(false))
-			Expect(IsOwnedByLabel(nil, owner)).To(Equal(false))
+			Expect(IsOwnedByLabel(nil, nil, OwnerLabel)).To(Equal(false))
+			Expect(IsOwnedByLabel(rb.DeepCopy(), nil, OwnerLabel)).To(Equal(false))
+			Expect(IsOwnedByLabel(nil, owner, OwnerLabel)).To(Equal(false))
 		})
 	})
 
@@ -117,7 +121,7 @@ var _ = Describe("Util", func() {
 				OwnerLabel: "uid", // should match the uid of the owner
 			})
 
-			Expect(GetOwnerByLabel(rb.DeepCopy(), owner)).To(Equal(true))
+			Expect(GetOwnerByLabel(rb.DeepCopy(), owner, OwnerLabel)).To(Equal(true))
 		})
 		It("should return false if owner label does not match", func() {
 			// set a specific UID for this owner
@@ -128,15 +132,15 @@ var _ = Describe("Util", func() {
 				OwnerLabel: "anotheruid", // should be different than owner
 			})
 
-			Expect(GetOwnerByLabel(rb.DeepCopy(), owner)).To(Equal(false))
+			Expect(GetOwnerByLabel(rb.DeepCopy(), owner, OwnerLabel)).To(Equal(false))
 		})
 		It("should return false if owner label does not exist", func() {
-			Expect(GetOwnerByLabel(rb.DeepCopy(), owner)).To(Equal(false))
+			Expect(GetOwnerByLabel(rb.DeepCopy(), owner, OwnerLabel)).To(Equal(false))
 		})
 		It("should return false if either option is nil", func() {
-			Expect(GetOwnerByLabel(nil, nil)).To(Equal(false))
-			Expect(GetOwnerByLabel(rb.DeepCopy(), nil)).To(Equal(false))
-			Expect(GetOwnerByLabel(nil, owner)).To(Equal(false))
+			Expect(GetOwnerByLabel(nil, nil, OwnerLabel)).To(Equal(false))
+			Expect(GetOwnerByLabel(rb.DeepCopy(), nil, OwnerLabel)).To(Equal(false))
+			Expect(GetOwnerByLabel(nil, owner, OwnerLabel)).To(Equal(false))
 		})
 	})
 
@@ -220,7 +224,7 @@ var _ = Describe("Util", func() {
 			}
 
 			hash := HashObject(si.Spec)
-			Expect(hash).Should(Equal("577777495c"))
+			Expect(hash).Should(Equal("54bcbdf74"))
 		})
 		It("should return a hash for an empty string", func() {
 			hash := HashObject("")
@@ -230,5 +234,50 @@ var _ = Describe("Util", func() {
 			hash := HashObject(nil)
 			Expect(hash).Should(Equal("5cb59f8c64"))
 		})
+		It("should return a valid label value", func() {
+			objects := []interface{}{
+				nil,
+				"",
+				strings.Repeat("a", 1024),
+				operatorsv1.ScopeInstanceSpec{
+					ScopeTemplateName: strings.Repeat("scopetemplate-name", 20),
+					Namespaces:        []string{strings.Repeat("test-foo", 50)},
+				},
+			}
+			for _, obj := range objects {
+				hash := HashObject(obj)
+				Expect(len(hash)).Should(BeNumerically("<=", validation.LabelValueMaxLength))
+				Expect(validation.IsValidLabelValue(hash)).Should(BeEmpty())
+			}
+		})
+	})
+
+	Describe("TruncateWithHash", func() {
+		It("should not change short strings", func() {
+			Expect(TruncateWithHash("test", validation.LabelValueMaxLength)).Should(Equal("test"))
+		})
+		It("should shorten long strings and keep them distinct", func() {
+			a := TruncateWithHash(strings.Repeat("a", 250)+"-1", validation.LabelValueMaxLength)
+			b := TruncateWithHash(strings.Repeat("a", 250)+"-2", validation.LabelValueMaxLength)
+			Expect(len(a)).Should(BeNumerically("<=", validation.LabelValueMaxLength))
+			Expect(validation.IsValidLabelValue(a)).Should(BeEmpty())
+			Expect(a).Should(HavePrefix(strings.Repeat("a", 40)))
+			Expect(a).ShouldNot(Equal(b))
+		})
+	})
+
+	Describe("SetRateLimits", func() {
+		It("should set the configured QPS and Burst", func() {
+			cfg := &rest.Config{QPS: 20, Burst: 30}
+			SetRateLimits(cfg, 100, 200)
+			Expect(cfg.QPS).Should(Equal(float32(100)))
+			Expect(cfg.Burst).Should(Equal(200))
+		})
+		It("should keep the existing limits when unset", func() {
+			cfg := &rest.Config{QPS: 20, Burst: 30}
+			SetRateLimits(cfg, 0, 0)
+			Expect(cfg.QPS).Should(Equal(float32(20)))
+			Expect(cfg.Burst).Should(Equal(30))
+		})
 	})
 })